@@ -0,0 +1,145 @@
+package bitboard
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/talglobus/fearsome/board"
+)
+
+// FromHistory constructs the position of the given geometry resulting from a sequence of moves
+func FromHistory(g Geometry, h board.History) (Position, error) {
+	if err := g.Validate(); err != nil {
+		return Position{}, err
+	}
+	return New(g).PlayMoves(h)
+}
+
+// FromBoard constructs the standard-geometry position of a board by replaying its history, which is the only way to
+// guarantee Termination Validity, as a board in itself places no restriction on moving after a win
+func FromBoard(b board.Board) (Position, error) {
+	return FromHistory(Standard, b.History())
+}
+
+// FromState constructs the standard-geometry position shown by a state. As a state carries no history, only Drop
+// Validity and the balance of stones between players can be verified, and Termination Validity is not checked
+func FromState(s board.State) (Position, error) {
+	p := New(Standard)
+	var red, blue uint64
+	for col := range s {
+		for row := range s[col] {
+			switch s[col][row] {
+			case board.NONE:
+				continue
+			case board.RED:
+				red |= p.bottomMask(col) << uint(row)
+			case board.BLUE:
+				blue |= p.bottomMask(col) << uint(row)
+			}
+			if row > 0 && s[col][row-1] == board.NONE {
+				return Position{}, fmt.Errorf("cannot read state: %w", DropValidityError{col, row})
+			}
+			p.moves++
+		}
+	}
+
+	p.mask = red | blue
+	switch n := p.moves; {
+	case n%2 == 0 && 2*bits.OnesCount64(red) == n:
+		p.current = red
+	case n%2 == 1 && 2*bits.OnesCount64(blue) == n-1:
+		p.current = blue
+	case 2*bits.OnesCount64(red) > n:
+		return Position{}, fmt.Errorf("cannot read state: %w", board.TurnValidityError(board.RED))
+	default:
+		return Position{}, fmt.Errorf("cannot read state: %w", board.TurnValidityError(board.BLUE))
+	}
+	return p, nil
+}
+
+// State returns the position as a board.State, which is only possible for positions of the standard geometry
+func (p Position) State() (board.State, error) {
+	var s board.State
+	if p.Geometry() != Standard {
+		return s, fmt.Errorf("cannot convert position to state: %w", GeometryMismatchError{Standard, p.Geometry()})
+	}
+	for col := range s {
+		for row := range s[col] {
+			s[col][row] = p.At(col, row)
+		}
+	}
+	return s, nil
+}
+
+// FromKey reconstructs the position of the given geometry identified by a key, as returned by Position.Key
+func FromKey(g Geometry, key uint64) (Position, error) {
+	if err := g.Validate(); err != nil {
+		return Position{}, err
+	}
+
+	p := New(g)
+	stride := uint(g.Height + 1)
+	if key&^(p.l.full|p.l.full<<1|p.l.bottom) != 0 {
+		return Position{}, fmt.Errorf("cannot read key %#x: %w", key, KeyError(key))
+	}
+
+	// Within each column, the key holds the stones of the player to move plus a single bit atop the column's stones
+	for col := 0; col < g.Width; col++ {
+		column := key >> (uint(col) * stride) & (1<<stride - 1)
+		if column == 0 {
+			return Position{}, fmt.Errorf("cannot read key %#x: %w", key, KeyError(key))
+		}
+		top := uint64(1) << uint(63-bits.LeadingZeros64(column))
+		p.mask |= (top - 1) << (uint(col) * stride)
+		p.current |= (column - top) << (uint(col) * stride)
+	}
+	p.moves = bits.OnesCount64(p.mask)
+	return p, nil
+}
+
+// HistoryOf finds a sequence of moves reaching the position from the empty position of its geometry, without passing
+// through a finished game, proving Termination Validity for positions constructed without a history, such as with
+// FromState. Of the many sequences typically reaching a position, HistoryOf returns the first found by taking back
+// the last mover's stones from the leftmost column possible
+func HistoryOf(p Position) (board.History, error) {
+	h := make(board.History, p.moves)
+	if !unplay(p, h, map[uint64]bool{}) {
+		return nil, fmt.Errorf("cannot find moves reaching position: %w", UnreachableError{})
+	}
+	return h, nil
+}
+
+// unplay fills h with moves reaching p, by taking back each of the last mover's topmost stones in turn and recursing,
+// remembering the keys of positions found unreachable
+func unplay(p Position, h board.History, unreachable map[uint64]bool) bool {
+	if p.moves == 0 {
+		return true
+	}
+	if unreachable[p.Key()] {
+		return false
+	}
+
+	last := p.current ^ p.mask
+	for col := 0; col < p.l.Width; col++ {
+		column := p.mask & p.columnMask(col)
+		if column == 0 {
+			continue
+		}
+		top := uint64(1) << uint(63-bits.LeadingZeros64(column))
+		if last&top == 0 {
+			continue
+		}
+
+		prev := Position{l: p.l, current: last &^ top, mask: p.mask &^ top, moves: p.moves - 1}
+		if prev.Winner() != board.NONE {
+			continue
+		}
+		if unplay(prev, h, unreachable) {
+			h[prev.moves] = board.Move(col)
+			return true
+		}
+	}
+
+	unreachable[p.Key()] = true
+	return false
+}
@@ -0,0 +1,128 @@
+package bitboard
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/talglobus/fearsome/board"
+)
+
+func TestFromBoard(t *testing.T) {
+	b := board.New()
+	for _, col := range []int{3, 3, 4, 2} {
+		if _, _, err := b.Move(col); err != nil {
+			t.Fatalf("unexpected error making move: %v", err)
+		}
+	}
+
+	p, err := FromBoard(b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s, err := p.State()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s != b.State() {
+		t.Errorf("position state does not match board. Expected:\n%v\nObserved:\n%v", b.State(), s)
+	}
+}
+
+func TestFromState(t *testing.T) {
+	table := []struct {
+		name  string
+		state board.State
+		moves board.History
+		err   error
+	}{
+		{"empty state", board.State{}, board.History{}, nil},
+		{"red to move", board.State{{}, {}, {}, {board.RED, board.BLUE}}, board.History{3, 3}, nil},
+		{"blue to move", board.State{{board.RED}, {}, {}, {board.RED, board.BLUE}}, board.History{3, 3, 0}, nil},
+		{"floating stone", board.State{{board.NONE, board.RED}}, nil, DropValidityError{0, 1}},
+		{"too many red", board.State{{board.RED, board.RED}}, nil, board.TurnValidityError(board.RED)},
+		{"too many blue", board.State{{board.BLUE}}, nil, board.TurnValidityError(board.BLUE)},
+	}
+
+	for _, r := range table {
+		t.Run(r.name, func(t *testing.T) {
+			p, err := FromState(r.state)
+			if !errors.Is(err, r.err) {
+				t.Fatalf("unexpected error. Expected %v, observed %v", r.err, err)
+			}
+			if err != nil {
+				return
+			}
+			want, _ := FromHistory(Standard, r.moves)
+			if p != want {
+				t.Errorf("unexpected position. Expected:\n%v\nObserved:\n%v", want, p)
+			}
+		})
+	}
+}
+
+func TestPosition_State(t *testing.T) {
+	if _, err := New(Geometry{4, 4}).State(); !errors.As(err, &GeometryMismatchError{}) {
+		t.Errorf("expected %T converting non-standard geometry, observed %v", GeometryMismatchError{}, err)
+	}
+}
+
+func TestFromKey(t *testing.T) {
+	for _, g := range []Geometry{Standard, {4, 4}, {8, 7}} {
+		p := New(g)
+		for i := 0; !p.IsOver(); i++ {
+			got, err := FromKey(g, p.Key())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != p {
+				t.Fatalf("%v key %#x produced unexpected position. Expected:\n%v\nObserved:\n%v", g, p.Key(), p, got)
+			}
+			p = p.Play(i * 3 % g.Width)
+		}
+	}
+
+	if _, err := FromKey(Geometry{4, 4}, 1<<63); !errors.Is(err, KeyError(1<<63)) {
+		t.Errorf("expected %v, observed %v", KeyError(1<<63), err)
+	}
+}
+
+func TestHistoryOf(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for _, g := range []Geometry{Standard, {Width: 4, Height: 4}, {Width: 9, Height: 6}} {
+		for i := 0; i < 100; i++ {
+			// Play a random game to its end, which HistoryOf must then find some way of reaching
+			p := New(g)
+			for !p.IsOver() {
+				legal := p.LegalMoves()
+				p = p.Play(int(legal[rng.Intn(len(legal))]))
+			}
+
+			h, err := HistoryOf(p)
+			if err != nil {
+				t.Fatalf("unexpected error: %v\n%v", err, p)
+			}
+			got, err := FromHistory(g, h)
+			if err != nil {
+				t.Fatalf("moves %v are illegal: %v", h, err)
+			}
+			if got != p {
+				t.Fatalf("moves %v reach\n%v\nrather than\n%v", h, got, p)
+			}
+		}
+	}
+}
+
+func TestHistoryOf_unreachable(t *testing.T) {
+	// Both players hold four in a row, so whoever completed theirs first ended the game
+	s := board.State{{board.RED, board.BLUE}, {board.RED, board.BLUE}, {board.RED, board.BLUE},
+		{board.RED, board.BLUE}}
+	p, err := FromState(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := HistoryOf(p); !errors.Is(err, UnreachableError{}) {
+		t.Errorf("expected %v, observed %v", UnreachableError{}, err)
+	}
+}
@@ -0,0 +1,52 @@
+package bitboard
+
+import "fmt"
+
+// GameOverError defines an error used when attempting to move after the game has been won or the board filled
+type GameOverError struct{}
+
+func (e GameOverError) Error() string {
+	return "game is over"
+}
+
+// ColumnRangeError defines an error used when attempting to move in a column that doesn't exist in the geometry. The
+// column counts from zero, but is shown counting from one, as in move notation
+type ColumnRangeError int
+
+func (e ColumnRangeError) Error() string {
+	return fmt.Sprintf("column %v does not exist", int(e)+1)
+}
+
+// DropValidityError defines an error used when an occupied square sits above an empty square, violating Drop Validity.
+// Col and Row count from zero, from the left and the bottom, but are shown counting from one
+type DropValidityError struct {
+	Col, Row int
+}
+
+func (e DropValidityError) Error() string {
+	return fmt.Sprintf("square at column %v, row %v is occupied but the square beneath it is empty", e.Col+1, e.Row+1)
+}
+
+// GeometryMismatchError defines an error used when an operation expects one geometry but is given another
+type GeometryMismatchError struct {
+	Want, Got Geometry
+}
+
+func (e GeometryMismatchError) Error() string {
+	return fmt.Sprintf("expected %v geometry, got %v", e.Want, e.Got)
+}
+
+// KeyError defines an error used when a key doesn't identify any position of a geometry
+type KeyError uint64
+
+func (e KeyError) Error() string {
+	return fmt.Sprintf("key %#x does not identify a position", uint64(e))
+}
+
+// UnreachableError defines an error used when no sequence of moves reaches a position without ending the game first,
+// as when both players have completed an alignment of four
+type UnreachableError struct{}
+
+func (e UnreachableError) Error() string {
+	return "position cannot be reached without the game ending first"
+}
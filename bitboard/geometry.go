@@ -0,0 +1,105 @@
+// Package bitboard implements a compact, copy-by-value Connect Four position for any board geometry that fits in a
+// 64-bit word. Where board.Board favors safety and a complete record of play, a Position favors raw speed, making it
+// the workhorse for search, simulation, and any other analysis that needs to visit millions of positions.
+//
+// Squares are numbered column by column, bottom to top, with one spare bit atop each column acting as a sentinel,
+// such that a geometry of Width columns and Height rows requires Width*(Height+1) bits.
+package bitboard
+
+import (
+	"fmt"
+
+	"github.com/talglobus/fearsome/board"
+)
+
+// Geometry defines the dimensions of a game board, in columns (Width) and rows (Height)
+type Geometry struct {
+	Width  int `json:"cols"`
+	Height int `json:"rows"`
+}
+
+// Standard is the geometry of board.Board, and the classic 7x6 game
+var Standard = Geometry{Width: board.COLS, Height: board.ROWS}
+
+// GeometryError defines an error used when a geometry can't be represented as a Position
+type GeometryError Geometry
+
+func (e GeometryError) Error() string {
+	return fmt.Sprintf("geometry %v cannot be represented, as columns*(rows+1) must be between 1 and 64",
+		Geometry(e))
+}
+
+// String formats the geometry in the customary "columns x rows" format, e.g. 7x6
+func (g Geometry) String() string {
+	return fmt.Sprintf("%vx%v", g.Width, g.Height)
+}
+
+// Cells returns the number of playable squares in the geometry
+func (g Geometry) Cells() int {
+	return g.Width * g.Height
+}
+
+// Validate returns a GeometryError if the geometry doesn't fit in a Position, and nil otherwise
+func (g Geometry) Validate() error {
+	if g.Width < 1 || g.Height < 1 || g.Width*(g.Height+1) > 64 {
+		return GeometryError(g)
+	}
+	return nil
+}
+
+// layout holds the precomputed masks for a geometry, shared by all positions of that geometry
+type layout struct {
+	Geometry
+	bottom uint64 // One bit at the bottom of each column
+	full   uint64 // Every playable square
+	order  []int  // Columns ordered from the center outwards, the customary search order
+}
+
+// layouts holds one layout for every valid geometry, indexed by width and then height
+var layouts = func() [65][65]*layout {
+	var ls [65][65]*layout
+	for w := 1; w <= 64; w++ {
+		for h := 1; w*(h+1) <= 64; h++ {
+			l := &layout{Geometry: Geometry{w, h}}
+			for c := 0; c < w; c++ {
+				l.bottom |= 1 << uint(c*(h+1))
+			}
+			l.full = l.bottom * (1<<uint(h) - 1)
+			for i := 0; i < w; i++ {
+				// Alternate right and left of center, e.g. 3, 2, 4, 1, 5, 0, 6 for a width of 7
+				l.order = append(l.order, w/2+(1-2*(i%2))*(i+1)/2)
+			}
+			ls[w][h] = l
+		}
+	}
+	return ls
+}()
+
+// layoutFor returns the layout of a geometry, panicking on an invalid geometry, as that is always programmer error
+func layoutFor(g Geometry) *layout {
+	if err := g.Validate(); err != nil {
+		panic(err)
+	}
+	return layouts[g.Width][g.Height]
+}
+
+// Order returns the columns of the geometry ordered from the center outwards, the most promising order to try moves
+func (g Geometry) Order() []int {
+	order := layoutFor(g).order
+	out := make([]int, len(order))
+	copy(out, order)
+	return out
+}
+
+// ParseGeometry parses a geometry in the "columns x rows" format produced by Geometry.String, e.g. 7x6
+func ParseGeometry(s string) (Geometry, error) {
+	var g Geometry
+	if n, err := fmt.Sscanf(s, "%dx%d", &g.Width, &g.Height); err != nil || n != 2 ||
+		fmt.Sprint(g) != s {
+		return Geometry{}, fmt.Errorf("cannot parse geometry %q: expected columns x rows, e.g. 7x6", s)
+	}
+	if err := g.Validate(); err != nil {
+		return Geometry{}, fmt.Errorf("cannot parse geometry %q: %w", s, err)
+	}
+	return g, nil
+}
@@ -0,0 +1,77 @@
+package bitboard
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestGeometry_Validate(t *testing.T) {
+	table := []struct {
+		g     Geometry
+		valid bool
+	}{
+		{Standard, true},
+		{Geometry{4, 4}, true},
+		{Geometry{8, 7}, true},
+		{Geometry{9, 7}, false},
+		{Geometry{1, 63}, true},
+		{Geometry{1, 64}, false},
+		{Geometry{0, 6}, false},
+		{Geometry{7, -1}, false},
+	}
+
+	for _, r := range table {
+		err := r.g.Validate()
+		if r.valid && err != nil {
+			t.Errorf("geometry %v should be valid, observed error: %v", r.g, err)
+		}
+		if !r.valid && !errors.Is(err, GeometryError(r.g)) {
+			t.Errorf("geometry %v should be invalid. Expected %v, observed %v", r.g, GeometryError(r.g), err)
+		}
+	}
+}
+
+func TestGeometry_Order(t *testing.T) {
+	table := []struct {
+		g    Geometry
+		want []int
+	}{
+		{Standard, []int{3, 2, 4, 1, 5, 0, 6}},
+		{Geometry{4, 4}, []int{2, 1, 3, 0}},
+		{Geometry{1, 6}, []int{0}},
+	}
+
+	for _, r := range table {
+		if got := r.g.Order(); fmt.Sprint(got) != fmt.Sprint(r.want) {
+			t.Errorf("geometry %v produced unexpected order. Expected %v, observed %v", r.g, r.want, got)
+		}
+	}
+}
+
+func ExampleGeometry_String() {
+	fmt.Println(Standard)
+	// Output: 7x6
+}
+
+func TestParseGeometry(t *testing.T) {
+	table := []struct {
+		s    string
+		want Geometry
+		ok   bool
+	}{
+		{"7x6", Standard, true},
+		{"8x7", Geometry{8, 7}, true},
+		{"9x7", Geometry{}, false},
+		{"7 x 6", Geometry{}, false},
+		{"7x6x5", Geometry{}, false},
+		{"seven", Geometry{}, false},
+	}
+
+	for _, r := range table {
+		got, err := ParseGeometry(r.s)
+		if (err == nil) != r.ok || got != r.want {
+			t.Errorf("parsing %q produced %v (error %v), expected %v (ok %v)", r.s, got, err, r.want, r.ok)
+		}
+	}
+}
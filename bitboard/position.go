@@ -0,0 +1,281 @@
+package bitboard
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/talglobus/fearsome/board"
+)
+
+// Position holds a game position as a pair of bitmaps, one of the stones of the player to move and one of all
+// stones, along with a count of moves played. Positions are immutable values: Play returns a new Position.
+// Note that the zero value is not usable, and Positions must be constructed with New or one of its siblings
+type Position struct {
+	l       *layout
+	current uint64 // Stones of the player to move
+	mask    uint64 // Stones of both players
+	moves   int
+}
+
+// New constructs an empty Position of the given geometry, panicking if the geometry is invalid
+func New(g Geometry) Position {
+	return Position{l: layoutFor(g)}
+}
+
+// Geometry returns the geometry of the position
+func (p Position) Geometry() Geometry {
+	return p.l.Geometry
+}
+
+// Moves returns the number of moves played to reach the position
+func (p Position) Moves() int {
+	return p.moves
+}
+
+// Turn returns the type of the player to move, RED moving on even-numbered turns and BLUE on odd-numbered turns
+func (p Position) Turn() board.Type {
+	if p.moves%2 == 0 {
+		return board.RED
+	}
+	return board.BLUE
+}
+
+// Key returns a number uniquely identifying the position among all positions of the same geometry
+func (p Position) Key() uint64 {
+	return p.current + p.mask + p.l.bottom
+}
+
+// MirrorKey returns the key of the position reflected across its central column. Symmetric positions share a value,
+// so the lesser of Key and MirrorKey is a convenient canonical key for symmetry-reduced storage
+func (p Position) MirrorKey() uint64 {
+	return p.Mirror().Key()
+}
+
+// Mirror returns the position reflected across its central column
+func (p Position) Mirror() Position {
+	stride := uint(p.l.Height + 1)
+	colMask := uint64(1)<<stride - 1
+	m := p
+	m.current, m.mask = 0, 0
+	for c := 0; c < p.l.Width; c++ {
+		from, to := uint(c)*stride, uint(p.l.Width-1-c)*stride
+		m.current |= (p.current >> from & colMask) << to
+		m.mask |= (p.mask >> from & colMask) << to
+	}
+	return m
+}
+
+// CanPlay returns whether a stone can be dropped into the given column
+func (p Position) CanPlay(col int) bool {
+	return col >= 0 && col < p.l.Width && p.mask&p.topMask(col) == 0
+}
+
+// Play returns the position resulting from the player to move dropping a stone in the given column, which must be
+// playable as reported by CanPlay
+func (p Position) Play(col int) Position {
+	p.current ^= p.mask
+	p.mask |= p.mask + p.bottomMask(col)
+	p.moves++
+	return p
+}
+
+// PlayMoves plays a sequence of moves in order, returning an error at the first move that can't be played, either
+// due to the column being full or out of bounds, or due to the game having already ended
+func (p Position) PlayMoves(h board.History) (Position, error) {
+	for i, m := range h {
+		if p.IsOver() {
+			return p, fmt.Errorf("cannot play move %v (column %v), as game is over: %w", i+1, int(m)+1,
+				GameOverError{})
+		}
+		if int(m) >= p.l.Width {
+			return p, fmt.Errorf("cannot play move %v: %w", i+1, ColumnRangeError(m))
+		}
+		if !p.CanPlay(int(m)) {
+			return p, fmt.Errorf("cannot play move %v (column %v): %w", i+1, int(m)+1, board.FullColumnError(m))
+		}
+		p = p.Play(int(m))
+	}
+	return p, nil
+}
+
+// Height returns the number of stones in the given column
+func (p Position) Height(col int) int {
+	return bits.OnesCount64(p.mask & p.columnMask(col))
+}
+
+// At returns the type of the stone at the given column and row, rows counting from the bottom, or NONE if empty
+func (p Position) At(col, row int) board.Type {
+	bit := p.bottomMask(col) << uint(row)
+	switch {
+	case p.mask&bit == 0:
+		return board.NONE
+	case p.current&bit != 0:
+		return p.Turn()
+	case p.Turn() == board.RED:
+		return board.BLUE
+	default:
+		return board.RED
+	}
+}
+
+// Square identifies a single square of a position by its column and its row, counting from the bottom
+type Square struct {
+	Col, Row int
+}
+
+// Threats returns the empty squares in which a stone of the given player would complete an alignment of four,
+// column by column from the bottom up, whether or not a stone can yet be dropped there
+func (p Position) Threats(t board.Type) []Square {
+	stones := p.current
+	if t != p.Turn() {
+		stones ^= p.mask
+	}
+
+	var squares []Square
+	stride := p.l.Height + 1
+	for w := p.winning(stones, p.mask); w != 0; w &= w - 1 {
+		i := bits.TrailingZeros64(w)
+		squares = append(squares, Square{Col: i / stride, Row: i % stride})
+	}
+	return squares
+}
+
+// IsWinningMove returns whether the player to move wins by dropping a stone in the given playable column
+func (p Position) IsWinningMove(col int) bool {
+	return p.winning(p.current, p.mask)&p.Possible()&p.columnMask(col) != 0
+}
+
+// CanWinNext returns whether the player to move has any move that wins immediately
+func (p Position) CanWinNext() bool {
+	return p.winning(p.current, p.mask)&p.Possible() != 0
+}
+
+// Winner returns the type of the player who completed an alignment of four, or NONE if no alignment exists.
+// Only the player who moved last can have won, as the game ends with the first alignment
+func (p Position) Winner() board.Type {
+	if p.moves > 0 && aligned(p.current^p.mask, uint(p.l.Height)) {
+		if p.Turn() == board.RED {
+			return board.BLUE
+		}
+		return board.RED
+	}
+	return board.NONE
+}
+
+// IsFull returns whether every square of the position is occupied
+func (p Position) IsFull() bool {
+	return p.moves >= p.l.Cells()
+}
+
+// IsOver returns whether the game has ended, either by a win or by the board filling up
+func (p Position) IsOver() bool {
+	return p.IsFull() || p.Winner() != board.NONE
+}
+
+// LegalMoves returns the playable columns of the position in ascending order, or none if the game is over
+func (p Position) LegalMoves() []board.Move {
+	if p.IsOver() {
+		return nil
+	}
+	var moves []board.Move
+	for c := 0; c < p.l.Width; c++ {
+		if p.CanPlay(c) {
+			moves = append(moves, board.Move(c))
+		}
+	}
+	return moves
+}
+
+// Possible returns a bitmap of the squares in which a stone can be dropped, namely one square atop each open column
+func (p Position) Possible() uint64 {
+	return (p.mask + p.l.bottom) & p.l.full
+}
+
+// NonLosingMoves returns a bitmap of the possible moves that don't immediately hand the opponent a win, assuming the
+// player to move has no immediately winning move. An empty bitmap means every move loses
+func (p Position) NonLosingMoves() uint64 {
+	possible := p.Possible()
+	opponentWin := p.winning(p.current^p.mask, p.mask)
+	forced := possible & opponentWin
+	if forced != 0 {
+		if forced&(forced-1) != 0 {
+			// The opponent has two immediate threats, which can't both be blocked
+			return 0
+		}
+		possible = forced
+	}
+	// Avoid playing directly beneath a square in which the opponent would win
+	return possible &^ (opponentWin >> 1)
+}
+
+// MoveScore returns the number of winning squares the player to move would hold after playing the given move bitmap,
+// a cheap heuristic for ordering moves in search
+func (p Position) MoveScore(move uint64) int {
+	return bits.OnesCount64(p.winning(p.current|move, p.mask))
+}
+
+// ColumnOf returns the column holding the single square of a move bitmap
+func (p Position) ColumnOf(move uint64) int {
+	return bits.TrailingZeros64(move) / (p.l.Height + 1)
+}
+
+// ColumnMask returns a bitmap of every playable square in the given column
+func (p Position) ColumnMask(col int) uint64 {
+	return p.columnMask(col)
+}
+
+func (p Position) topMask(col int) uint64 {
+	return 1 << uint(p.l.Height-1+col*(p.l.Height+1))
+}
+
+func (p Position) bottomMask(col int) uint64 {
+	return 1 << uint(col*(p.l.Height+1))
+}
+
+func (p Position) columnMask(col int) uint64 {
+	return (1<<uint(p.l.Height) - 1) << uint(col*(p.l.Height+1))
+}
+
+// winning returns a bitmap of the empty squares which would complete an alignment of four for the given stones
+func (p Position) winning(stones, mask uint64) uint64 {
+	h := uint(p.l.Height)
+
+	// Vertical
+	r := (stones << 1) & (stones << 2) & (stones << 3)
+
+	// Horizontal, then both diagonals, which differ only in the shift between adjacent squares
+	for _, s := range [3]uint{h + 1, h, h + 2} {
+		t := (stones << s) & (stones << (2 * s))
+		r |= t & (stones << (3 * s))
+		r |= t & (stones >> s)
+		t = (stones >> s) & (stones >> (2 * s))
+		r |= t & (stones << s)
+		r |= t & (stones >> (3 * s))
+	}
+
+	return r & (p.l.full ^ mask)
+}
+
+// aligned returns whether the given stones contain an alignment of four in any direction
+func aligned(stones uint64, height uint) bool {
+	for _, s := range [4]uint{1, height + 1, height, height + 2} {
+		m := stones & (stones >> s)
+		if m&(m>>(2*s)) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// String provides the same fancy output as board.State, for any geometry
+func (p Position) String() string {
+	str := ""
+	for row := p.l.Height - 1; row >= 0; row-- {
+		str += "|"
+		for col := 0; col < p.l.Width; col++ {
+			str += " " + p.At(col, row).String() + " |"
+		}
+		str += "\n"
+	}
+	return str[:len(str)-1]
+}
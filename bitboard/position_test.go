@@ -0,0 +1,153 @@
+package bitboard
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/talglobus/fearsome/board"
+)
+
+// naiveWinner finds an alignment of four by brute force, as a reference against which to check the bitmap arithmetic
+func naiveWinner(p Position) board.Type {
+	g := p.Geometry()
+	dirs := [][2]int{{1, 0}, {0, 1}, {1, 1}, {1, -1}}
+	for c := 0; c < g.Width; c++ {
+		for r := 0; r < g.Height; r++ {
+			t := p.At(c, r)
+			if t == board.NONE {
+				continue
+			}
+			for _, d := range dirs {
+				n := 1
+				for n < 4 {
+					cc, rr := c+d[0]*n, r+d[1]*n
+					if cc < 0 || cc >= g.Width || rr < 0 || rr >= g.Height || p.At(cc, rr) != t {
+						break
+					}
+					n++
+				}
+				if n == 4 {
+					return t
+				}
+			}
+		}
+	}
+	return board.NONE
+}
+
+// TestPosition_randomGames plays random games across a range of geometries, checking the bitmap-based win detection
+// against a brute-force search for alignments after every move
+func TestPosition_randomGames(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	geometries := []Geometry{Standard, {4, 4}, {5, 4}, {8, 7}, {6, 5}, {1, 8}, {9, 5}}
+
+	for _, g := range geometries {
+		for game := 0; game < 200; game++ {
+			p := New(g)
+			for !p.IsOver() {
+				moves := p.LegalMoves()
+				col := int(moves[rng.Intn(len(moves))])
+
+				wins := p.IsWinningMove(col)
+				p = p.Play(col)
+
+				if got, want := p.Winner(), naiveWinner(p); got != want {
+					t.Fatalf("%v position has unexpected winner. Expected %v, observed %v\n%v", g, want, got, p)
+				}
+				if wins != (p.Winner() != board.NONE) {
+					t.Fatalf("%v position predicted win %v, but winner is %v\n%v", g, wins, p.Winner(), p)
+				}
+				if p.Key() == p.Play(0).Key() && p.CanPlay(0) {
+					t.Fatalf("%v position key did not change after move\n%v", g, p)
+				}
+			}
+		}
+	}
+}
+
+func TestPosition_PlayMoves(t *testing.T) {
+	p, err := New(Standard).PlayMoves(board.History{3, 3, 4, 4, 5, 5, 6})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Winner() != board.RED || !p.IsOver() {
+		t.Errorf("expected win for %v, observed winner %v\n%v", board.RED, p.Winner(), p)
+	}
+
+	if _, err := p.PlayMoves(board.History{0}); err == nil {
+		t.Errorf("expected %T playing after win, observed nil", GameOverError{})
+	}
+
+	full := board.History{0, 0, 0, 0, 0, 0}
+	if _, err := New(Standard).PlayMoves(append(full, 0)); err == nil {
+		t.Errorf("expected %T playing in a full column, observed nil", board.FullColumnError(0))
+	}
+
+	if _, err := New(Standard).PlayMoves(board.History{7}); err == nil {
+		t.Errorf("expected %T playing out of bounds, observed nil", ColumnRangeError(7))
+	}
+}
+
+func TestPosition_Mirror(t *testing.T) {
+	p, _ := New(Standard).PlayMoves(board.History{0, 1, 0, 6})
+	q, _ := New(Standard).PlayMoves(board.History{6, 5, 6, 0})
+
+	if p.Mirror().Key() != q.Key() || p.MirrorKey() != q.Key() {
+		t.Errorf("mirror of\n%v\nshould equal\n%v\nobserved\n%v", p, q, p.Mirror())
+	}
+	if p.Mirror().Mirror() != p {
+		t.Errorf("mirroring twice should return the original position")
+	}
+}
+
+func TestPosition_NonLosingMoves(t *testing.T) {
+	// RED threatens to complete a row in column 3, so BLUE's only non-losing move is to block there
+	p, _ := New(Standard).PlayMoves(board.History{0, 0, 1, 1, 2})
+	if got, want := p.NonLosingMoves(), p.Possible()&p.ColumnMask(3); got != want {
+		t.Errorf("expected only column 3 to be non-losing, observed bitmap %b", got)
+	}
+
+	// RED threatens both ends of an open row, so BLUE has no non-losing move
+	p, _ = New(Standard).PlayMoves(board.History{2, 2, 3, 3, 4})
+	if got := p.NonLosingMoves(); got != 0 {
+		t.Errorf("expected no non-losing moves, observed bitmap %b", got)
+	}
+}
+
+func TestPosition_String(t *testing.T) {
+	p := New(Geometry{5, 4}).Play(2)
+	rows := strings.Split(p.String(), "\n")
+	if len(rows) != 4 {
+		t.Fatalf("Position has incorrect number of rows. Expected %v, observed %v", 4, len(rows))
+	}
+	if !strings.Contains(rows[3], board.RED.String()) {
+		t.Errorf("bottom row should contain a %v stone, observed %q", board.RED, rows[3])
+	}
+}
+
+func TestPosition_Threats(t *testing.T) {
+	// RED holds three in a row along the bottom, threatening both ends, while BLUE holds three in a column
+	p, _ := New(Standard).PlayMoves(board.History{2, 6, 3, 6, 4, 6})
+	if got, want := p.Threats(board.RED), []Square{{1, 0}, {5, 0}}; !equalSquares(got, want) {
+		t.Errorf("expected RED threats %v, observed %v", want, got)
+	}
+	if got, want := p.Threats(board.BLUE), []Square{{6, 3}}; !equalSquares(got, want) {
+		t.Errorf("expected BLUE threats %v, observed %v", want, got)
+	}
+	if got := New(Standard).Threats(board.RED); len(got) != 0 {
+		t.Errorf("expected no threats on an empty board, observed %v", got)
+	}
+}
+
+func equalSquares(a, b []Square) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
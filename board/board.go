@@ -100,7 +100,7 @@ func (b *Board) Move(colNum int) (Type, int, error) {
 		}
 	}
 
-	return NONE, 0, fmt.Errorf("cannot make move in column %v: %w", colNum, FullColumnError(colNum))
+	return NONE, 0, fmt.Errorf("cannot make move in column %v: %w", colNum+1, FullColumnError(colNum))
 }
 
 // MoveRed wraps Move for a slightly safer, `red`-specific way of making moves
@@ -109,7 +109,7 @@ func (b *Board) Move(colNum int) (Type, int, error) {
 func (b *Board) MoveRed(colNum int) (int, error) {
 	if b.nextTurn() != RED {
 		return 0, fmt.Errorf("cannot make %v move in column %v: %w",
-			RED.String(), colNum, TurnValidityError(RED))
+			RED.String(), colNum+1, TurnValidityError(RED))
 	}
 
 	_, r, e := b.Move(colNum)
@@ -123,7 +123,7 @@ func (b *Board) MoveRed(colNum int) (int, error) {
 func (b *Board) MoveBlue(colNum int) (int, error) {
 	if b.nextTurn() != BLUE {
 		return 0, fmt.Errorf("cannot make %v move in column %v: %w",
-			BLUE.String(), colNum, TurnValidityError(BLUE))
+			BLUE.String(), colNum+1, TurnValidityError(BLUE))
 	}
 
 	_, r, e := b.Move(colNum)
@@ -148,7 +148,7 @@ func (b *Board) Unmove() error {
 	for i := ROWS - 1; i >= 0; i-- {
 		if square := b.state[col][i]; square == t.invert() {
 			// If the square is of the opposite Type expected (RED when expecting BLUE or vice versa), error
-			return fmt.Errorf("cannot undo move (column %v, type %v): %w", col, t, HistoryValidityError(*b))
+			return fmt.Errorf("cannot undo move (column %v, type %v): %w", int(col)+1, t, HistoryValidityError(*b))
 		} else if square == t {
 			// If the square is of the Type expected, undo the move
 			b.state[col][i] = NONE
@@ -159,5 +159,21 @@ func (b *Board) Unmove() error {
 
 	// If column is exhausted and correct piece still hasn't been found on top, History Validity must be violated
 	return fmt.Errorf("cannot undo move (column %v, type %v) as column is empty: %w",
-		col, t, HistoryValidityError(*b))
+		int(col)+1, t, HistoryValidityError(*b))
+}
+
+// History returns a copy of the board's move history, safe to retain and modify without affecting the board
+func (b Board) History() History {
+	b.RLock()
+	defer b.RUnlock()
+	h := make(History, len(b.history))
+	copy(h, b.history)
+	return h
+}
+
+// State returns the board's current state. As State is an array type, the returned value is already a copy
+func (b Board) State() State {
+	b.RLock()
+	defer b.RUnlock()
+	return b.state
 }
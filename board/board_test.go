@@ -352,3 +352,39 @@ func TestBoard_Unmove(t *testing.T) {
 		}
 	}
 }
+
+func TestBoard_History(t *testing.T) {
+	b := Board{
+		history: History{4, 5, 5},
+		state:   State{{}, {}, {}, {}, {RED}, {BLUE, RED}, {}},
+		mutex:   &sync.RWMutex{},
+	}
+
+	h := b.History()
+	if !h.Equals(History{4, 5, 5}) {
+		t.Fatalf("History returned unexpected moves. Expected %v, observed %v", History{4, 5, 5}, h)
+	}
+
+	// Modifying the returned history must not reach back into the board
+	h[0] = 0
+	if b.history[0] != 4 {
+		t.Errorf("History must return a copy, but modifying it changed the board to:\n%v", b)
+	}
+}
+
+func TestBoard_State(t *testing.T) {
+	b := New()
+	if _, _, err := b.Move(3); err != nil {
+		t.Fatalf("unexpected error making move: %v", err)
+	}
+
+	s := b.State()
+	if s[3][0] != RED {
+		t.Fatalf("State returned unexpected square at column 3, row 0. Expected %v, observed %v", RED, s[3][0])
+	}
+
+	s[3][0] = BLUE
+	if b.state[3][0] != RED {
+		t.Errorf("State must return a copy, but modifying it changed the board to:\n%v", b)
+	}
+}
@@ -5,11 +5,12 @@ import (
 	"fmt"
 )
 
-// FullColumnError defines an error used when attempting to add pieces to an already-full column
+// FullColumnError defines an error used when attempting to add pieces to an already-full column. The column is shown
+// counting from one, as in move notation
 type FullColumnError Move
 
 func (e FullColumnError) Error() string {
-	return fmt.Sprintf("column %v is full and cannot accept any more pieces", int(e))
+	return fmt.Sprintf("column %v is full and cannot accept any more pieces", int(e)+1)
 }
 
 // TurnValidityError defines an error used when attempting to make a move during the opposite player's turn
@@ -43,3 +44,27 @@ type EmptyBoardError struct{}
 func (e EmptyBoardError) Error() string {
 	return "board is empty"
 }
+
+// NotationError defines an error used when parsing move notation containing a character that doesn't name a column
+type NotationError struct {
+	Notation string
+	Index    int
+}
+
+func (e NotationError) Error() string {
+	return fmt.Sprintf("invalid move %q at position %v of notation %q", e.Notation[e.Index], e.Index+1, e.Notation)
+}
+
+// StateFormatError defines an error used when parsing a grid of squares that doesn't describe a state. Row counts
+// from one at the top of the grid, or is zero if the error concerns the grid as a whole
+type StateFormatError struct {
+	Row    int
+	Reason string
+}
+
+func (e StateFormatError) Error() string {
+	if e.Row == 0 {
+		return fmt.Sprintf("cannot parse grid: %v", e.Reason)
+	}
+	return fmt.Sprintf("cannot parse grid row %v: %v", e.Row, e.Reason)
+}
@@ -1,6 +1,9 @@
 package board
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Move holds the choice of column for a given move
 type Move uint8
@@ -44,3 +47,55 @@ func (h History) Equals(h2 History) bool {
 
 	return true
 }
+
+// notationDigits maps columns to the characters used for them in move notation, counting columns from one
+const notationDigits = "123456789abcdefghijklmnopqrstuvwxyz"
+
+// Notation outputs the compact notation for the move history, consisting of one character per move naming its
+// column counted from one, such that History{3, 3, 4, 2} is "4453". Columns beyond the ninth are named by letters,
+// starting with "a" for the tenth
+func (h History) Notation() string {
+	b := make([]byte, len(h))
+	for i, m := range h {
+		if int(m) >= len(notationDigits) {
+			b[i] = '?'
+			continue
+		}
+		b[i] = notationDigits[m]
+	}
+	return string(b)
+}
+
+// ParseHistory parses a move history from its compact notation, as output by History.Notation. Letters are accepted
+// in either case. As notation names columns of boards of any width, up to 35 columns, the moves aren't checked
+// against a board: callers must check them before playing them, such as with bitboard.FromHistory, which rejects
+// columns beyond the geometry with a ColumnRangeError
+func ParseHistory(s string) (History, error) {
+	h := make(History, 0, len(s))
+	for i, c := range []byte(s) {
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		col := strings.IndexByte(notationDigits, c)
+		if col < 0 {
+			return nil, NotationError{Notation: s, Index: i}
+		}
+		h = append(h, Move(col))
+	}
+	return h, nil
+}
+
+// MarshalText encodes the move history in its compact notation, such that it's encoded as a string in JSON
+func (h History) MarshalText() ([]byte, error) {
+	return []byte(h.Notation()), nil
+}
+
+// UnmarshalText decodes a move history from its compact notation
+func (h *History) UnmarshalText(text []byte) error {
+	parsed, err := ParseHistory(string(text))
+	if err != nil {
+		return err
+	}
+	*h = parsed
+	return nil
+}
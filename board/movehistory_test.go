@@ -1,7 +1,10 @@
 package board
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -63,3 +66,64 @@ func TestHistory_Equals(t *testing.T) {
 		}
 	}
 }
+
+func ExampleHistory_Notation() {
+	fmt.Println(History{3, 3, 4, 2}.Notation())
+	// Output: 4453
+}
+
+func TestParseHistory(t *testing.T) {
+	table := []struct {
+		notation string
+		want     History
+		err      bool
+	}{
+		{"4453", History{3, 3, 4, 2}, false},
+		{"", History{}, false},
+		{"19aB", History{0, 8, 9, 10}, false},
+		{"40", nil, true},
+		{"4 4", nil, true},
+	}
+
+	for _, r := range table {
+		got, err := ParseHistory(r.notation)
+		if (err != nil) != r.err {
+			t.Errorf("%q: expected error %v, observed %v", r.notation, r.err, err)
+			continue
+		}
+		var notationErr NotationError
+		if r.err && !errors.As(err, &notationErr) {
+			t.Errorf("%q: expected a notation error, observed %v", r.notation, err)
+		}
+		if !r.err && !got.Equals(r.want) {
+			t.Errorf("%q: expected %v, observed %v", r.notation, r.want, got)
+		}
+		if !r.err && strings.ToLower(r.notation) != got.Notation() {
+			t.Errorf("%q: expected notation to round trip, observed %q", r.notation, got.Notation())
+		}
+	}
+}
+
+func TestHistory_MarshalText(t *testing.T) {
+	type game struct {
+		Moves History `json:"moves"`
+	}
+	data, err := json.Marshal(game{History{3, 3, 4, 2}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != `{"moves":"4453"}` {
+		t.Errorf("unexpected encoding %s", data)
+	}
+
+	var g game
+	if err := json.Unmarshal(data, &g); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !g.Moves.Equals(History{3, 3, 4, 2}) {
+		t.Errorf("unexpected decoding %v", g.Moves)
+	}
+	if err := json.Unmarshal([]byte(`{"moves":"4!"}`), &g); err == nil {
+		t.Errorf("expected an error decoding invalid notation")
+	}
+}
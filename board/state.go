@@ -1,5 +1,10 @@
 package board
 
+import (
+	"fmt"
+	"strings"
+)
+
 // State holds the full state of the board at a given point in time
 type State [COLS][ROWS]Type
 
@@ -16,3 +21,54 @@ func (s State) String() string {
 
 	return str[:len(str)-1]
 }
+
+// ParseState parses a grid of squares, top row first, in either the format produced by State.String, or a compact
+// format of one character per square: R or X for RED, B, O, or Y for BLUE, and . or - for an empty square. Rows are
+// separated by newlines or slashes, and blank rows, along with a row of column numbers, are ignored. Note that a
+// parsed state is subject to no validity checks whatsoever
+func ParseState(s string) (State, error) {
+	var state State
+	var rows [][]Type
+	for _, line := range strings.FieldsFunc(s, func(r rune) bool { return r == '\n' || r == '/' }) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.Trim(line, "0123456789 ") == "" {
+			continue
+		}
+
+		var cells []string
+		if strings.Contains(line, "|") {
+			cells = strings.Split(strings.Trim(line, "|"), "|")
+		} else {
+			cells = strings.Split(strings.Join(strings.Fields(line), ""), "")
+		}
+
+		row := make([]Type, len(cells))
+		for i, cell := range cells {
+			switch strings.ToUpper(strings.TrimSpace(cell)) {
+			case RED.String(), "R", "X":
+				row[i] = RED
+			case BLUE.String(), "BLU", "B", "O", "Y":
+				row[i] = BLUE
+			case NONE.String(), "-", ".", "":
+				row[i] = NONE
+			default:
+				return state, StateFormatError{Row: len(rows) + 1, Reason: fmt.Sprintf("unknown square %q", cell)}
+			}
+		}
+		if len(row) != COLS {
+			return state, StateFormatError{Row: len(rows) + 1, Reason: fmt.Sprintf("expected %v squares, found %v",
+				COLS, len(row))}
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) != ROWS {
+		return state, StateFormatError{Reason: fmt.Sprintf("expected %v rows, found %v", ROWS, len(rows))}
+	}
+	for i, row := range rows {
+		for col, t := range row {
+			state[col][ROWS-1-i] = t
+		}
+	}
+	return state, nil
+}
@@ -1,6 +1,7 @@
 package board
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -26,3 +27,43 @@ func TestState_String(t *testing.T) {
 		t.Errorf("State has incorrect number of rows. Expected %v, observed %v", ROWS, len(rows))
 	}
 }
+
+func TestParseState(t *testing.T) {
+	b := New()
+	for _, col := range []int{3, 3, 4, 2, 3} {
+		if _, _, err := b.Move(col); err != nil {
+			t.Fatalf("unexpected error making move: %v", err)
+		}
+	}
+	want := b.State()
+
+	table := []struct {
+		name, grid string
+	}{
+		{"string", want.String()},
+		{"string with labels", "\n" + want.String() + "\n   1     2     3     4     5     6     7\n"},
+		{"compact", ".......\n.......\n.......\n...R...\n...B...\n..BRR..\n"},
+		{"compact with slashes", "......./......./......./...x.../...o.../..oxx.."},
+		{"compact with spaces", ". . . . . . .\n. . . . . . .\n. . . . . . .\n- - - X - - -\n- - - Y - - -\n- - Y X X - -"},
+	}
+	for _, r := range table {
+		got, err := ParseState(r.grid)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", r.name, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%v: expected\n%v\nobserved\n%v", r.name, want, got)
+		}
+	}
+
+	for _, grid := range []string{
+		".......\n.......\n.......\n.......\n.......",
+		".......\n.......\n.......\n.......\n.......\n......",
+		".......\n.......\n.......\n.......\n.......\n...Z...",
+	} {
+		if _, err := ParseState(grid); !errors.As(err, new(StateFormatError)) {
+			t.Errorf("expected a grid format error parsing %q, observed %v", grid, err)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+package board
+
+// TypeStatic names the type of TYPE within tests, such that go vet resolves the examples of its static methods
+type TypeStatic = typeStatic
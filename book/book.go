@@ -0,0 +1,230 @@
+// Package book implements opening books: precomputed exact scores and best moves for every position up to a given
+// number of plies, stored once per pair of mirror-image positions, such that the opening of a game can be played
+// instantly rather than searched afresh every time.
+package book
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/internal/chunked"
+	"github.com/talglobus/fearsome/solver"
+)
+
+// Entry holds the best move of a position and the exact score it achieves, from the perspective of the player to move
+type Entry struct {
+	Move   board.Move
+	Score  solver.Score
+	InBook bool // Whether the entry came from the book, as opposed to a fallback search
+}
+
+// Book maps positions of a single geometry, up to a given number of plies, to their best moves and scores
+type Book struct {
+	geometry bitboard.Geometry
+	depth    int
+	keys     []uint64 // Canonical keys, sorted ascending
+	scores   []int8   // Scores, in the same order as keys
+	moves    []uint8  // Best moves in the canonical orientation, in the same order as keys
+}
+
+// Geometry returns the geometry of the positions in the book
+func (b *Book) Geometry() bitboard.Geometry {
+	return b.geometry
+}
+
+// Depth returns the greatest number of plies of any position in the book
+func (b *Book) Depth() int {
+	return b.depth
+}
+
+// Len returns the number of entries in the book, where a position and its mirror image share a single entry
+func (b *Book) Len() int {
+	return len(b.keys)
+}
+
+// canonical returns the key identifying a position and its mirror image, and whether the position is the mirror
+// image of the stored orientation
+func canonical(p bitboard.Position) (uint64, bool) {
+	k, m := p.Key(), p.MirrorKey()
+	if m < k {
+		return m, true
+	}
+	return k, false
+}
+
+// Probe returns the book entry of a position, if the position is in the book
+func (b *Book) Probe(p bitboard.Position) (Entry, bool) {
+	if p.Geometry() != b.geometry || p.Moves() > b.depth {
+		return Entry{}, false
+	}
+
+	key, mirrored := canonical(p)
+	i := sort.Search(len(b.keys), func(i int) bool { return b.keys[i] >= key })
+	if i == len(b.keys) || b.keys[i] != key {
+		return Entry{}, false
+	}
+
+	move := int(b.moves[i])
+	if mirrored {
+		move = b.geometry.Width - 1 - move
+	}
+	return Entry{Move: board.Move(move), Score: solver.Score(b.scores[i]), InBook: true}, true
+}
+
+// Lookup returns the best move and score of the position reached by a sequence of moves, from the book if the
+// position is in it, and by searching with the solver otherwise
+func (b *Book) Lookup(ctx context.Context, s *solver.Solver, h board.History) (Entry, error) {
+	p, err := bitboard.FromHistory(b.geometry, h)
+	if err != nil {
+		return Entry{}, fmt.Errorf("cannot look up moves: %w", err)
+	}
+	if p.IsOver() {
+		return Entry{}, fmt.Errorf("cannot look up moves: %w", bitboard.GameOverError{})
+	}
+
+	if e, ok := b.Probe(p); ok {
+		return e, nil
+	}
+	return best(ctx, s, p, nil)
+}
+
+// best finds the best move of a position by scoring each move, either through the given lookup of positions already
+// solved, or by searching with the solver. Ties are broken in favor of central columns
+func best(ctx context.Context, s *solver.Solver, p bitboard.Position, solved func(bitboard.Position) (solver.Score,
+	bool)) (Entry, error) {
+	e := Entry{Score: -solver.Score(p.Geometry().Cells())}
+	for _, col := range p.Geometry().Order() {
+		if !p.CanPlay(col) {
+			continue
+		}
+
+		child := p.Play(col)
+		score, ok := solver.Score(0), false
+		if solved != nil && !child.IsOver() {
+			score, ok = solved(child)
+		}
+		if !ok {
+			var err error
+			if score, err = s.Solve(ctx, child); err != nil {
+				return Entry{}, err
+			}
+		}
+
+		if -score > e.Score {
+			e.Move, e.Score = board.Move(col), -score
+		}
+	}
+	return e, nil
+}
+
+// bookMagic identifies a file as a book, and the version of its format
+var bookMagic = [4]byte{'F', 'B', 'K', 1}
+
+// bookHeader precedes the entries of a book file, which follow as sorted keys, then scores, then moves
+type bookHeader struct {
+	Magic   [4]byte
+	Width   uint8
+	Height  uint8
+	Depth   uint8
+	_       uint8
+	Entries uint64
+}
+
+// WriteTo writes the book to the writer in its compact binary format, such that it can be restored with ReadBook
+func (b *Book) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	h := bookHeader{
+		Magic:   bookMagic,
+		Width:   uint8(b.geometry.Width),
+		Height:  uint8(b.geometry.Height),
+		Depth:   uint8(b.depth),
+		Entries: uint64(len(b.keys)),
+	}
+	for _, data := range []interface{}{h, b.keys, b.scores, b.moves} {
+		if err := binary.Write(bw, binary.LittleEndian, data); err != nil {
+			return 0, fmt.Errorf("cannot write book: %w", err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return 0, fmt.Errorf("cannot write book: %w", err)
+	}
+	return int64(binary.Size(h) + 10*len(b.keys)), nil
+}
+
+// ReadBook restores a book written with WriteTo
+func ReadBook(r io.Reader) (*Book, error) {
+	br := bufio.NewReader(r)
+	var h bookHeader
+	if err := binary.Read(br, binary.LittleEndian, &h); err != nil {
+		return nil, fmt.Errorf("cannot read book header: %w", err)
+	}
+	if h.Magic != bookMagic {
+		return nil, fmt.Errorf("cannot read book: %w", BookFormatError(h.Magic))
+	}
+
+	g := bitboard.Geometry{Width: int(h.Width), Height: int(h.Height)}
+	if err := g.Validate(); err != nil {
+		return nil, fmt.Errorf("cannot read book: %w", err)
+	}
+
+	b := &Book{geometry: g, depth: int(h.Depth)}
+	err := chunked.Read(h.Entries, func(count int) error {
+		keys := make([]uint64, count)
+		if err := binary.Read(br, binary.LittleEndian, keys); err != nil {
+			return err
+		}
+		b.keys = append(b.keys, keys...)
+		return nil
+	})
+	if err == nil {
+		// Once the keys are read, the scores and moves are known to be far smaller than the data read
+		b.scores, b.moves = make([]int8, h.Entries), make([]uint8, h.Entries)
+		for _, data := range []interface{}{b.scores, b.moves} {
+			if err = binary.Read(br, binary.LittleEndian, data); err != nil {
+				break
+			}
+		}
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("cannot read book entries: %w", BookSizeError(h.Entries))
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot read book entries: %w", err)
+	}
+	return b, nil
+}
+
+// Save writes the book to a file at the given path, replacing any existing file
+func (b *Book) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("cannot save book: %w", err)
+	}
+	if _, err := b.WriteTo(f); err != nil {
+		f.Close()
+		return fmt.Errorf("cannot save book to %v: %w", path, err)
+	}
+	return f.Close()
+}
+
+// Load restores a book saved to a file with Save
+func Load(path string) (*Book, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load book: %w", err)
+	}
+	defer f.Close()
+
+	b, err := ReadBook(f)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load book from %v: %w", path, err)
+	}
+	return b, nil
+}
@@ -0,0 +1,142 @@
+package book
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/solver"
+)
+
+var small = bitboard.Geometry{Width: 5, Height: 4}
+
+// generate builds a shallow book of the small geometry, along with the solver used to build it
+func generate(t *testing.T, depth int) (*Book, *solver.Solver) {
+	s, err := solver.New(small, solver.Config{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := Generate(context.Background(), s, depth, nil)
+	if err != nil {
+		t.Fatalf("unexpected error generating book: %v", err)
+	}
+	return b, s
+}
+
+func TestBook_Lookup(t *testing.T) {
+	b, s := generate(t, 3)
+
+	table := []struct {
+		name   string
+		moves  board.History
+		inBook bool
+	}{
+		{"empty board", board.History{}, true},
+		{"one move", board.History{0}, true},
+		{"mirror image of one move", board.History{4}, true},
+		{"deepest ply", board.History{1, 2, 4}, true},
+		{"out of book", board.History{1, 2, 4, 0}, false},
+	}
+
+	for _, r := range table {
+		t.Run(r.name, func(t *testing.T) {
+			e, err := b.Lookup(context.Background(), s, r.moves)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if e.InBook != r.inBook {
+				t.Errorf("expected in book %v, observed %v", r.inBook, e.InBook)
+			}
+
+			// The move must achieve the score, and no move may score better
+			p, _ := bitboard.FromHistory(small, r.moves)
+			scores, ok, err := s.Analyze(context.Background(), p)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !ok[e.Move] || scores[e.Move] != e.Score {
+				t.Errorf("move %v should score %v, observed %v", e.Move, e.Score, scores[e.Move])
+			}
+			for col, score := range scores {
+				if ok[col] && score > e.Score {
+					t.Errorf("column %v scores %v, better than book move %v scoring %v", col, score, e.Move, e.Score)
+				}
+			}
+		})
+	}
+}
+
+func TestBook_Lookup_invalid(t *testing.T) {
+	b, s := generate(t, 1)
+	if _, err := b.Lookup(context.Background(), s, board.History{5}); !errors.Is(err, bitboard.ColumnRangeError(5)) {
+		t.Errorf("expected %v, observed %v", bitboard.ColumnRangeError(5), err)
+	}
+	over := board.History{0, 1, 0, 1, 0, 1, 0}
+	if _, err := b.Lookup(context.Background(), s, over); !errors.Is(err, bitboard.GameOverError{}) {
+		t.Errorf("expected %v, observed %v", bitboard.GameOverError{}, err)
+	}
+}
+
+func TestBook_Probe_symmetry(t *testing.T) {
+	b, _ := generate(t, 2)
+
+	// Two plies of a 5-wide board make 25 positions, of which only the central stack is its own mirror image,
+	// leaving 13 entries, alongside 3 for the 5 positions of one ply, and 1 for the empty board
+	if b.Len() != 1+3+13 {
+		t.Errorf("unexpected number of entries. Expected %v, observed %v", 1+3+13, b.Len())
+	}
+
+	p, _ := bitboard.FromHistory(small, board.History{0, 1})
+	q, _ := bitboard.FromHistory(small, board.History{4, 3})
+	e, _ := b.Probe(p)
+	f, _ := b.Probe(q)
+	if e.Score != f.Score || int(e.Move) != small.Width-1-int(f.Move) {
+		t.Errorf("mirror images should have mirrored entries, observed %+v and %+v", e, f)
+	}
+}
+
+func TestBook_Save(t *testing.T) {
+	b, _ := generate(t, 3)
+	path := filepath.Join(t.TempDir(), "book")
+	if err := b.Save(path); err != nil {
+		t.Fatalf("unexpected error saving book: %v", err)
+	}
+
+	restored, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error loading book: %v", err)
+	}
+	if restored.Geometry() != b.Geometry() || restored.Depth() != b.Depth() || restored.Len() != b.Len() {
+		t.Fatalf("restored book has unexpected configuration")
+	}
+	for i := range b.keys {
+		if restored.keys[i] != b.keys[i] || restored.scores[i] != b.scores[i] || restored.moves[i] != b.moves[i] {
+			t.Fatalf("restored book differs from original at entry %v", i)
+		}
+	}
+}
+
+func TestReadBook(t *testing.T) {
+	if _, err := ReadBook(bytes.NewReader([]byte("not a book at all"))); !errors.As(err, new(BookFormatError)) {
+		t.Errorf("expected %T, observed %v", BookFormatError{}, err)
+	}
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, bookHeader{Magic: bookMagic, Width: 7, Height: 6, Entries: 1 << 60})
+	buf.Write(make([]byte, 100))
+	if _, err := ReadBook(&buf); !errors.As(err, new(BookSizeError)) {
+		t.Errorf("expected %T, observed %v", BookSizeError(0), err)
+	}
+}
+
+func TestGenerate_depth(t *testing.T) {
+	s, _ := solver.New(small, solver.Config{})
+	if _, err := Generate(context.Background(), s, -1, nil); !errors.Is(err, DepthError(-1)) {
+		t.Errorf("expected %v, observed %v", DepthError(-1), err)
+	}
+}
@@ -0,0 +1,24 @@
+package book
+
+import "fmt"
+
+// DepthError defines an error used when a book is requested to a depth that isn't possible in its geometry
+type DepthError int
+
+func (e DepthError) Error() string {
+	return fmt.Sprintf("book depth %v is out of range", int(e))
+}
+
+// BookFormatError defines an error used when reading a book from data that isn't a book
+type BookFormatError [4]byte
+
+func (e BookFormatError) Error() string {
+	return fmt.Sprintf("unrecognized book format %q", e[:])
+}
+
+// BookSizeError defines an error used when reading a book whose header claims more entries than its data holds
+type BookSizeError uint64
+
+func (e BookSizeError) Error() string {
+	return fmt.Sprintf("book header claims %v entries, which the data does not hold", uint64(e))
+}
@@ -0,0 +1,81 @@
+package book
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/solver"
+)
+
+// Generate builds a book of every unfinished position of the solver's geometry reachable in at most the given number
+// of plies. Positions are enumerated ply by ply, and then solved from the deepest ply back, such that only the
+// deepest positions need searching, with every shallower position scored from the entries of its children.
+// Progress, if not nil, is called after each ply is solved with the ply and the number of positions in it
+func Generate(ctx context.Context, s *solver.Solver, depth int, progress func(ply, positions int)) (*Book, error) {
+	g := s.Geometry()
+	if depth < 0 || depth > g.Cells() {
+		return nil, fmt.Errorf("cannot generate book: %w", DepthError(depth))
+	}
+
+	// Enumerate each ply, keeping one orientation of each pair of mirror images
+	plies := []map[uint64]bitboard.Position{{bitboard.New(g).Key(): bitboard.New(g)}}
+	for ply := 1; ply <= depth; ply++ {
+		next := map[uint64]bitboard.Position{}
+		for _, p := range plies[ply-1] {
+			for _, m := range p.LegalMoves() {
+				child := p.Play(int(m))
+				if child.IsOver() {
+					continue
+				}
+				if key, mirrored := canonical(child); mirrored {
+					next[key] = child.Mirror()
+				} else {
+					next[key] = child
+				}
+			}
+		}
+		plies = append(plies, next)
+	}
+
+	b := &Book{geometry: g, depth: depth}
+	solved := map[uint64]Entry{}
+	lookup := func(p bitboard.Position) (solver.Score, bool) {
+		key, _ := canonical(p)
+		e, ok := solved[key]
+		return e.Score, ok
+	}
+
+	for ply := depth; ply >= 0; ply-- {
+		for key, p := range plies[ply] {
+			e, err := best(ctx, s, p, lookup)
+			if err != nil {
+				return nil, fmt.Errorf("cannot generate book at ply %v: %w", ply, err)
+			}
+			solved[key] = e
+		}
+		if progress != nil {
+			progress(ply, len(plies[ply]))
+		}
+	}
+
+	for key, e := range solved {
+		b.keys = append(b.keys, key)
+		b.scores = append(b.scores, int8(e.Score))
+		b.moves = append(b.moves, uint8(e.Move))
+	}
+	sort.Sort(byKey{b})
+	return b, nil
+}
+
+// byKey sorts the entries of a book by key, keeping the parallel slices in step
+type byKey struct{ *Book }
+
+func (b byKey) Len() int           { return len(b.keys) }
+func (b byKey) Less(i, j int) bool { return b.keys[i] < b.keys[j] }
+func (b byKey) Swap(i, j int) {
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+	b.scores[i], b.scores[j] = b.scores[j], b.scores[i]
+	b.moves[i], b.moves[j] = b.moves[j], b.moves[i]
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/book"
+	"github.com/talglobus/fearsome/solver"
+)
+
+func init() {
+	commands["analyze"] = command{
+		summary: "Explore positions interactively, stepping through lines and querying the solver",
+		run:     analyze,
+	}
+}
+
+// analysisHelp lists the commands of the analysis REPL
+const analysisHelp = `Commands:
+  move <col>...     play moves in the given columns, numbered from 1
+  undo [n]          take back the last move, or the last n moves
+  show              show the board and its moves
+  load [notation]   set up the position reached by the given moves, or a file holding them, or the empty board
+  save <file>       write the moves of the position to a file, for loading later
+  eval              solve the position, reporting who wins and how quickly
+  best              solve every move of the position, reporting which are best
+  threats           list the squares in which either player would complete four
+  help              show this list
+  quit              leave the analysis`
+
+// analysis holds the state of an analysis session
+type analysis struct {
+	board  board.Board
+	solver *solver.Solver
+	book   *book.Book // Opening book consulted before the solver, if not nil
+	budget time.Duration
+	out    io.Writer
+}
+
+func analyze(ctx context.Context, args []string, s streams) error {
+	fs := newFlagSet("analyze", "[notation]", s)
+	budget := fs.Duration("budget", 30*time.Second, "time allowed per solver query, or unlimited if 0")
+	workers := fs.Int("workers", 1, "number of parallel search workers")
+	memory := fs.Int("memory", 64, "transposition table size, in MiB")
+	bookPath := fs.String("book", "", "opening book to consult before searching, as saved by fearsome book")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return usageError{fmt.Errorf("unexpected arguments %v", fs.Args()[1:])}
+	}
+
+	t, err := solver.NewTable(bitboard.Standard, *memory<<20, solver.TwoTier)
+	if err != nil {
+		return err
+	}
+	sv, err := solver.New(bitboard.Standard, solver.Config{Workers: *workers, Table: t})
+	if err != nil {
+		return err
+	}
+	a := &analysis{board: board.New(), solver: sv, budget: *budget, out: s.out}
+	if *bookPath != "" {
+		if a.book, err = book.Load(*bookPath); err != nil {
+			return err
+		}
+		if a.book.Geometry() != bitboard.Standard {
+			return fmt.Errorf("cannot use book: %w", bitboard.GeometryMismatchError{Want: bitboard.Standard,
+				Got: a.book.Geometry()})
+		}
+	}
+	fmt.Fprintln(s.out, "Enter a command, or help for the list of commands.")
+	if fs.NArg() == 1 {
+		if err := a.load(fs.Arg(0)); err != nil {
+			fs.Usage()
+			return usageError{err}
+		}
+	} else {
+		a.show()
+	}
+	in := readLines(s.in)
+	for {
+		fmt.Fprint(s.out, "> ")
+		var line string
+		var ok bool
+		select {
+		case line, ok = <-in:
+		case <-ctx.Done():
+			fmt.Fprintln(s.out)
+			return ctx.Err()
+		}
+		if !ok {
+			fmt.Fprintln(s.out)
+			return nil
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		cmd, args := strings.ToLower(fields[0]), fields[1:]
+		if cmd == "quit" || cmd == "q" || cmd == "exit" {
+			return nil
+		}
+		if err := a.dispatch(ctx, cmd, args); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			fmt.Fprintf(s.out, "Error: %v\n", err)
+		}
+	}
+}
+
+// dispatch runs a single command of the REPL
+func (a *analysis) dispatch(ctx context.Context, cmd string, args []string) error {
+	switch cmd {
+	case "move", "m":
+		if len(args) == 0 {
+			return errors.New("expected at least one column")
+		}
+		return a.move(args)
+	case "undo", "u":
+		n := 1
+		if len(args) > 0 {
+			var err error
+			if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+				return fmt.Errorf("cannot undo %q moves", args[0])
+			}
+		}
+		return a.undo(n)
+	case "show", "s":
+		a.show()
+	case "load", "l":
+		return a.load(strings.Join(args, ""))
+	case "save":
+		if len(args) != 1 {
+			return errors.New("expected a single file name")
+		}
+		if err := ioutil.WriteFile(args[0], []byte(a.board.History().Notation()+"\n"), 0644); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.out, "Saved %v moves to %v\n", len(a.board.History()), args[0])
+	case "eval", "e":
+		return a.eval(ctx)
+	case "best", "b":
+		return a.best(ctx)
+	case "threats", "t":
+		return a.threats()
+	case "help", "h", "?":
+		fmt.Fprintln(a.out, analysisHelp)
+	default:
+		return fmt.Errorf("unknown command %q, enter help for the list of commands", cmd)
+	}
+	return nil
+}
+
+// position returns the board's position, for analysis
+func (a *analysis) position() (bitboard.Position, error) {
+	return bitboard.FromBoard(a.board)
+}
+
+// move plays the moves in the given columns, stopping at the first that can't be played
+func (a *analysis) move(cols []string) error {
+	defer a.show()
+	for _, arg := range cols {
+		col, err := strconv.Atoi(arg)
+		if err != nil || col < 1 || col > board.COLS {
+			return fmt.Errorf("column %q is not a number from 1 to %v", arg, board.COLS)
+		}
+		p, err := a.position()
+		if err != nil {
+			return err
+		}
+		if p.IsOver() {
+			return errors.New("the game is over")
+		}
+		if _, _, err := a.board.Move(col - 1); err != nil {
+			if errors.As(err, new(board.FullColumnError)) {
+				return fmt.Errorf("column %v is full", col)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// undo takes back the last n moves, or as many as have been played
+func (a *analysis) undo(n int) error {
+	if len(a.board.History()) == 0 {
+		return errors.New("there are no moves to undo")
+	}
+	for i := 0; i < n && len(a.board.History()) > 0; i++ {
+		if err := a.board.Unmove(); err != nil {
+			return err
+		}
+	}
+	a.show()
+	return nil
+}
+
+// load replaces the board with the position reached by the given moves, or by the moves held in the named file if
+// the argument isn't notation
+func (a *analysis) load(arg string) error {
+	h, err := board.ParseHistory(arg)
+	if err != nil {
+		data, readErr := ioutil.ReadFile(arg)
+		if readErr != nil {
+			return err
+		}
+		if h, err = board.ParseHistory(strings.TrimSpace(string(data))); err != nil {
+			return fmt.Errorf("cannot load %v: %w", arg, err)
+		}
+	}
+	b, err := newBoard(h)
+	if err != nil {
+		return err
+	}
+	a.board = b
+	a.show()
+	return nil
+}
+
+// show renders the board, the moves played, and the player to move or the result
+func (a *analysis) show() {
+	fmt.Fprintln(a.out)
+	fmt.Fprintln(a.out, a.board.State())
+	var labels strings.Builder
+	for col := 1; col <= board.COLS; col++ {
+		fmt.Fprintf(&labels, "   %v  ", col)
+	}
+	fmt.Fprintln(a.out, strings.TrimRight(labels.String(), " "))
+
+	h := a.board.History()
+	status := "no moves"
+	if len(h) > 0 {
+		status = fmt.Sprintf("moves %v", h.Notation())
+	}
+	p, _ := a.position()
+	switch {
+	case p.Winner() != board.NONE:
+		status += fmt.Sprintf(", %v has won", p.Winner())
+	case p.IsFull():
+		status += ", drawn"
+	default:
+		status += fmt.Sprintf(", %v to move", p.Turn())
+	}
+	fmt.Fprintf(a.out, "%v (%v)\n\n", strings.ToUpper(status[:1])+status[1:], len(h))
+}
+
+// query runs a solver query within the configured budget
+func (a *analysis) query(ctx context.Context, f func(context.Context) error) error {
+	if a.budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.budget)
+		defer cancel()
+	}
+	start, nodes := time.Now(), a.solver.Nodes()
+	err := f(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("the solver didn't finish within %v", a.budget)
+	}
+	if err == nil {
+		fmt.Fprintf(a.out, "(%v nodes in %v)\n", a.solver.Nodes()-nodes,
+			time.Since(start).Round(time.Millisecond))
+	}
+	return err
+}
+
+// solve returns the score of a position, from the book if the position is in it, and by searching otherwise
+func (a *analysis) solve(ctx context.Context, p bitboard.Position) (solver.Score, error) {
+	if a.book != nil {
+		if e, ok := a.book.Probe(p); ok {
+			return e.Score, nil
+		}
+	}
+	return a.solver.Solve(ctx, p)
+}
+
+// outcome describes the outcome of a position under optimal play, given its score
+func outcome(s solver.Score, p bitboard.Position) string {
+	if w := s.Winner(p); w != board.NONE {
+		if n := s.Plies(p); n > 1 {
+			return fmt.Sprintf("%v wins in %v moves", w, n)
+		}
+		return fmt.Sprintf("%v wins with the next move", w)
+	}
+	return "draw"
+}
+
+// eval reports the score of the position
+func (a *analysis) eval(ctx context.Context) error {
+	p, err := a.position()
+	if err != nil {
+		return err
+	}
+	return a.query(ctx, func(ctx context.Context) error {
+		score, err := a.solve(ctx, p)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(a.out, "Score %+d: %v\n", score, outcome(score, p))
+		return nil
+	})
+}
+
+// best reports the score of every move of the position, marking the best
+func (a *analysis) best(ctx context.Context) error {
+	p, err := a.position()
+	if err != nil {
+		return err
+	}
+	if p.IsOver() {
+		return errors.New("the game is over")
+	}
+	return a.query(ctx, func(ctx context.Context) error {
+		scores, ok := make([]solver.Score, p.Geometry().Width), make([]bool, p.Geometry().Width)
+		best := solver.Score(-p.Geometry().Cells())
+		for col := range scores {
+			if !p.CanPlay(col) {
+				continue
+			}
+			score, err := a.solve(ctx, p.Play(col))
+			if err != nil {
+				return err
+			}
+			scores[col], ok[col] = -score, true
+			if -score > best {
+				best = -score
+			}
+		}
+		for col := range scores {
+			if !ok[col] {
+				fmt.Fprintf(a.out, "  %v  full\n", col+1)
+				continue
+			}
+			mark := ""
+			if scores[col] == best {
+				mark = "  best"
+			}
+			fmt.Fprintf(a.out, "  %v  %+3d  %v%v\n", col+1, scores[col], outcome(scores[col], p), mark)
+		}
+		return nil
+	})
+}
+
+// threats lists the squares in which each player would complete four, marking those playable immediately
+func (a *analysis) threats() error {
+	p, err := a.position()
+	if err != nil {
+		return err
+	}
+	for _, t := range []board.Type{board.RED, board.BLUE} {
+		var squares []string
+		for _, sq := range p.Threats(t) {
+			s := fmt.Sprintf("column %v row %v", sq.Col+1, sq.Row+1)
+			if p.Height(sq.Col) == sq.Row {
+				s += " (playable)"
+			}
+			squares = append(squares, s)
+		}
+		if len(squares) == 0 {
+			squares = []string{"none"}
+		}
+		fmt.Fprintf(a.out, "%v threats: %v\n", t, strings.Join(squares, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// endgame is a position solved within a few nodes, with RED able to win immediately in column 1 or 5
+const endgame = "444444333333222222"
+
+func TestAnalyze(t *testing.T) {
+	saved := filepath.Join(t.TempDir(), "line.txt")
+	table := []struct {
+		name  string
+		args  []string
+		input string
+		want  []string
+	}{
+		{"moves", nil, "move 4 4\nmove 3\nshow\n", []string{"Moves 443, BLÜ to move (3)"}},
+		{"undo", nil, "move 4 4 3\nundo\nundo 5\nundo\n", []string{"Moves 44, RED to move (2)",
+			"No moves, RED to move (0)", "there are no moves to undo"}},
+		{"illegal moves", nil, "move 8\nmove 1 1 1 1 1 1 1\n", []string{`column "8" is not a number from 1 to 7`,
+			"column 1 is full", "Moves 111111, RED to move"}},
+		{"load", nil, "load 4453\nload 4!\n", []string{"Moves 4453, RED to move (4)", "invalid move '!'"}},
+		{"eval", []string{endgame}, "eval\n", []string{"Score +12: RED wins with the next move"}},
+		{"best", []string{endgame}, "best\n", []string{"1  +12  RED wins with the next move  best", "2  full",
+			"6  +11  RED wins in 3 moves\n"}},
+		{"threats", []string{endgame}, "threats\n", []string{"RED threats: column 1 row 1 (playable), column 1 row 3",
+			"BLÜ threats: column 1 row 2,"}},
+		{"finished", []string{"1212121"}, "move 3\nbest\neval\n", []string{"RED has won", "the game is over",
+			"Score -18: RED wins"}},
+		{"save and load", nil, "move 4 5\nsave " + saved + "\nload\nload " + saved + "\n", []string{"Saved 2 moves",
+			"No moves, RED to move (0)", "Moves 45, RED to move (2)"}},
+		{"unknown command", nil, "frobnicate\n\nhelp\n", []string{`unknown command "frobnicate"`, "threats    "}},
+	}
+
+	for _, r := range table {
+		code, out, errs := runCommand(r.input+"quit\n", append([]string{"analyze"}, r.args...)...)
+		if code != 0 {
+			t.Errorf("%v: expected exit code 0, observed %v with error output:\n%v", r.name, code, errs)
+			continue
+		}
+		for _, w := range r.want {
+			if !strings.Contains(out, w) {
+				t.Errorf("%v: expected output to contain %q, observed:\n%v", r.name, w, out)
+			}
+		}
+	}
+
+	data, err := ioutil.ReadFile(saved)
+	if err != nil || string(data) != "45\n" {
+		t.Errorf("expected the saved file to hold the moves, observed %q with error %v", data, err)
+	}
+}
+
+func TestAnalyze_badArguments(t *testing.T) {
+	for _, args := range [][]string{
+		{"analyze", "4!"},
+		{"analyze", "44", "55"},
+	} {
+		if code, _, _ := runCommand("", args...); code != 2 {
+			t.Errorf("%v: expected exit code 2, observed %v", args, code)
+		}
+	}
+}
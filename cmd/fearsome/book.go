@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/book"
+	"github.com/talglobus/fearsome/solver"
+)
+
+func init() {
+	commands["book"] = command{
+		summary: "Generate an opening book of every position up to a number of plies, and save it to a file",
+		run:     generateBook,
+	}
+}
+
+func generateBook(ctx context.Context, args []string, s streams) error {
+	g := bitboard.Standard
+	fs := newFlagSet("book", "", s)
+	fs.Var(geometryFlag{&g}, "geometry", "board size, as columns x rows")
+	depth := fs.Int("depth", 4, "number of plies of the deepest positions in the book")
+	workers := fs.Int("workers", 1, "number of parallel search workers")
+	memory := fs.Int("memory", 64, "transposition table size, in MiB")
+	path := fs.String("o", "", "file to save the book to, which is required")
+	quiet := fs.Bool("q", false, "don't report progress on standard error")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return usageError{fmt.Errorf("unexpected arguments %v", fs.Args())}
+	}
+	if *path == "" {
+		fs.Usage()
+		return usageError{fmt.Errorf("no file given to save the book to")}
+	}
+
+	t, err := solver.NewTable(g, *memory<<20, solver.TwoTier)
+	if err != nil {
+		return err
+	}
+	sv, err := solver.New(g, solver.Config{Workers: *workers, Table: t})
+	if err != nil {
+		return err
+	}
+	var progress func(ply, positions int)
+	if !*quiet {
+		progress = func(ply, positions int) {
+			fmt.Fprintf(s.err, "ply %v: %v positions\n", ply, positions)
+		}
+	}
+
+	b, err := book.Generate(ctx, sv, *depth, progress)
+	if err != nil {
+		return err
+	}
+	if err := b.Save(*path); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "Saved %v positions of %v up to ply %v to %v\n", b.Len(), g, b.Depth(), *path)
+	return nil
+}
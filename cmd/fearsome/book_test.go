@@ -0,0 +1,45 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/book"
+)
+
+func TestBook(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book")
+	code, out, errs := runCommand("", "book", "-geometry", "4x4", "-depth", "3", "-memory", "1", "-o", path)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, observed %v with error output:\n%v", code, errs)
+	}
+	if !strings.Contains(out, "up to ply 3") || !strings.Contains(errs, "ply 0: 1 positions") {
+		t.Errorf("expected a summary and progress, observed:\n%v\n%v", out, errs)
+	}
+
+	b, err := book.Load(path)
+	if err != nil {
+		t.Fatalf("cannot load book: %v", err)
+	}
+	if b.Geometry() != (bitboard.Geometry{Width: 4, Height: 4}) || b.Depth() != 3 || b.Len() == 0 {
+		t.Errorf("expected a 4x4 book to ply 3, observed %v to ply %v of %v positions", b.Geometry(), b.Depth(),
+			b.Len())
+	}
+}
+
+func TestBook_badFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"book"},
+		{"book", "-o", "book", "extra"},
+		{"book", "-geometry", "9x9", "-o", "book"},
+	} {
+		if code, _, _ := runCommand("", args...); code != 2 {
+			t.Errorf("%v: expected exit code 2, observed %v", args, code)
+		}
+	}
+	if code, _, _ := runCommand("", "book", "-depth", "-1", "-o", filepath.Join(t.TempDir(), "book")); code != 1 {
+		t.Errorf("expected exit code 1 generating a book of negative depth, observed %v", code)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/talglobus/fearsome/engine"
+	"github.com/talglobus/fearsome/solver"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+func init() {
+	commands["engine"] = command{
+		summary: "Speak the engine protocol on standard input and output, choosing moves with a strategy or the solver",
+		run:     runEngine,
+	}
+}
+
+// solverEngine is the name of the engine playing optimally with the solver, in place of a strategy name
+const solverEngine = "solver"
+
+func runEngine(ctx context.Context, args []string, s streams) error {
+	fs := newFlagSet("engine", "", s)
+	name := fs.String("strategy", solverEngine, "engine choosing moves: solver, which plays optimally and reports "+
+		"scores, or a strategy such as greedy or lookahead:N")
+	workers := fs.Int("workers", 1, "number of parallel search workers of the solver")
+	memory := fs.Int("memory", 64, "transposition table size of the solver, in MiB")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return usageError{fmt.Errorf("unexpected arguments %v", fs.Args())}
+	}
+
+	wr := &engine.Wrapper{Name: "fearsome " + *name}
+	if *name == solverEngine {
+		wr.Engine = &engine.SolverEngine{Perfect: strategy.Perfect{Config: solver.Config{Workers: *workers},
+			Memory: *memory << 20}}
+	} else {
+		st, err := strategy.Parse(*name)
+		if err != nil {
+			fs.Usage()
+			return usageError{err}
+		}
+		wr.Engine = engine.StrategyEngine{Strategy: st}
+	}
+	return wr.Serve(ctx, s.in, s.out)
+}
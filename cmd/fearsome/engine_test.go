@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+// engineVariable names the environment variable that makes the test binary run the engine command, such that it can
+// be played against as an external engine
+const engineVariable = "FEARSOME_TEST_ENGINE"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(engineVariable) != "" {
+		os.Exit(run(context.Background(), []string{"engine", "-strategy", "greedy"}, streams{in: os.Stdin,
+			out: os.Stdout, err: os.Stderr}))
+	}
+	os.Exit(m.Run())
+}
+
+func TestEngine(t *testing.T) {
+	table := []struct {
+		name   string
+		args   []string
+		script string
+		want   []string
+	}{
+		{"solver", nil, "hello\nposition moves 444444333333222222\ngo\nquit\n", []string{"id name fearsome solver\n",
+			"hellook\n", "info score 12", "bestmove 5\n"}},
+		{"strategy", []string{"-strategy", "greedy"}, "position moves 121212\ngo\n", []string{"bestmove 1\n"}},
+	}
+
+	for _, r := range table {
+		code, out, errs := runCommand(r.script, append([]string{"engine"}, r.args...)...)
+		if code != 0 {
+			t.Errorf("%v: expected exit code 0, observed %v with error output:\n%v", r.name, code, errs)
+			continue
+		}
+		for _, w := range r.want {
+			if !strings.Contains(out, w) {
+				t.Errorf("%v: expected output to contain %q, observed:\n%v", r.name, w, out)
+			}
+		}
+	}
+
+	if code, _, _ := runCommand("", "engine", "-strategy", "clairvoyant"); code != 2 {
+		t.Errorf("expected exit code 2 for an unknown strategy, observed %v", code)
+	}
+}
+
+func TestPlay_engine(t *testing.T) {
+	os.Setenv(engineVariable, "1")
+	defer os.Unsetenv(engineVariable)
+
+	code, out, errs := runCommand("", "play", "-red", "engine:"+os.Args[0], "-blue", "random", "-seed", "1")
+	if code != 0 {
+		t.Fatalf("expected exit code 0, observed %v with error output:\n%v", code, errs)
+	}
+	if !strings.Contains(out, "RED (fearsome greedy) plays column") {
+		t.Errorf("expected the engine to play, observed:\n%v", out)
+	}
+
+	if code, _, _ := runCommand("", "play", "-red", "engine:"); code != 2 {
+		t.Errorf("expected exit code 2 for a missing engine command, observed %v", code)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/talglobus/fearsome/experiment"
+)
+
+func init() {
+	commands["experiment"] = command{
+		summary: "Run every combination listed by a JSON experiment spec, writing results and a manifest",
+		run:     runExperiment,
+	}
+}
+
+func runExperiment(ctx context.Context, args []string, s streams) error {
+	fs := newFlagSet("experiment", "spec.json", s)
+	dir := fs.String("o", "", "directory to write results to, by default named after the spec")
+	parallel := fs.Int("parallel", 0, "number of runs at once, overriding the spec if positive")
+	quiet := fs.Bool("q", false, "don't report progress on standard error")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return usageError{errors.New("expected a single spec file")}
+	}
+
+	spec, err := experiment.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *parallel > 0 {
+		spec.Parallel = *parallel
+	}
+	if *dir == "" {
+		*dir = spec.Name
+		if *dir == "" {
+			*dir = strings.TrimSuffix(filepath.Base(fs.Arg(0)), filepath.Ext(fs.Arg(0)))
+		}
+	}
+
+	// Runs report progress from any goroutine, so their lines are written one at a time
+	var progress func(experiment.Result)
+	var mutex sync.Mutex
+	if !*quiet {
+		progress = func(r experiment.Result) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if r.Error != "" {
+				fmt.Fprintf(s.err, "run %v: %v\n", r.ID, r.Error)
+				return
+			}
+			fmt.Fprintf(s.err, "run %v: %v %v vs %v, %v games: %v-%v-%v\n", r.ID, r.Geometry, r.Red, r.Blue, r.Games,
+				r.RedWins, r.BlueWins, r.Draws)
+		}
+	}
+
+	m, err := experiment.Execute(ctx, spec, *dir, progress)
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, r := range m.Runs {
+		if r.Error != "" {
+			failed++
+		}
+	}
+	fmt.Fprintf(s.out, "%v runs written to %v\n", len(m.Runs), *dir)
+	if failed > 0 {
+		return fmt.Errorf("%v of %v runs failed", failed, len(m.Runs))
+	}
+	return nil
+}
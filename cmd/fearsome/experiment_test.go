@@ -0,0 +1,49 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExperiment(t *testing.T) {
+	dir := t.TempDir()
+	spec := filepath.Join(dir, "spec.json")
+	data := `{"name": "sweep", "geometries": ["4x4"], "red": ["greedy"], "blue": ["random"], "games": [10],
+		"seeds": [1, 2]}`
+	if err := ioutil.WriteFile(spec, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	results := filepath.Join(dir, "results")
+	code, out, errs := runCommand("", "experiment", "-o", results, spec)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, observed %v with error output:\n%v", code, errs)
+	}
+	if !strings.Contains(out, "2 runs written") || !strings.Contains(errs, "run 1: 4x4 greedy vs random") {
+		t.Errorf("unexpected output:\n%v\n%v", out, errs)
+	}
+	for _, name := range []string{"manifest.json", "spec.json", "runs/0/summary.txt", "runs/1/summary.txt"} {
+		if _, err := os.Stat(filepath.Join(results, filepath.FromSlash(name))); err != nil {
+			t.Errorf("expected %v to be written: %v", name, err)
+		}
+	}
+
+	// Results are never overwritten
+	if code, _, _ := runCommand("", "experiment", "-o", results, spec); code != 1 {
+		t.Errorf("expected exit code 1 writing to a non-empty directory, observed %v", code)
+	}
+}
+
+func TestExperiment_badArguments(t *testing.T) {
+	for _, args := range [][]string{
+		{"experiment"},
+		{"experiment", "a.json", "b.json"},
+	} {
+		if code, _, _ := runCommand("", args...); code != 2 {
+			t.Errorf("%v: expected exit code 2, observed %v", args, code)
+		}
+	}
+}
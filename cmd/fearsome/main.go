@@ -0,0 +1,171 @@
+// Command fearsome exposes the analyses of the fearsome library from the command line, as a set of subcommands.
+//
+// Usage:
+//
+//	fearsome <command> [flags] [arguments]
+//
+// Run "fearsome help" for the list of commands, or "fearsome <command> -h" for the flags of a single command.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"github.com/talglobus/fearsome/bitboard"
+)
+
+// streams bundles the standard streams a command reads from and writes to, so that commands can be run in tests
+type streams struct {
+	in       io.Reader
+	out, err io.Writer
+}
+
+// command defines a single subcommand
+type command struct {
+	summary string
+	run     func(ctx context.Context, args []string, s streams) error
+}
+
+// commands holds every subcommand by name. It's populated by the init function of each subcommand's file, such that
+// adding a subcommand touches no other file
+var commands = map[string]command{}
+
+// usageError defines an error used when a command is invoked incorrectly, after the usage has been shown
+type usageError struct{ error }
+
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		// Commands stop once the context is done, but a second interrupt kills the process in case one doesn't
+		signal.Stop(interrupt)
+		cancel()
+	}()
+
+	os.Exit(run(ctx, os.Args[1:], streams{in: os.Stdin, out: os.Stdout, err: os.Stderr}))
+}
+
+// run dispatches to the named subcommand, returning the process exit code
+func run(ctx context.Context, args []string, s streams) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(s.err)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+
+	c, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(s.err, "fearsome: unknown command %q\n", args[0])
+		usage(s.err)
+		return 2
+	}
+
+	err := c.run(ctx, args[1:], s)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.As(err, &usageError{}):
+		return 2
+	default:
+		fmt.Fprintf(s.err, "fearsome %v: %v\n", args[0], err)
+		return 1
+	}
+}
+
+// usage lists the available subcommands
+func usage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "Usage: fearsome <command> [flags] [arguments]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10v %v\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(w, "\nRun \"fearsome <command> -h\" for the flags of a command.")
+}
+
+// newFlagSet constructs a flag set for a subcommand, writing its usage to the error stream
+func newFlagSet(name, arguments string, s streams) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(s.err)
+	fs.Usage = func() {
+		fmt.Fprintf(s.err, "Usage: fearsome %v [flags]%v\n\n%v\n\nFlags:\n", name,
+			strings.TrimRight(" "+arguments, " "), commands[name].summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses a subcommand's flags, wrapping failures as usage errors
+func parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageError{err}
+	}
+	return nil
+}
+
+// output opens the named file for writing, or returns the output stream if the name is empty or "-"
+func output(path string, s streams) (io.Writer, func() error, error) {
+	if path == "" || path == "-" {
+		return s.out, func() error { return nil }, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f.Close, nil
+}
+
+// readLines reads trimmed lines from the input as they're needed, closing the channel once the input ends, such that
+// interactive commands can wait for the context to be done as well as for input
+func readLines(r io.Reader) <-chan string {
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		in := bufio.NewScanner(r)
+		for in.Scan() {
+			ch <- strings.TrimSpace(in.Text())
+		}
+	}()
+	return ch
+}
+
+// geometryFlag adapts a bitboard.Geometry to the flag.Value interface
+type geometryFlag struct{ *bitboard.Geometry }
+
+func (f geometryFlag) String() string {
+	if f.Geometry == nil {
+		return ""
+	}
+	return f.Geometry.String()
+}
+
+func (f geometryFlag) Set(s string) error {
+	g, err := bitboard.ParseGeometry(s)
+	if err != nil {
+		return err
+	}
+	*f.Geometry = g
+	return nil
+}
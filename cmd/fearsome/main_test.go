@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runCommand runs the command line with the given standard input, returning the exit code and output streams
+func runCommand(input string, args ...string) (int, string, string) {
+	var out, err bytes.Buffer
+	code := run(context.Background(), args, streams{in: strings.NewReader(input), out: &out, err: &err})
+	return code, out.String(), err.String()
+}
+
+func TestRun(t *testing.T) {
+	table := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"no command", nil, 2},
+		{"help", []string{"help"}, 0},
+		{"unknown command", []string{"frobnicate"}, 2},
+		{"command help", []string{"book", "-h"}, 0},
+		{"unknown flag", []string{"book", "-frobnicate"}, 2},
+	}
+
+	for _, r := range table {
+		if code, _, _ := runCommand("", r.args...); code != r.code {
+			t.Errorf("%v: expected exit code %v, observed %v", r.name, r.code, code)
+		}
+	}
+}
+
+// TestRun_interrupt checks that interactive commands waiting for input stop once the context is done, as on an
+// interrupt, rather than waiting for input that may never come
+func TestRun_interrupt(t *testing.T) {
+	for _, args := range [][]string{
+		{"play", "-blue", "human"},
+		{"analyze"},
+	} {
+		in, w := io.Pipe()
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan int)
+		go func() {
+			done <- run(ctx, args, streams{in: in, out: ioutil.Discard, err: ioutil.Discard})
+		}()
+
+		cancel()
+		select {
+		case code := <-done:
+			if code != 1 {
+				t.Errorf("%v: expected exit code 1, observed %v", args, code)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("%v: expected command to stop once interrupted", args)
+		}
+		w.Close()
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/netplay"
+)
+
+func init() {
+	commands["host"] = command{
+		summary: "Host a game played against another human over the network, who joins it with the join command",
+		run:     host,
+	}
+	commands["join"] = command{
+		summary: "Join a game hosted over the network by another human with the host command",
+		run:     join,
+	}
+}
+
+func host(ctx context.Context, args []string, s streams) error {
+	fs := newFlagSet("host", "", s)
+	addr := fs.String("addr", ":4444", "address to listen on, as host:port")
+	color := fs.String("color", "red", "color to play, either red, who moves first, or blue")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return usageError{fmt.Errorf("unexpected arguments %q", fs.Args())}
+	}
+	local := map[string]board.Type{"red": board.RED, "blue": board.BLUE}[strings.ToLower(*color)]
+	if local == board.NONE {
+		fs.Usage()
+		return usageError{fmt.Errorf("unknown color %q", *color)}
+	}
+
+	h, err := netplay.Listen(*addr, local)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- h.Serve(ctx)
+	}()
+
+	fmt.Fprintf(s.out, "Hosting on %v as %v\n", h.Addr(), local)
+	err = remoteGame{player: h, in: readLines(s.in), out: s.out}.run(ctx)
+	cancel()
+	if serveErr := <-done; !errors.Is(serveErr, context.Canceled) && err == nil {
+		err = serveErr
+	}
+	return err
+}
+
+func join(ctx context.Context, args []string, s streams) error {
+	fs := newFlagSet("join", "<host:port>", s)
+	retries := fs.Int("retries", 5, "number of attempts to resume the game after losing the connection")
+	token := fs.String("token", "", "token of a game joined earlier, to resume it, such as after a restart")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return usageError{errors.New("expected the address of the host, as host:port")}
+	}
+
+	var c *netplay.Client
+	var err error
+	if *token != "" {
+		c, err = netplay.Resume(ctx, fs.Arg(0), *token)
+	} else {
+		c, err = netplay.Join(ctx, fs.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	fmt.Fprintf(s.out, "Joined game at %v as %v\n", fs.Arg(0), c.Color())
+	fmt.Fprintf(s.out, "To resume this game, run: fearsome join -token %v %v\n", c.Token(), fs.Arg(0))
+
+	g := remoteGame{player: c, in: readLines(s.in), out: s.out}
+	g.reconnect = func(ctx context.Context) error {
+		fmt.Fprintf(s.out, "Lost connection to host: %v\n", c.Err())
+		delay := time.Second
+		for i := 0; ; i++ {
+			err := c.Reconnect(ctx)
+			if err == nil {
+				fmt.Fprintln(s.out, "Reconnected")
+				return nil
+			}
+			if i >= *retries || ctx.Err() != nil {
+				return err
+			}
+			fmt.Fprintf(s.out, "Cannot reconnect, retrying in %v: %v\n", delay, err)
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			delay *= 2
+		}
+	}
+	return g.run(ctx)
+}
+
+// remoteGame holds the state of a game played in the terminal against a human over the network
+type remoteGame struct {
+	player netplay.Player
+	in     <-chan string
+	out    io.Writer
+	// reconnect restores a lost connection to the opponent, or is nil if the opponent is to be waited for instead
+	reconnect func(ctx context.Context) error
+}
+
+// run plays the game until it ends, the local player quits, input ends, or the context is done
+func (g remoteGame) run(ctx context.Context) error {
+	color := g.player.Color()
+	shown, waiting := -1, false
+	for {
+		s, changed := g.player.State()
+		if len(s.Moves) != shown {
+			if len(s.Moves) > 0 && shown >= 0 {
+				last := len(s.Moves) - 1
+				fmt.Fprintf(g.out, "%v plays column %v\n", turnOf(last), int(s.Moves[last])+1)
+			}
+			b, err := newBoard(s.Moves)
+			if err != nil {
+				return err
+			}
+			(&game{board: b, out: g.out}).show()
+			shown = len(s.Moves)
+		}
+
+		switch {
+		case s.Over():
+			switch s.Winner() {
+			case board.NONE:
+				fmt.Fprintf(g.out, "Draw after %v moves\n", len(s.Moves))
+			case color:
+				fmt.Fprintf(g.out, "You win after %v moves\n", len(s.Moves))
+			default:
+				fmt.Fprintf(g.out, "You lose after %v moves\n", len(s.Moves))
+			}
+			return nil
+		case !s.Opponent && g.reconnect != nil:
+			if err := g.reconnect(ctx); err != nil {
+				return fmt.Errorf("cannot resume game: %w", err)
+			}
+			continue
+		case !s.Opponent || s.Turn() != color:
+			if !waiting {
+				if s.Opponent {
+					fmt.Fprintln(g.out, "Waiting for your opponent to move")
+				} else {
+					fmt.Fprintln(g.out, "Waiting for an opponent to connect")
+				}
+				waiting = true
+			}
+			select {
+			case <-changed:
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		waiting = false
+
+		fmt.Fprintf(g.out, "%v to move. Enter a column (1-%v), or q to quit: ", color, board.COLS)
+		var cmd string
+		var ok bool
+		select {
+		case cmd, ok = <-g.in:
+			cmd = strings.ToLower(cmd)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if !ok || cmd == "q" || cmd == "quit" {
+			fmt.Fprintln(g.out)
+			return nil
+		}
+		col, err := strconv.Atoi(cmd)
+		if err != nil || col < 1 || col > board.COLS {
+			fmt.Fprintf(g.out, "Unknown command %q\n", cmd)
+			continue
+		}
+		switch err := g.player.Move(ctx, col-1); {
+		case errors.As(err, new(board.FullColumnError)):
+			fmt.Fprintf(g.out, "Column %v is full\n", col)
+		case errors.As(err, &netplay.DisconnectedError{}):
+			fmt.Fprintln(g.out, "Move not played, as the connection was lost")
+		case err != nil:
+			return err
+		}
+	}
+}
+
+// turnOf returns the player making the move at the given index of a game
+func turnOf(i int) board.Type {
+	if i%2 == 0 {
+		return board.RED
+	}
+	return board.BLUE
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/talglobus/fearsome/netplay"
+)
+
+func TestHostAndJoin(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	r, w := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- host(ctx, []string{"-addr", "127.0.0.1:0"}, streams{in: strings.NewReader("1\n1\n1\n1\n"), out: w,
+			err: ioutil.Discard})
+		w.Close()
+	}()
+
+	out := bufio.NewReader(r)
+	line, err := out.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "Hosting on ") {
+		t.Fatalf("cannot read address from %q: %v", line, err)
+	}
+	addr := strings.Fields(line)[2]
+	var hosted bytes.Buffer
+	copied := make(chan struct{})
+	go func() {
+		io.Copy(&hosted, out)
+		close(copied)
+	}()
+
+	var joined bytes.Buffer
+	if err := join(ctx, []string{addr}, streams{in: strings.NewReader("2\n9\n2\n2\n"), out: &joined,
+		err: ioutil.Discard}); err != nil {
+		t.Errorf("cannot join: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("cannot host: %v", err)
+	}
+	<-copied
+
+	if !strings.Contains(hosted.String(), "You win after 7 moves") {
+		t.Errorf("expected host to win, observed output:\n%v", hosted.String())
+	}
+	if !strings.Contains(joined.String(), "Unknown command \"9\"") ||
+		!strings.Contains(joined.String(), "You lose after 7 moves") {
+		t.Errorf("expected joining player to lose, observed output:\n%v", joined.String())
+	}
+}
+
+func TestJoin_token(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	r, w := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- host(ctx, []string{"-addr", "127.0.0.1:0"}, streams{in: strings.NewReader("1\n1\n1\n1\n"), out: w,
+			err: ioutil.Discard})
+		w.Close()
+	}()
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil {
+		t.Fatalf("cannot read address: %v", err)
+	}
+	addr := strings.Fields(line)[2]
+	go io.Copy(ioutil.Discard, r)
+
+	// A player joins, then leaves, as if their process had ended, keeping the seat for its token
+	first, err := netplay.Join(ctx, addr)
+	if err != nil {
+		t.Fatalf("cannot join: %v", err)
+	}
+	token := first.Token()
+	first.Close()
+
+	var joined bytes.Buffer
+	if err := join(ctx, []string{"-token", token, addr}, streams{in: strings.NewReader("2\n2\n2\n"), out: &joined,
+		err: ioutil.Discard}); err != nil {
+		t.Errorf("cannot resume: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("cannot host: %v", err)
+	}
+	if !strings.Contains(joined.String(), "fearsome join -token "+token+" "+addr) ||
+		!strings.Contains(joined.String(), "You lose after 7 moves") {
+		t.Errorf("expected the resumed game to be played out, observed output:\n%v", joined.String())
+	}
+}
+
+func TestHostAndJoin_usage(t *testing.T) {
+	table := [][]string{
+		{"host", "-color", "green"},
+		{"host", "extra"},
+		{"join"},
+		{"join", "a:1", "b:2"},
+	}
+	for _, args := range table {
+		if code, _, _ := runCommand("", args...); code != 2 {
+			t.Errorf("%v: expected exit code 2, observed %v", args, code)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/record"
+	"github.com/talglobus/fearsome/stats"
+)
+
+func init() {
+	commands["filter"] = command{
+		summary: "Select game records by result, length, opening, or a position reached",
+		run:     filter,
+	}
+	commands["stats"] = command{
+		summary: "Summarize game records, with win rates, game lengths, and column usage",
+		run:     summarizeRecords,
+	}
+	commands["dedupe"] = command{
+		summary: "Drop game records ending in a position already seen, counting mirror images as the same",
+		run:     dedupe,
+	}
+	commands["convert"] = command{
+		summary: "Convert game records between formats",
+		run:     convert,
+	}
+}
+
+// The formats in which the pipeline commands read and write game records: newline-delimited JSON records, CSV with a
+// header row, or lines of move notation from the empty board
+const (
+	recordsFormat = "records"
+	csvFormat     = "csv"
+	movesFormat   = "moves"
+)
+
+// recordReader reads game records in any format
+type recordReader interface {
+	Read() (record.Record, error)
+}
+
+// recordWriter writes game records in any format, some of which buffer writes until flushed
+type recordWriter interface {
+	Write(record.Record) error
+	Flush() error
+}
+
+// unbuffered adapts a writer of records that doesn't buffer writes to recordWriter
+type unbuffered struct {
+	write func(record.Record) error
+}
+
+func (w unbuffered) Write(r record.Record) error { return w.write(r) }
+func (w unbuffered) Flush() error                { return nil }
+
+// pipeFlags holds the flags shared by the pipeline commands
+type pipeFlags struct {
+	from, to string
+	geometry bitboard.Geometry
+}
+
+// register adds the flags shared by the pipeline commands to a flag set, including the output format if writing
+func (f *pipeFlags) register(fs *flag.FlagSet, writes bool) {
+	f.geometry = bitboard.Standard
+	fs.StringVar(&f.from, "from", recordsFormat, "input format: records (newline-delimited JSON), csv, or moves "+
+		"(one game of move notation per line)")
+	fs.Var(geometryFlag{&f.geometry}, "geometry", "board size of games read as moves, as columns x rows")
+	if writes {
+		fs.StringVar(&f.to, "to", recordsFormat, "output format, as for -from")
+	}
+}
+
+// validate checks the formats named by the flags
+func (f *pipeFlags) validate() error {
+	for _, format := range []string{f.from, f.to} {
+		switch format {
+		case "", recordsFormat, csvFormat, movesFormat:
+		default:
+			return fmt.Errorf("unknown format %q", format)
+		}
+	}
+	return nil
+}
+
+// reader returns a reader of records from the named files in turn, or from the input stream if there are none
+func (f *pipeFlags) reader(paths []string, s streams) (recordReader, func() error, error) {
+	in, closeInput := s.in, func() error { return nil }
+	if len(paths) > 0 {
+		var readers []io.Reader
+		var files []*os.File
+		for _, path := range paths {
+			file, err := os.Open(path)
+			if err != nil {
+				for _, file := range files {
+					file.Close()
+				}
+				return nil, nil, err
+			}
+			files, readers = append(files, file), append(readers, file)
+		}
+		in = io.MultiReader(readers...)
+		closeInput = func() error {
+			for _, file := range files {
+				file.Close()
+			}
+			return nil
+		}
+	}
+
+	switch f.from {
+	case csvFormat:
+		return record.NewCSVReader(in), closeInput, nil
+	case movesFormat:
+		return record.NewMovesReader(in, f.geometry), closeInput, nil
+	default:
+		return record.NewReader(in), closeInput, nil
+	}
+}
+
+// writer returns a writer of records to the output stream
+func (f *pipeFlags) writer(s streams) recordWriter {
+	switch f.to {
+	case csvFormat:
+		return record.NewCSVWriter(s.out)
+	case movesFormat:
+		return unbuffered{record.NewMovesWriter(s.out).Write}
+	default:
+		return unbuffered{record.NewWriter(s.out).Write}
+	}
+}
+
+// pipe copies the records read from the input to the output, keeping only those for which keep returns true
+func pipe(ctx context.Context, f *pipeFlags, paths []string, s streams,
+	keep func(record.Record) (bool, error)) error {
+	r, closeInput, err := f.reader(paths, s)
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+
+	w := f.writer(s)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if ok, err := keep(rec); err != nil {
+			return fmt.Errorf("game %v: %w", rec.Index, err)
+		} else if !ok {
+			continue
+		}
+		if err := w.Write(rec); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// historyFlag adapts a board.History to the flag.Value interface, in move notation
+type historyFlag struct{ *board.History }
+
+func (f historyFlag) String() string {
+	if f.History == nil {
+		return ""
+	}
+	return f.History.Notation()
+}
+
+func (f historyFlag) Set(s string) error {
+	h, err := board.ParseHistory(s)
+	if err != nil {
+		return err
+	}
+	*f.History = h
+	return nil
+}
+
+// resultsFlag adapts a list of results to the flag.Value interface, as a comma-separated list
+type resultsFlag struct{ results *[]record.Result }
+
+func (f resultsFlag) String() string {
+	if f.results == nil {
+		return ""
+	}
+	names := make([]string, len(*f.results))
+	for i, r := range *f.results {
+		names[i] = r.String()
+	}
+	return strings.Join(names, ",")
+}
+
+func (f resultsFlag) Set(s string) error {
+	*f.results = nil
+	for _, name := range strings.Split(s, ",") {
+		var r record.Result
+		if err := r.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+			return err
+		}
+		*f.results = append(*f.results, r)
+	}
+	return nil
+}
+
+func filter(ctx context.Context, args []string, s streams) error {
+	var pf pipeFlags
+	var f record.Filter
+	fs := newFlagSet("filter", "[file...]", s)
+	pf.register(fs, true)
+	fs.Var(resultsFlag{&f.Results}, "result", "results to keep, as a comma-separated list of red, blue, draw, "+
+		"and unfinished")
+	fs.IntVar(&f.MinLength, "min-length", 0, "least number of moves of games to keep")
+	fs.IntVar(&f.MaxLength, "max-length", 0, "greatest number of moves of games to keep, or no limit if 0")
+	fs.Var(historyFlag{&f.Prefix}, "prefix", "opening moves of games to keep, in notation such as 4453")
+	fs.Var(historyFlag{&f.Reaches}, "reaches", "moves leading to a position that games to keep pass through, by "+
+		"any move order")
+	fs.BoolVar(&f.Mirrored, "mirror", false, "also match the mirror images of -prefix and -reaches")
+	invert := fs.Bool("v", false, "keep the games that don't match, rather than those that do")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := pf.validate(); err != nil {
+		fs.Usage()
+		return usageError{err}
+	}
+
+	return pipe(ctx, &pf, fs.Args(), s, func(r record.Record) (bool, error) {
+		ok, err := f.Match(r)
+		return ok != *invert, err
+	})
+}
+
+func dedupe(ctx context.Context, args []string, s streams) error {
+	var pf pipeFlags
+	fs := newFlagSet("dedupe", "[file...]", s)
+	pf.register(fs, true)
+	exact := fs.Bool("exact", false, "count mirror images as different positions")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := pf.validate(); err != nil {
+		fs.Usage()
+		return usageError{err}
+	}
+
+	d := record.NewDeduplicator(!*exact)
+	return pipe(ctx, &pf, fs.Args(), s, d.Add)
+}
+
+func convert(ctx context.Context, args []string, s streams) error {
+	var pf pipeFlags
+	fs := newFlagSet("convert", "[file...]", s)
+	pf.register(fs, true)
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := pf.validate(); err != nil {
+		fs.Usage()
+		return usageError{err}
+	}
+
+	return pipe(ctx, &pf, fs.Args(), s, func(record.Record) (bool, error) { return true, nil })
+}
+
+func summarizeRecords(ctx context.Context, args []string, s streams) error {
+	var pf pipeFlags
+	fs := newFlagSet("stats", "[file...]", s)
+	pf.register(fs, false)
+	format := fs.String("format", "table", "output format, either table or csv")
+	confidence := fs.Float64("confidence", 0.95, "confidence level of the reported intervals")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := pf.validate(); err != nil {
+		fs.Usage()
+		return usageError{err}
+	}
+	if *format != "table" && *format != "csv" {
+		fs.Usage()
+		return usageError{fmt.Errorf("unknown format %q", *format)}
+	}
+	if *confidence <= 0 || *confidence >= 1 {
+		fs.Usage()
+		return usageError{errors.New("confidence must be between 0 and 1")}
+	}
+
+	// Summaries hold games of a single geometry, so games are summarized separately for each geometry found
+	var geometries []bitboard.Geometry
+	summaries := map[bitboard.Geometry]*stats.Summary{}
+	unfinished := 0
+	err := pipe(ctx, &pf, fs.Args(), streams{in: s.in, out: ioutil.Discard, err: s.err},
+		func(r record.Record) (bool, error) {
+			p, err := r.Position()
+			if err != nil {
+				return false, err
+			}
+			if !p.IsOver() {
+				unfinished++
+				return false, nil
+			}
+			g := p.Geometry()
+			if summaries[g] == nil {
+				summaries[g] = stats.New(g, *confidence)
+				geometries = append(geometries, g)
+			}
+			return false, summaries[g].Add(r.History())
+		})
+	if err != nil {
+		return err
+	}
+	if unfinished > 0 {
+		fmt.Fprintf(s.err, "skipped %v unfinished games\n", unfinished)
+	}
+
+	if *format == "csv" {
+		return writeSummariesCSV(s.out, geometries, summaries)
+	}
+	for i, g := range geometries {
+		if len(geometries) > 1 {
+			if i > 0 {
+				fmt.Fprintln(s.out)
+			}
+			fmt.Fprintf(s.out, "%v:\n", g)
+		}
+		if err := stats.WriteTable(s.out, summaries[g]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSummariesCSV writes summaries as a single CSV table, prefixing each row of stats.WriteCSV with the geometry of
+// the summary
+func writeSummariesCSV(w io.Writer, geometries []bitboard.Geometry,
+	summaries map[bitboard.Geometry]*stats.Summary) error {
+	cw := csv.NewWriter(w)
+	for i, g := range geometries {
+		var buf bytes.Buffer
+		if err := stats.WriteCSV(&buf, summaries[g]); err != nil {
+			return err
+		}
+		rows, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			return err
+		}
+		for j, row := range rows {
+			switch {
+			case j == 0 && i == 0:
+				cw.Write(append([]string{"geometry"}, row...))
+			case j > 0:
+				cw.Write(append([]string{g.String()}, row...))
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
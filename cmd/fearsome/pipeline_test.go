@@ -0,0 +1,148 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/talglobus/fearsome/record"
+)
+
+// games holds records of four games: a win for RED, a win for BLUE, a transposition of the win for RED reaching the
+// same final position, and the mirror image of the win for RED
+const games = `{"index":0,"geometry":{"cols":7,"rows":6},"moves":"441212121","result":"red"}
+{"index":1,"geometry":{"cols":7,"rows":6},"moves":"14747474","result":"blue"}
+{"index":2,"geometry":{"cols":7,"rows":6},"moves":"124412121","result":"red"}
+{"index":3,"geometry":{"cols":7,"rows":6},"moves":"447676767","result":"red"}
+`
+
+// indices reads records from the output of a pipeline command, returning their indices
+func indices(t *testing.T, out string) []int {
+	t.Helper()
+	records, err := record.ReadAll(strings.NewReader(out))
+	if err != nil {
+		t.Fatalf("cannot read output: %v\n%v", err, out)
+	}
+	var got []int
+	for _, r := range records {
+		got = append(got, r.Index)
+	}
+	return got
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPipeline(t *testing.T) {
+	table := []struct {
+		name string
+		args []string
+		want []int
+	}{
+		{"filter result", []string{"filter", "-result", "blue"}, []int{1}},
+		{"filter results", []string{"filter", "-result", "red, blue"}, []int{0, 1, 2, 3}},
+		{"filter inverted", []string{"filter", "-v", "-result", "red"}, []int{1}},
+		{"filter length", []string{"filter", "-max-length", "8"}, []int{1}},
+		{"filter prefix", []string{"filter", "-prefix", "44"}, []int{0, 3}},
+		{"filter reaches", []string{"filter", "-reaches", "4412"}, []int{0, 2}},
+		{"filter mirrored", []string{"filter", "-mirror", "-reaches", "4412"}, []int{0, 2, 3}},
+		{"dedupe", []string{"dedupe"}, []int{0, 1}},
+		{"dedupe exact", []string{"dedupe", "-exact"}, []int{0, 1, 3}},
+		{"convert", []string{"convert"}, []int{0, 1, 2, 3}},
+	}
+
+	for _, r := range table {
+		code, out, errs := runCommand(games, r.args...)
+		if code != 0 {
+			t.Errorf("%v: expected exit code 0, observed %v with error output:\n%v", r.name, code, errs)
+			continue
+		}
+		if got := indices(t, out); !equalInts(got, r.want) {
+			t.Errorf("%v: expected games %v, observed %v", r.name, r.want, got)
+		}
+	}
+}
+
+func TestPipeline_formats(t *testing.T) {
+	code, out, errs := runCommand(games, "convert", "-to", "csv")
+	if code != 0 {
+		t.Fatalf("expected exit code 0, observed %v with error output:\n%v", code, errs)
+	}
+	if !strings.HasPrefix(out, "event,index,red,blue,geometry,start,moves,result,seed\n") {
+		t.Errorf("unexpected CSV output:\n%v", out)
+	}
+
+	code, out, errs = runCommand(out, "filter", "-from", "csv", "-to", "moves", "-result", "red")
+	if code != 0 {
+		t.Fatalf("expected exit code 0, observed %v with error output:\n%v", code, errs)
+	}
+	if want := "441212121\n124412121\n447676767\n"; out != want {
+		t.Errorf("expected moves output %q, observed %q", want, out)
+	}
+
+	code, out, errs = runCommand(out, "dedupe", "-from", "moves")
+	if code != 0 {
+		t.Fatalf("expected exit code 0, observed %v with error output:\n%v", code, errs)
+	}
+	if got := indices(t, out); !equalInts(got, []int{0}) {
+		t.Errorf("expected games [0], observed %v", got)
+	}
+}
+
+func TestPipeline_stats(t *testing.T) {
+	input := games + `{"index":4,"geometry":{"cols":7,"rows":6},"moves":"44","result":"unfinished"}` + "\n"
+	table := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"table", nil, []string{"4 games"}},
+		{"csv", []string{"-format", "csv"}, []string{"geometry,section,key,count,proportion,low,high\n7x6,"}},
+	}
+
+	for _, r := range table {
+		code, out, errs := runCommand(input, append([]string{"stats"}, r.args...)...)
+		if code != 0 {
+			t.Errorf("%v: expected exit code 0, observed %v with error output:\n%v", r.name, code, errs)
+			continue
+		}
+		for _, w := range r.want {
+			if !strings.Contains(out, w) {
+				t.Errorf("%v: expected output to contain %q, observed:\n%v", r.name, w, out)
+			}
+		}
+		if !strings.Contains(errs, "skipped 1 unfinished games") {
+			t.Errorf("%v: expected unfinished games to be reported, observed %q", r.name, errs)
+		}
+	}
+}
+
+func TestPipeline_errors(t *testing.T) {
+	table := []struct {
+		name  string
+		input string
+		args  []string
+		code  int
+	}{
+		{"unknown format", games, []string{"convert", "-to", "xml"}, 2},
+		{"unknown result", games, []string{"filter", "-result", "purple"}, 2},
+		{"bad prefix", games, []string{"filter", "-prefix", "4!"}, 2},
+		{"bad input", "not json\n", []string{"convert"}, 1},
+		{"illegal moves", "4444444\n", []string{"convert", "-from", "moves"}, 1},
+		{"missing file", "", []string{"convert", "nonexistent.ndjson"}, 1},
+	}
+
+	for _, r := range table {
+		if code, _, _ := runCommand(r.input, r.args...); code != r.code {
+			t.Errorf("%v: expected exit code %v, observed %v", r.name, r.code, code)
+		}
+	}
+}
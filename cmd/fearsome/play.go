@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/engine"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+func init() {
+	commands["play"] = command{
+		summary: "Play a game in the terminal, against another human or a computer player",
+		run:     play,
+	}
+}
+
+// human is the name of the player entering moves at the terminal, in place of a strategy name
+const human = "human"
+
+// enginePrefix precedes the command line of an external engine, in place of a strategy name
+const enginePrefix = "engine:"
+
+// player describes one side of a game, being a human if its strategy is nil
+type player struct {
+	name     string
+	strategy strategy.Strategy
+}
+
+// game holds the state of a game being played in the terminal
+type game struct {
+	board   board.Board
+	players map[board.Type]player
+	rng     *rand.Rand
+	in      <-chan string
+	out     io.Writer
+}
+
+func play(ctx context.Context, args []string, s streams) error {
+	fs := newFlagSet("play", "", s)
+	red := fs.String("red", human, "RED player, who moves first: human, a computer strategy such as random, "+
+		"greedy, lookahead:N, or perfect, in increasing order of strength, or engine:COMMAND to run an external "+
+		"engine speaking the engine protocol")
+	blue := fs.String("blue", "lookahead:4", "BLUE player, as for -red")
+	seed := fs.Int64("seed", 0, "seed of the computer players' randomness, or chosen from the time if 0")
+	moveTime := fs.Duration("movetime", 0, "time allowed to external engines for each move, or their choice if 0")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return usageError{fmt.Errorf("unexpected arguments %v", fs.Args())}
+	}
+
+	g := game{board: board.New(), players: map[board.Type]player{}, in: readLines(s.in), out: s.out}
+	for i, name := range []string{*red, *blue} {
+		p := player{name: name}
+		switch {
+		case strings.HasPrefix(name, enginePrefix):
+			command := strings.Fields(strings.TrimPrefix(name, enginePrefix))
+			if len(command) == 0 {
+				fs.Usage()
+				return usageError{fmt.Errorf("expected a command after %v", enginePrefix)}
+			}
+			d, err := engine.Start(ctx, command[0], command[1:]...)
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+			d.MoveTime = *moveTime
+			p.name, p.strategy = d.String(), d
+		case name != human:
+			var err error
+			if p.strategy, err = strategy.Parse(name); err != nil {
+				fs.Usage()
+				return usageError{err}
+			}
+		}
+		g.players[[]board.Type{board.RED, board.BLUE}[i]] = p
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	g.rng = rand.New(rand.NewSource(*seed))
+
+	return g.run(ctx)
+}
+
+// run plays the game until a player quits, input ends, or the context is done. A finished game may still be undone
+// by a human player
+func (g *game) run(ctx context.Context) error {
+	g.show()
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		p, err := bitboard.FromBoard(g.board)
+		if err != nil {
+			return err
+		}
+		turn := p.Turn()
+		mover := g.players[turn]
+
+		if p.IsOver() {
+			g.announce(p)
+			if !g.hasHuman() {
+				return nil
+			}
+			cmd, ok := g.prompt(ctx, "Game over. Enter u to undo, or q to quit: ")
+			if !ok {
+				return ctx.Err()
+			}
+			if cmd == "q" || cmd == "quit" {
+				return nil
+			}
+			if cmd == "u" || cmd == "undo" {
+				g.undo()
+			} else {
+				fmt.Fprintf(g.out, "Unknown command %q\n", cmd)
+			}
+			continue
+		}
+
+		if mover.strategy != nil {
+			m, err := mover.strategy.Choose(ctx, p, g.rng)
+			if err != nil {
+				return fmt.Errorf("%v cannot choose a move: %w", mover.name, err)
+			}
+			if _, _, err := g.board.Move(int(m)); err != nil {
+				return fmt.Errorf("%v chose an illegal move: %w", mover.name, err)
+			}
+			fmt.Fprintf(g.out, "%v (%v) plays column %v\n", turn, mover.name, int(m)+1)
+			g.show()
+			continue
+		}
+
+		cmd, ok := g.prompt(ctx, fmt.Sprintf("%v to move. Enter a column (1-%v), u to undo, or q to quit: ", turn,
+			board.COLS))
+		switch {
+		case !ok:
+			return ctx.Err()
+		case cmd == "q" || cmd == "quit":
+			return nil
+		case cmd == "u" || cmd == "undo":
+			g.undo()
+		default:
+			col, err := strconv.Atoi(cmd)
+			if err != nil || col < 1 || col > board.COLS {
+				fmt.Fprintf(g.out, "Unknown command %q\n", cmd)
+				continue
+			}
+			if _, _, err := g.board.Move(col - 1); err != nil {
+				if errors.As(err, new(board.FullColumnError)) {
+					fmt.Fprintf(g.out, "Column %v is full\n", col)
+					continue
+				}
+				return err
+			}
+			g.show()
+		}
+	}
+}
+
+// prompt reads a command from the input, reporting false if the input has ended or the context is done
+func (g *game) prompt(ctx context.Context, text string) (string, bool) {
+	fmt.Fprint(g.out, text)
+	select {
+	case line, ok := <-g.in:
+		if !ok {
+			fmt.Fprintln(g.out)
+			return "", false
+		}
+		return strings.ToLower(line), true
+	case <-ctx.Done():
+		fmt.Fprintln(g.out)
+		return "", false
+	}
+}
+
+// undo takes back moves until it's a human's turn again, such that undoing against a computer player takes back the
+// computer's reply along with the human's move
+func (g *game) undo() {
+	if len(g.board.History()) == 0 {
+		fmt.Fprintln(g.out, "There are no moves to undo")
+		return
+	}
+	for {
+		if err := g.board.Unmove(); err != nil {
+			break
+		}
+		next := board.RED
+		if len(g.board.History())%2 == 1 {
+			next = board.BLUE
+		}
+		if g.players[next].strategy == nil {
+			break
+		}
+	}
+	g.show()
+}
+
+// hasHuman returns whether either player is a human
+func (g *game) hasHuman() bool {
+	return g.players[board.RED].strategy == nil || g.players[board.BLUE].strategy == nil
+}
+
+// announce reports the result of a finished game
+func (g *game) announce(p bitboard.Position) {
+	if w := p.Winner(); w != board.NONE {
+		fmt.Fprintf(g.out, "%v (%v) wins after %v moves\n", w, g.players[w].name, p.Moves())
+	} else {
+		fmt.Fprintf(g.out, "Draw after %v moves\n", p.Moves())
+	}
+}
+
+// show renders the board, with the column numbers to enter beneath it
+func (g *game) show() {
+	fmt.Fprintln(g.out)
+	fmt.Fprintln(g.out, g.board.State())
+	var labels strings.Builder
+	for col := 1; col <= board.COLS; col++ {
+		fmt.Fprintf(&labels, "   %v  ", col)
+	}
+	fmt.Fprintln(g.out, strings.TrimRight(labels.String(), " "))
+	if h := g.board.History(); len(h) > 0 {
+		fmt.Fprintf(g.out, "Moves: %v\n", h.Notation())
+	}
+	fmt.Fprintln(g.out)
+}
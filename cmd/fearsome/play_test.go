@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlay(t *testing.T) {
+	table := []struct {
+		name  string
+		args  []string
+		input string
+		want  []string
+	}{
+		{"humans", []string{"-blue", "human"}, "1\n2\n1\n2\n1\n2\n1\nq\n",
+			[]string{"Moves: 1212121", "RED (human) wins after 7 moves", "Game over."}},
+		{"undo", []string{"-blue", "human"}, "4\nu\n3\n", []string{"Moves: 4\n", "Moves: 3\n"}},
+		{"undo on an empty board", []string{"-blue", "human"}, "u\n", []string{"There are no moves to undo"}},
+		{"bad input", []string{"-blue", "human"}, "8\nfour\n", []string{`Unknown command "8"`,
+			`Unknown command "four"`}},
+		{"full column", []string{"-blue", "human"}, "1\n1\n1\n1\n1\n1\n1\n", []string{"Column 1 is full"}},
+		{"computer", []string{"-blue", "greedy", "-seed", "1"}, "4\n", []string{"BLÜ (greedy) plays column"}},
+		{"computers", []string{"-red", "greedy", "-blue", "random", "-seed", "1"}, "", []string{"RED (greedy) plays",
+			"BLÜ (random) plays"}},
+	}
+
+	for _, r := range table {
+		code, out, errs := runCommand(r.input, append([]string{"play"}, r.args...)...)
+		if code != 0 {
+			t.Errorf("%v: expected exit code 0, observed %v with error output:\n%v", r.name, code, errs)
+			continue
+		}
+		for _, w := range r.want {
+			if !strings.Contains(out, w) {
+				t.Errorf("%v: expected output to contain %q, observed:\n%v", r.name, w, out)
+			}
+		}
+	}
+}
+
+// TestPlay_undoComputer asserts that undoing against a computer player takes back its reply as well
+func TestPlay_undoComputer(t *testing.T) {
+	code, out, _ := runCommand("4\nu\nq\n", "play", "-blue", "greedy", "-seed", "1")
+	if code != 0 {
+		t.Fatalf("expected exit code 0, observed %v", code)
+	}
+	last := out[strings.LastIndex(out, "   1     2"):]
+	if strings.Contains(last, "Moves:") || strings.Contains(last, "RED |") {
+		t.Errorf("expected the board to return to empty, observed:\n%v", out)
+	}
+}
+
+func TestPlay_badFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"play", "-red", "oracle"},
+		{"play", "extra"},
+	} {
+		if code, _, _ := runCommand("", args...); code != 2 {
+			t.Errorf("%v: expected exit code 2, observed %v", args, code)
+		}
+	}
+}
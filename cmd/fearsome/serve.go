@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/talglobus/fearsome/server"
+)
+
+func init() {
+	commands["serve"] = command{
+		summary: "Serve position analysis, solves and simulations over HTTP as a JSON API and a web page",
+		run:     serve,
+	}
+}
+
+func serve(ctx context.Context, args []string, s streams) error {
+	var c server.Config
+	fs := newFlagSet("serve", "", s)
+	addr := fs.String("addr", "localhost:8080", "address to listen on, as host:port")
+	fs.DurationVar(&c.Timeout, "timeout", server.DefaultTimeout, "time allowed to each request")
+	fs.IntVar(&c.Concurrency, "concurrency", 0, "number of solves and simulations running at once, or the number "+
+		"of CPUs if 0")
+	memory := fs.Int("memory", server.DefaultMemory>>20, "total size of the transposition tables kept, shared "+
+		"by the most recently used board sizes, in MiB")
+	fs.IntVar(&c.MaxGames, "max-games", server.DefaultMaxGames, "greatest number of games per simulation")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return usageError{fmt.Errorf("unexpected arguments %q", fs.Args())}
+	}
+	c.Memory = *memory << 20
+
+	sv, err := server.New(c)
+	if err != nil {
+		fs.Usage()
+		return usageError{err}
+	}
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+
+	// Requests are bounded by the API's own timeout, so the connection timeouts only guard against slow clients.
+	// Responses have no write timeout, as spectators' event streams last as long as the games they watch
+	hs := &http.Server{
+		Handler:           sv,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       time.Minute,
+		IdleTimeout:       2 * time.Minute,
+	}
+	hs.RegisterOnShutdown(sv.Hub().Close)
+	done := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		done <- hs.Shutdown(shutdown)
+	}()
+
+	fmt.Fprintf(s.out, "Listening on http://%v\n", l.Addr())
+	if err := hs.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return <-done
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestServe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r, w := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- serve(ctx, []string{"-addr", "127.0.0.1:0"}, streams{in: strings.NewReader(""), out: w,
+			err: ioutil.Discard})
+		w.Close()
+	}()
+
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil {
+		t.Fatalf("cannot read address: %v", err)
+	}
+	url := strings.TrimPrefix(strings.TrimSpace(line), "Listening on ")
+	go io.Copy(ioutil.Discard, r)
+
+	resp, err := http.Post(url+"/api/validate", "application/json", strings.NewReader(`{"board":{"moves":"44"}}`))
+	if err != nil {
+		t.Fatalf("cannot reach server: %v", err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `"turn":"red"`) {
+		t.Errorf("unexpected response %v: %s", resp.Status, body)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expected a clean shutdown, observed %v", err)
+	}
+}
+
+func TestServe_usage(t *testing.T) {
+	table := [][]string{
+		{"-concurrency", "-1"},
+		{"-timeout", "-1s"},
+		{"extra"},
+	}
+	for _, args := range table {
+		if code, _, _ := runCommand("", append([]string{"serve"}, args...)...); code != 2 {
+			t.Errorf("%v: expected exit code 2, observed %v", args, code)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/record"
+	"github.com/talglobus/fearsome/simulate"
+	"github.com/talglobus/fearsome/spectate"
+	"github.com/talglobus/fearsome/stats"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+func init() {
+	commands["simulate"] = command{
+		summary: "Play games between two strategies, and report how often each side wins",
+		run:     runSimulate,
+	}
+}
+
+// simulationRate is the JSON encoding of an estimated proportion
+type simulationRate struct {
+	Count    int     `json:"count"`
+	Estimate float64 `json:"estimate"`
+	Low      float64 `json:"low"`
+	High     float64 `json:"high"`
+}
+
+// simulationJSON is the JSON encoding of a simulation report
+type simulationJSON struct {
+	Red         string            `json:"red"`
+	Blue        string            `json:"blue"`
+	Geometry    bitboard.Geometry `json:"geometry"`
+	Start       board.History     `json:"start,omitempty"`
+	Seed        int64             `json:"seed"`
+	Games       int               `json:"games"`
+	Confidence  float64           `json:"confidence"`
+	RedWins     simulationRate    `json:"red_wins"`
+	BlueWins    simulationRate    `json:"blue_wins"`
+	Draws       simulationRate    `json:"draws"`
+	MeanLength  float64           `json:"mean_length"`
+	StdevLength float64           `json:"stdev_length"`
+	Histories   []record.Record   `json:"histories,omitempty"`
+}
+
+func runSimulate(ctx context.Context, args []string, s streams) error {
+	var c simulate.Config
+	fs := newFlagSet("simulate", "", s)
+	fs.IntVar(&c.Games, "games", 1000, "number of games to play")
+	red := fs.String("red", "greedy", "strategy of RED, who moves first, such as random, greedy, lookahead:N, "+
+		"perfect, or mix:EPSILON:STRATEGY")
+	blue := fs.String("blue", "random", "strategy of BLUE, as for -red")
+	fs.Int64Var(&c.Seed, "seed", 1, "master seed, from which the seed of every game is derived")
+	fs.IntVar(&c.Workers, "workers", 0, "number of games played at once, or the number of CPUs if 0")
+	fs.IntVar(&c.Geometry.Height, "rows", board.ROWS, "number of rows of the board")
+	fs.IntVar(&c.Geometry.Width, "cols", board.COLS, "number of columns of the board")
+	start := fs.String("start", "", "moves leading to the starting position, in notation such as 4453")
+	fs.Float64Var(&c.Confidence, "confidence", 0.95, "confidence level of the reported intervals")
+	format := fs.String("format", "table", "output format: table, json, csv, or records, writing the record of "+
+		"every game as newline-delimited JSON")
+	histories := fs.Bool("histories", false, "include the moves of every game in the output")
+	path := fs.String("o", "", "file to write the output to, instead of standard output")
+	watch := fs.String("watch", "", "address on which to stream games live to spectators as Server-Sent Events, "+
+		"as host:port, or none if empty")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return usageError{fmt.Errorf("unexpected arguments %v", fs.Args())}
+	}
+
+	var write func(io.Writer, simulate.Report, bool) error
+	switch *format {
+	case "table":
+		write = writeSimulationTable
+	case "json":
+		write = writeSimulationJSON
+	case "csv":
+		write = writeSimulationCSV
+	case "records":
+		write = writeSimulationRecords
+	default:
+		fs.Usage()
+		return usageError{fmt.Errorf("unknown format %q", *format)}
+	}
+	if err := c.Geometry.Validate(); err != nil {
+		fs.Usage()
+		return usageError{err}
+	}
+	var err error
+	if c.Start, err = board.ParseHistory(*start); err != nil {
+		fs.Usage()
+		return usageError{err}
+	}
+	for _, p := range []struct {
+		name string
+		s    *strategy.Strategy
+	}{{*red, &c.Red}, {*blue, &c.Blue}} {
+		if *p.s, err = strategy.Parse(p.name); err != nil {
+			fs.Usage()
+			return usageError{err}
+		}
+	}
+
+	var hub *spectate.Hub
+	var mutex sync.Mutex
+	var started []string // Identifiers of the games published, such that any left unfinished can be ended
+	if *watch != "" {
+		hub = spectate.NewHub(0)
+		stop, err := spectateOn(*watch, hub, s)
+		if err != nil {
+			return err
+		}
+		defer stop()
+		redName, blueName := strategy.Name(c.Red), strategy.Name(c.Blue)
+		c.Moved = func(g simulate.Game, p bitboard.Position) {
+			r := record.Record{Event: "simulate", Index: g.Index, Red: redName, Blue: blueName,
+				Geometry: p.Geometry(), Start: c.Start, Moves: g.Moves, Result: record.ResultOf(p), Seed: g.Seed}
+			if len(g.Moves) == 0 {
+				mutex.Lock()
+				started = append(started, spectate.ID(r))
+				mutex.Unlock()
+			}
+			hub.Publish(r)
+		}
+	}
+
+	r, err := simulate.Run(ctx, c)
+	if err != nil {
+		// Games cut short never publish a result, so are ended for their spectators
+		for _, id := range started {
+			hub.End(id)
+		}
+		return err
+	}
+
+	w, closeOutput, err := output(*path, s)
+	if err != nil {
+		return err
+	}
+	if err := write(w, r, *histories); err != nil {
+		closeOutput()
+		return err
+	}
+	return closeOutput()
+}
+
+// simulationRecords returns the record of every game of a simulation
+func simulationRecords(r simulate.Report) []record.Record {
+	records := make([]record.Record, len(r.Games))
+	for i, g := range r.Games {
+		records[i] = record.Record{Event: "simulate", Index: g.Index, Red: strategy.Name(r.Config.Red),
+			Blue: strategy.Name(r.Config.Blue), Geometry: r.Config.Geometry, Start: r.Config.Start, Moves: g.Moves,
+			Result: record.Finished(g.Winner), Seed: g.Seed}
+	}
+	return records
+}
+
+// writeSimulationTable writes the human-readable summary of a simulation, followed by the moves of every game if
+// requested
+func writeSimulationTable(w io.Writer, r simulate.Report, histories bool) error {
+	if _, err := fmt.Fprintf(w, "%v vs %v on %v, seed %v\n%v\n", strategy.Name(r.Config.Red),
+		strategy.Name(r.Config.Blue), r.Config.Geometry, r.Config.Seed, r); err != nil {
+		return fmt.Errorf("cannot write simulation: %w", err)
+	}
+	if !histories {
+		return nil
+	}
+	if _, err := fmt.Fprintln(w, "\nGame  Result  Moves"); err != nil {
+		return fmt.Errorf("cannot write simulation: %w", err)
+	}
+	for _, rec := range simulationRecords(r) {
+		if _, err := fmt.Fprintf(w, "%4v  %-6v  %v\n", rec.Index, rec.Result, rec.Moves.Notation()); err != nil {
+			return fmt.Errorf("cannot write simulation: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeSimulationJSON writes a simulation as a single JSON object, including the record of every game if requested
+func writeSimulationJSON(w io.Writer, r simulate.Report, histories bool) error {
+	rate := func(count int, i stats.Interval) simulationRate {
+		return simulationRate{Count: count, Estimate: i.Estimate, Low: i.Low, High: i.High}
+	}
+	out := simulationJSON{
+		Red:         strategy.Name(r.Config.Red),
+		Blue:        strategy.Name(r.Config.Blue),
+		Geometry:    r.Config.Geometry,
+		Start:       r.Config.Start,
+		Seed:        r.Config.Seed,
+		Games:       len(r.Games),
+		Confidence:  r.Config.Confidence,
+		RedWins:     rate(r.Red, r.RedRate),
+		BlueWins:    rate(r.Blue, r.BlueRate),
+		Draws:       rate(r.Draws, r.DrawRate),
+		MeanLength:  r.MeanLength,
+		StdevLength: r.StdevLength,
+	}
+	if histories {
+		out.Histories = simulationRecords(r)
+	}
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	if err := e.Encode(out); err != nil {
+		return fmt.Errorf("cannot write simulation: %w", err)
+	}
+	return nil
+}
+
+// writeSimulationCSV writes a simulation as CSV, with a row per result, or if requested, a row per game
+func writeSimulationCSV(w io.Writer, r simulate.Report, histories bool) error {
+	cw := csv.NewWriter(w)
+	if histories {
+		cw.Write([]string{"index", "seed", "result", "length", "moves"})
+		for _, rec := range simulationRecords(r) {
+			cw.Write([]string{strconv.Itoa(rec.Index), strconv.FormatInt(rec.Seed, 10), rec.Result.String(),
+				strconv.Itoa(len(rec.Moves)), rec.Moves.Notation()})
+		}
+	} else {
+		format := func(f float64) string { return strconv.FormatFloat(f, 'f', 6, 64) }
+		cw.Write([]string{"result", "count", "proportion", "low", "high"})
+		for _, row := range []struct {
+			name  string
+			count int
+			rate  stats.Interval
+		}{{"red", r.Red, r.RedRate}, {"blue", r.Blue, r.BlueRate}, {"draw", r.Draws, r.DrawRate}} {
+			cw.Write([]string{row.name, strconv.Itoa(row.count), format(row.rate.Estimate), format(row.rate.Low),
+				format(row.rate.High)})
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("cannot write simulation: %w", err)
+	}
+	return nil
+}
+
+// writeSimulationRecords writes the record of every game of a simulation as newline-delimited JSON, for the game
+// pipeline commands
+func writeSimulationRecords(w io.Writer, r simulate.Report, _ bool) error {
+	if err := record.WriteAll(w, simulationRecords(r)); err != nil {
+		return fmt.Errorf("cannot write simulation: %w", err)
+	}
+	return nil
+}
+
+// spectateOn serves a hub's events to spectators on a TCP address until stopped, reporting the address on standard
+// error
+func spectateOn(addr string, hub *spectate.Hub, s streams) (stop func(), err error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot serve spectators: %w", err)
+	}
+	hs := &http.Server{Handler: hub, ReadHeaderTimeout: 10 * time.Second}
+	hs.RegisterOnShutdown(hub.Close)
+	go hs.Serve(l)
+	fmt.Fprintf(s.err, "Streaming games live at http://%v/\n", l.Addr())
+
+	// Shutting down closes the hub, ending every stream once it has sent the events already published
+	return func() {
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		hs.Shutdown(shutdown)
+	}, nil
+}
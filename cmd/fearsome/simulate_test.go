@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/talglobus/fearsome/record"
+)
+
+func TestSimulate(t *testing.T) {
+	table := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"table", []string{"-games", "20"}, []string{"greedy vs random on 7x6, seed 1", "20 games (95% confidence)"}},
+		{"table histories", []string{"-games", "3", "-histories"}, []string{"Game  Result  Moves", "   2  "}},
+		{"csv", []string{"-games", "20", "-format", "csv"}, []string{"result,count,proportion,low,high\nred,",
+			"\ndraw,"}},
+		{"csv histories", []string{"-games", "3", "-format", "csv", "-histories"}, []string{
+			"index,seed,result,length,moves\n0,", "\n2,"}},
+		{"geometry", []string{"-games", "5", "-cols", "4", "-rows", "4", "-red", "perfect", "-blue", "perfect"},
+			[]string{"on 4x4", "Draws:          5"}},
+	}
+
+	for _, r := range table {
+		code, out, errs := runCommand("", append([]string{"simulate"}, r.args...)...)
+		if code != 0 {
+			t.Errorf("%v: expected exit code 0, observed %v with error output:\n%v", r.name, code, errs)
+			continue
+		}
+		for _, w := range r.want {
+			if !strings.Contains(out, w) {
+				t.Errorf("%v: expected output to contain %q, observed:\n%v", r.name, w, out)
+			}
+		}
+	}
+}
+
+func TestSimulate_json(t *testing.T) {
+	code, out, errs := runCommand("", "simulate", "-games", "10", "-seed", "3", "-start", "44", "-format", "json",
+		"-histories")
+	if code != 0 {
+		t.Fatalf("expected exit code 0, observed %v with error output:\n%v", code, errs)
+	}
+
+	var got simulationJSON
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("cannot decode output: %v\n%v", err, out)
+	}
+	if got.Games != 10 || got.RedWins.Count+got.BlueWins.Count+got.Draws.Count != 10 || len(got.Histories) != 10 {
+		t.Errorf("unexpected report %+v", got)
+	}
+	for _, rec := range got.Histories {
+		if err := rec.Validate(); err != nil || rec.Start.Notation() != "44" {
+			t.Errorf("unexpected record %+v: %v", rec, err)
+		}
+	}
+
+	// The same seed produces the same games
+	if _, again, _ := runCommand("", "simulate", "-games", "10", "-seed", "3", "-start", "44", "-format", "json",
+		"-histories"); again != out {
+		t.Errorf("expected identical output from the same seed")
+	}
+}
+
+func TestSimulate_records(t *testing.T) {
+	code, out, errs := runCommand("", "simulate", "-games", "7", "-format", "records", "-workers", "2")
+	if code != 0 {
+		t.Fatalf("expected exit code 0, observed %v with error output:\n%v", code, errs)
+	}
+	records, err := record.ReadAll(bytes.NewBufferString(out))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 7 || records[6].Index != 6 || records[0].Red != "greedy" {
+		t.Errorf("unexpected records %+v", records)
+	}
+}
+
+func TestSimulate_badFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"simulate", "-format", "xml"},
+		{"simulate", "-red", "oracle"},
+		{"simulate", "-cols", "9", "-rows", "9"},
+		{"simulate", "-start", "4!"},
+		{"simulate", "extra"},
+	} {
+		if code, _, _ := runCommand("", args...); code != 2 {
+			t.Errorf("%v: expected exit code 2, observed %v", args, code)
+		}
+	}
+	if code, _, _ := runCommand("", "simulate", "-start", "1212121"); code != 1 {
+		t.Errorf("expected exit code 1 simulating from a finished game, observed %v", code)
+	}
+}
+
+func TestSimulate_watch(t *testing.T) {
+	code, out, errs := runCommand("", "simulate", "-games", "5", "-watch", "127.0.0.1:0")
+	if code != 0 || !strings.Contains(errs, "Streaming games live at http://127.0.0.1:") ||
+		!strings.Contains(out, "5 games") {
+		t.Errorf("expected 5 games streamed to spectators, observed exit code %v with output:\n%v%v", code, out, errs)
+	}
+	if code, _, _ := runCommand("", "simulate", "-games", "5", "-watch", "127.0.0.1:-1"); code != 1 {
+		t.Errorf("expected exit code 1 streaming to a bad address, observed %v", code)
+	}
+}
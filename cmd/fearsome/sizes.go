@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/survey"
+)
+
+func init() {
+	commands["sizes"] = command{
+		summary: "Solve the empty board of every size in a range, and tabulate the results",
+		run:     sizes,
+	}
+}
+
+func sizes(ctx context.Context, args []string, s streams) error {
+	c := survey.Config{
+		Min: bitboard.Geometry{Width: 4, Height: 4},
+		Max: bitboard.Geometry{Width: 8, Height: 7},
+	}
+	fs := newFlagSet("sizes", "", s)
+	fs.Var(geometryFlag{&c.Min}, "min", "smallest board size, as columns x rows")
+	fs.Var(geometryFlag{&c.Max}, "max", "largest board size, as columns x rows")
+	fs.DurationVar(&c.Budget, "budget", time.Minute, "time allowed per board size, or unlimited if 0")
+	fs.IntVar(&c.Workers, "workers", 1, "number of parallel search workers per board size")
+	memory := fs.Int("memory", 64, "transposition table size per board size, in MiB")
+	format := fs.String("format", "markdown", "output format, either markdown or csv")
+	path := fs.String("o", "", "file to write the table to, instead of standard output")
+	quiet := fs.Bool("q", false, "don't report progress on standard error")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return usageError{fmt.Errorf("unexpected arguments %v", fs.Args())}
+	}
+
+	write := survey.WriteMarkdown
+	switch *format {
+	case "markdown":
+	case "csv":
+		write = survey.WriteCSV
+	default:
+		fs.Usage()
+		return usageError{fmt.Errorf("unknown format %q", *format)}
+	}
+
+	c.Memory = *memory << 20
+	if !*quiet {
+		c.Progress = func(r survey.Result) {
+			fmt.Fprintf(s.err, "%v: %v (%v nodes in %v)\n", r.Geometry, r.Status, r.Nodes,
+				r.Elapsed.Round(time.Millisecond))
+		}
+	}
+
+	results, err := survey.Run(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	w, closeOutput, err := output(*path, s)
+	if err != nil {
+		return err
+	}
+	if err := write(w, results); err != nil {
+		closeOutput()
+		return err
+	}
+	return closeOutput()
+}
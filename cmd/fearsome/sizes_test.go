@@ -0,0 +1,35 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSizes(t *testing.T) {
+	code, out, errs := runCommand("", "sizes", "-min", "4x4", "-max", "5x4", "-format", "csv", "-memory", "1")
+	if code != 0 {
+		t.Fatalf("expected exit code 0, observed %v with error output:\n%v", code, errs)
+	}
+
+	want := []string{"cols,rows", "4,4,solved,draw,0,16,", "5,4,solved,draw,0,20,"}
+	for _, w := range want {
+		if !strings.Contains(out, w) {
+			t.Errorf("expected output to contain %q, observed:\n%v", w, out)
+		}
+	}
+	if !strings.Contains(errs, "4x4: solved") {
+		t.Errorf("expected progress on error output, observed:\n%v", errs)
+	}
+}
+
+func TestSizes_badFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"sizes", "-format", "xml"},
+		{"sizes", "-min", "9x9"},
+		{"sizes", "extra"},
+	} {
+		if code, _, _ := runCommand("", args...); code != 2 {
+			t.Errorf("%v: expected exit code 2, observed %v", args, code)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/solver"
+)
+
+func init() {
+	commands["solve"] = command{
+		summary: "Solve a position, given as moves or as a grid, reporting its value and how to play it",
+		run:     solve,
+	}
+}
+
+// solution is the result of solving a position, encoded as JSON for machine-readable output. Columns count from one,
+// as in move notation
+type solution struct {
+	Moves     string       `json:"moves"`            // Moves reaching the position, in notation
+	Turn      string       `json:"turn,omitempty"`   // Player to move, if the game isn't over
+	Score     solver.Score `json:"score"`            // Score from the perspective of the player to move
+	Result    string       `json:"result"`           // "win", "loss", or "draw", for the player to move
+	Winner    string       `json:"winner,omitempty"` // Winner under optimal play, if not a draw
+	Distance  int          `json:"distance"`         // Moves remaining under optimal play
+	Optimal   []int        `json:"optimal"`          // Columns of every optimal move
+	Scores    map[int]int  `json:"scores"`           // Score of every playable column
+	Variation string       `json:"variation"`        // Principal variation, in notation
+	Nodes     uint64       `json:"nodes"`
+	Seconds   float64      `json:"seconds"`
+	position  bitboard.Position
+}
+
+func solve(ctx context.Context, args []string, s streams) error {
+	fs := newFlagSet("solve", "<moves | grid | ->", s)
+	timeout := fs.Duration("timeout", 0, "time allowed to solve the position, or unlimited if 0")
+	memory := fs.Int("memory", 64, "transposition table size, in MiB")
+	workers := fs.Int("workers", 1, "number of parallel search workers")
+	limit := fs.Int("pv", 0, "greatest number of moves of the principal variation to show, or every move if 0")
+	format := fs.String("format", "text", "output format, either text or json")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return usageError{errors.New("expected a single position: moves such as 4453, a grid of squares with " +
+			"rows separated by slashes, or - to read either from standard input")}
+	}
+	if *format != "text" && *format != "json" {
+		fs.Usage()
+		return usageError{fmt.Errorf("unknown format %q", *format)}
+	}
+
+	text := fs.Arg(0)
+	if text == "-" {
+		data, err := ioutil.ReadAll(s.in)
+		if err != nil {
+			return fmt.Errorf("cannot read position: %w", err)
+		}
+		text = string(data)
+	}
+	b, err := parseBoard(text)
+	if err != nil {
+		return err
+	}
+
+	t, err := solver.NewTable(bitboard.Standard, *memory<<20, solver.TwoTier)
+	if err != nil {
+		return err
+	}
+	sv, err := solver.New(bitboard.Standard, solver.Config{Workers: *workers, Table: t})
+	if err != nil {
+		return err
+	}
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	sol, err := solveBoard(ctx, sv, b, *limit)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("the solver didn't finish within %v", *timeout)
+	} else if err != nil {
+		return err
+	}
+
+	if *format == "json" {
+		e := json.NewEncoder(s.out)
+		e.SetIndent("", "  ")
+		return e.Encode(sol)
+	}
+	return sol.write(s.out, b)
+}
+
+// parseBoard builds a board from moves in notation, or from a grid of squares as accepted by board.ParseState, in
+// which case the moves are reconstructed by bitboard.HistoryOf. Either way, the board is guaranteed to be reachable
+// in play
+func parseBoard(text string) (board.Board, error) {
+	h, err := board.ParseHistory(strings.TrimSpace(text))
+	if err != nil {
+		state, stateErr := board.ParseState(text)
+		if stateErr != nil {
+			if strings.ContainsAny(text, "\n/|") {
+				return board.Board{}, stateErr
+			}
+			return board.Board{}, err
+		}
+		p, err := bitboard.FromState(state)
+		if err != nil {
+			return board.Board{}, err
+		}
+		if h, err = bitboard.HistoryOf(p); err != nil {
+			return board.Board{}, err
+		}
+	}
+	return newBoard(h)
+}
+
+// newBoard builds the board reached by a sequence of moves, which must be legal and mustn't continue past the end of
+// the game
+func newBoard(h board.History) (board.Board, error) {
+	if _, err := bitboard.FromHistory(bitboard.Standard, h); err != nil {
+		return board.Board{}, fmt.Errorf("cannot play moves %q: %w", h.Notation(), err)
+	}
+	b := board.New()
+	for _, m := range h {
+		if _, _, err := b.Move(int(m)); err != nil {
+			return board.Board{}, err
+		}
+	}
+	return b, nil
+}
+
+// solveBoard solves a board, finding its optimal moves and a principal variation of at most limit moves if positive
+func solveBoard(ctx context.Context, sv *solver.Solver, b board.Board, limit int) (solution, error) {
+	start := time.Now()
+	p, err := bitboard.FromBoard(b)
+	if err != nil {
+		return solution{}, err
+	}
+
+	sol := solution{Moves: b.History().Notation(), Optimal: []int{}, Scores: map[int]int{}, position: p}
+	if p.IsOver() {
+		if sol.Score, err = sv.Solve(ctx, p); err != nil {
+			return sol, err
+		}
+	} else {
+		sol.Turn = p.Turn().String()
+		scores, ok, err := sv.Analyze(ctx, p)
+		if err != nil {
+			return sol, err
+		}
+		var moves []board.Move
+		sol.Score, moves = solver.OptimalMoves(p.Geometry(), scores, ok)
+		for _, m := range moves {
+			sol.Optimal = append(sol.Optimal, int(m)+1)
+		}
+		sort.Ints(sol.Optimal)
+		for col := range scores {
+			if ok[col] {
+				sol.Scores[col+1] = int(scores[col])
+			}
+		}
+	}
+
+	line, err := sv.Variation(ctx, p, limit)
+	if err != nil {
+		return sol, err
+	}
+	sol.Variation = line.Notation()
+
+	switch {
+	case sol.Score > 0:
+		sol.Result = "win"
+	case sol.Score < 0:
+		sol.Result = "loss"
+	default:
+		sol.Result = "draw"
+	}
+	if w := sol.Score.Winner(p); w != board.NONE {
+		sol.Winner = w.String()
+	}
+	sol.Distance = sol.Score.Plies(p)
+	sol.Nodes, sol.Seconds = sv.Nodes(), time.Since(start).Seconds()
+	return sol, nil
+}
+
+// write describes the solution in a human-readable format
+func (sol solution) write(w io.Writer, b board.Board) error {
+	var sb strings.Builder
+	fmt.Fprintln(&sb, b.State())
+	p := sol.position
+	moves := sol.Moves
+	if moves == "" {
+		moves = "none"
+	}
+	fmt.Fprintf(&sb, "Moves:     %v\n", moves)
+
+	switch {
+	case p.Winner() != board.NONE:
+		fmt.Fprintf(&sb, "Result:    %v has won\n", p.Winner())
+	case p.IsFull():
+		fmt.Fprintln(&sb, "Result:    drawn")
+	default:
+		fmt.Fprintf(&sb, "To move:   %v\n", p.Turn())
+		fmt.Fprintf(&sb, "Value:     %+d, %v\n", sol.Score, outcome(sol.Score, p))
+
+		optimal := make([]string, len(sol.Optimal))
+		for i, col := range sol.Optimal {
+			optimal[i] = fmt.Sprint(col)
+		}
+		fmt.Fprintf(&sb, "Optimal:   %v\n", strings.Join(optimal, " "))
+
+		var scores []string
+		for col := 1; col <= board.COLS; col++ {
+			if score, ok := sol.Scores[col]; ok {
+				scores = append(scores, fmt.Sprintf("%v:%+d", col, score))
+			}
+		}
+		fmt.Fprintf(&sb, "Scores:    %v\n", strings.Join(scores, " "))
+		fmt.Fprintf(&sb, "Variation: %v\n", sol.Variation)
+	}
+	fmt.Fprintf(&sb, "Searched:  %v nodes in %v\n", sol.Nodes,
+		time.Duration(sol.Seconds*float64(time.Second)).Round(time.Millisecond))
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("cannot write solution: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// endgameGrid is the endgame position, drawn as a grid
+const endgameGrid = `
+.BBB...
+.RRR...
+.BBB...
+.RRR...
+.BBB...
+.RRR...`
+
+func TestSolve(t *testing.T) {
+	table := []struct {
+		name  string
+		args  []string
+		input string
+		want  []string
+	}{
+		{"moves", []string{endgame}, "", []string{"Value:     +12, RED wins with the next move", "Optimal:   1 5\n",
+			"Scores:    1:+12 5:+12 6:+11 7:+11\n", "Variation: 5\n"}},
+		{"grid", []string{strings.Replace(strings.TrimSpace(endgameGrid), "\n", "/", -1)}, "",
+			[]string{"To move:   RED", "Optimal:   1 5\n"}},
+		{"standard input", []string{"-"}, endgameGrid, []string{"Optimal:   1 5\n"}},
+		{"finished", []string{"1212121"}, "", []string{"Result:    RED has won"}},
+		{"variation", []string{"-pv", "3", "44444433333322222"}, "", []string{"Variation: "}},
+	}
+
+	for _, r := range table {
+		code, out, errs := runCommand(r.input, append([]string{"solve"}, r.args...)...)
+		if code != 0 {
+			t.Errorf("%v: expected exit code 0, observed %v with error output:\n%v", r.name, code, errs)
+			continue
+		}
+		for _, w := range r.want {
+			if !strings.Contains(out, w) {
+				t.Errorf("%v: expected output to contain %q, observed:\n%v", r.name, w, out)
+			}
+		}
+	}
+}
+
+func TestSolve_json(t *testing.T) {
+	// BLUE to move must block in column 1 or 5, but can't block both, losing on RED's next move
+	code, out, errs := runCommand("", "solve", "-format", "json", "44444433333322222")
+	if code != 0 {
+		t.Fatalf("expected exit code 0, observed %v with error output:\n%v", code, errs)
+	}
+
+	var got solution
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("cannot decode output: %v\n%v", err, out)
+	}
+	if got.Turn != "BLÜ" || got.Result != "loss" || got.Winner != "RED" || got.Distance != len(got.Variation) {
+		t.Errorf("unexpected solution %+v", got)
+	}
+	for _, col := range got.Optimal {
+		if got.Scores[col] != int(got.Score) {
+			t.Errorf("optimal column %v scores %v rather than %v", col, got.Scores[col], got.Score)
+		}
+	}
+}
+
+func TestSolve_errors(t *testing.T) {
+	for _, r := range []struct {
+		args []string
+		code int
+	}{
+		{[]string{"solve"}, 2},
+		{[]string{"solve", "-format", "xml", "44"}, 2},
+		{[]string{"solve", "4!"}, 1},
+		{[]string{"solve", "12121212"}, 1},
+		{[]string{"solve", "......./......./......./......./BBBB.../RRRR..."}, 1},
+		{[]string{"solve", "-timeout", "1ms", ""}, 1},
+	} {
+		if code, _, _ := runCommand("", r.args...); code != r.code {
+			t.Errorf("%v: expected exit code %v, observed %v", r.args, r.code, code)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/solver"
+	"github.com/talglobus/fearsome/tablebase"
+)
+
+func init() {
+	commands["tablebase"] = command{
+		summary: "Generate an endgame tablebase and save it to a file, or verify a saved one, against search",
+		run:     generateTablebase,
+	}
+}
+
+func generateTablebase(ctx context.Context, args []string, s streams) error {
+	g := bitboard.Standard
+	fs := newFlagSet("tablebase", "", s)
+	fs.Var(geometryFlag{&g}, "geometry", "board size, as columns x rows")
+	moves := fs.String("moves", "", "moves from the empty board to the root of the tablebase, such as 4453")
+	empty := fs.Int("empty", 8, "greatest number of empty squares of the positions in the tablebase")
+	memory := fs.Int("memory", tablebase.DefaultMemory>>20, "memory the positions held during generation may occupy, "+
+		"in MiB")
+	path := fs.String("o", "", "file to save the tablebase to, which is required unless verifying with -i")
+	input := fs.String("i", "", "saved tablebase to verify, instead of generating one")
+	samples := fs.Int("verify", 100, "number of randomly sampled positions to check against search, if any")
+	seed := fs.Int64("seed", 1, "seed of the random source sampling positions to verify")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	switch {
+	case fs.NArg() > 0:
+		fs.Usage()
+		return usageError{fmt.Errorf("unexpected arguments %v", fs.Args())}
+	case *input == "" && *path == "":
+		fs.Usage()
+		return usageError{fmt.Errorf("no file given to save the tablebase to")}
+	case *input != "" && *path != "":
+		fs.Usage()
+		return usageError{fmt.Errorf("cannot both verify a tablebase and save one")}
+	}
+
+	var tb *tablebase.Tablebase
+	if *input != "" {
+		var err error
+		if tb, err = tablebase.Load(*input); err != nil {
+			return err
+		}
+	} else {
+		h, err := board.ParseHistory(*moves)
+		if err != nil {
+			fs.Usage()
+			return usageError{err}
+		}
+		root, err := bitboard.FromHistory(g, h)
+		if err != nil {
+			return err
+		}
+		if tb, err = tablebase.Generate(ctx, root, tablebase.Config{MaxEmpty: *empty, Memory: *memory << 20}); err != nil {
+			return err
+		}
+		if err := tb.Save(*path); err != nil {
+			return err
+		}
+		fmt.Fprintf(s.out, "Saved %v positions of %v with at most %v empty squares to %v\n", tb.Len(), tb.Geometry(),
+			tb.MaxEmpty(), *path)
+	}
+
+	if *samples > 0 {
+		sv, err := solver.New(tb.Geometry(), solver.Config{})
+		if err != nil {
+			return err
+		}
+		if err := tb.Verify(ctx, sv, rand.New(rand.NewSource(*seed)), *samples); err != nil {
+			return err
+		}
+		fmt.Fprintf(s.out, "Verified %v sampled positions against search\n", *samples)
+	}
+	return nil
+}
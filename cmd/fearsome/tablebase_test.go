@@ -0,0 +1,48 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTablebase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tablebase")
+	code, out, errs := runCommand("", "tablebase", "-geometry", "4x4", "-empty", "16", "-verify", "20", "-o", path)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, observed %v with error output:\n%v", code, errs)
+	}
+	if !strings.Contains(out, "of 4x4 with at most 16 empty squares") || !strings.Contains(out, "Verified 20") {
+		t.Errorf("expected a summary of a complete 4x4 tablebase, observed:\n%v", out)
+	}
+
+	code, out, errs = runCommand("", "tablebase", "-i", path, "-verify", "20")
+	if code != 0 || strings.Contains(out, "Saved") || !strings.Contains(out, "Verified 20") {
+		t.Errorf("expected the saved tablebase to verify, observed exit code %v:\n%v\n%v", code, out, errs)
+	}
+
+	code, out, errs = runCommand("", "tablebase", "-moves", "3161766312223613447336722621471547", "-empty", "6",
+		"-verify", "0", "-o", path)
+	if code != 0 || !strings.Contains(out, "of 7x6 with at most 6 empty squares") {
+		t.Errorf("expected a 7x6 tablebase below the root, observed exit code %v:\n%v\n%v", code, out, errs)
+	}
+}
+
+func TestTablebase_badFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"tablebase"},
+		{"tablebase", "-o", "tablebase", "extra"},
+		{"tablebase", "-o", "tablebase", "-i", "tablebase"},
+		{"tablebase", "-moves", "4!", "-o", "tablebase"},
+	} {
+		if code, _, _ := runCommand("", args...); code != 2 {
+			t.Errorf("%v: expected exit code 2, observed %v", args, code)
+		}
+	}
+
+	// Rooted at the empty 7x6 board, the region doesn't fit in memory
+	code, _, errs := runCommand("", "tablebase", "-empty", "4", "-memory", "1", "-o", filepath.Join(t.TempDir(), "tb"))
+	if code != 1 || !strings.Contains(errs, "too many positions") {
+		t.Errorf("expected the tablebase to be rejected, observed exit code %v:\n%v", code, errs)
+	}
+}
@@ -0,0 +1,310 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+// StopGrace sets how long an engine is given to reply with a move once told to stop, after which it's considered
+// unresponsive
+const StopGrace = time.Second
+
+// DeadlineMargin sets how much of the time until a context's deadline is withheld from an engine allowed that time,
+// such that its move arrives before the deadline
+const DeadlineMargin = 50 * time.Millisecond
+
+// ExitGrace sets how long an engine process is given to exit once told to quit, after which it's killed
+const ExitGrace = 5 * time.Second
+
+// Driver plays moves chosen by an external engine, speaking the protocol over a pair of streams, such as the
+// standard input and output of a process started with Start. Driver is a strategy.Strategy, and so may play in
+// simulations, tournaments, and interactive games. As an engine considers a single position at a time, concurrent
+// calls to Choose take turns.
+//
+// Before every move, the engine is sent a seed drawn from the strategy's random source, such that engines honoring
+// it play reproducibly. A Driver whose engine breaks the protocol, or fails to stop when told to, is unusable
+// thereafter, and every later move fails with the same error
+type Driver struct {
+	Name, Author string // Name and author as identified by the engine, which may be empty
+
+	// MoveTime sets the time allowed to the engine for each move. If zero, the engine is allowed the time remaining
+	// until the context's deadline, less DeadlineMargin, if any, and otherwise chooses for itself how long to search
+	MoveTime time.Duration
+	// Info, if not nil, is called with the report of every info line the engine sends, excepting lines that can't be
+	// parsed, which are skipped
+	Info func(Info)
+
+	mutex   sync.Mutex
+	w       io.Writer
+	lines   <-chan line
+	done    chan struct{} // Closed by Close, releasing the goroutine reading lines
+	failure error
+	close   func() error
+}
+
+// line holds a line read from an engine, or the error ending its output
+type line struct {
+	text string
+	err  error
+}
+
+// NewDriver introduces itself to the engine reading r and writing w, waiting until the engine identifies itself or
+// the context is done
+func NewDriver(ctx context.Context, r io.Reader, w io.Writer) (*Driver, error) {
+	lines, done := make(chan line), make(chan struct{})
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			select {
+			case lines <- line{text: scanner.Text()}:
+			case <-done:
+				return
+			}
+		}
+		err := scanner.Err()
+		if err == nil {
+			err = io.ErrUnexpectedEOF
+		}
+		select {
+		case lines <- line{err: fmt.Errorf("engine output ended: %w", err)}:
+		case <-done:
+		}
+	}()
+
+	d := &Driver{w: w, lines: lines, done: done, close: func() error { return nil }}
+	if err := d.introduce(ctx); err != nil {
+		close(done)
+		return nil, fmt.Errorf("cannot introduce engine: %w", err)
+	}
+	return d, nil
+}
+
+// introduce sends hello, reading the engine's identity from its reply
+func (d *Driver) introduce(ctx context.Context) error {
+	if err := d.send("hello"); err != nil {
+		return err
+	}
+	for {
+		text, err := d.next(ctx)
+		if err != nil {
+			return err
+		}
+		switch fields := strings.Fields(text); {
+		case len(fields) > 2 && fields[0] == "id" && fields[1] == "name":
+			d.Name = strings.Join(fields[2:], " ")
+		case len(fields) > 2 && fields[0] == "id" && fields[1] == "author":
+			d.Author = strings.Join(fields[2:], " ")
+		case len(fields) == 1 && fields[0] == "hellook":
+			return nil
+		}
+	}
+}
+
+// Start starts an engine process and introduces itself to it, waiting until the engine identifies itself or the
+// context is done. The process outlives the context, and runs until the Driver is closed. Its standard error is
+// discarded
+func Start(ctx context.Context, name string, args ...string) (*Driver, error) {
+	cmd := exec.Command(name, args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("cannot start engine: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("cannot start engine: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("cannot start engine: %w", err)
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	stop := func() error {
+		stdin.Close()
+		select {
+		case err := <-exited:
+			return err
+		case <-time.After(ExitGrace):
+			cmd.Process.Kill()
+			return fmt.Errorf("engine %v didn't exit within %v, and was killed", name, ExitGrace)
+		}
+	}
+
+	d, err := NewDriver(ctx, stdout, stdin)
+	if err != nil {
+		cmd.Process.Kill()
+		<-exited
+		return nil, err
+	}
+	d.close = stop
+	return d, nil
+}
+
+// Close tells the engine to quit, waiting for its process to exit if started with Start
+func (d *Driver) Close() error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.done == nil {
+		return nil
+	}
+	d.send("quit")
+	close(d.done)
+	d.done = nil
+	if d.failure == nil {
+		d.failure = errors.New("engine is closed")
+	}
+	return d.close()
+}
+
+// send writes a command to the engine
+func (d *Driver) send(command string) error {
+	if _, err := io.WriteString(d.w, command+"\n"); err != nil {
+		return fmt.Errorf("cannot write to engine: %w", err)
+	}
+	return nil
+}
+
+// next returns the next line the engine sends, or an error if its output ends or the context is done first
+func (d *Driver) next(ctx context.Context) (string, error) {
+	select {
+	case l, ok := <-d.lines:
+		if !ok {
+			return "", errors.New("engine output ended")
+		}
+		return l.text, l.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Choose asks the engine for a move in the position, reached by the first sequence of moves found by
+// bitboard.HistoryOf
+func (d *Driver) Choose(ctx context.Context, p bitboard.Position, rng *rand.Rand) (board.Move, error) {
+	h, err := bitboard.HistoryOf(p)
+	if err != nil {
+		return 0, fmt.Errorf("cannot choose move: %w", err)
+	}
+	return d.choose(ctx, p, h, rng)
+}
+
+// Move asks the engine for a move in the board, reached by the board's own history
+func (d *Driver) Move(ctx context.Context, b board.Board, rng *rand.Rand) (board.Move, error) {
+	p, err := bitboard.FromBoard(b)
+	if err != nil {
+		return 0, fmt.Errorf("cannot choose move: %w", err)
+	}
+	return d.choose(ctx, p, b.History(), rng)
+}
+
+// choose asks the engine for a move in the position reached by the given moves
+func (d *Driver) choose(ctx context.Context, p bitboard.Position, h board.History, rng *rand.Rand) (board.Move,
+	error) {
+	if p.IsOver() {
+		return 0, fmt.Errorf("cannot choose move: %w", bitboard.GameOverError{})
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.failure != nil {
+		return 0, fmt.Errorf("cannot choose move: %w", d.failure)
+	}
+	m, err := d.search(ctx, p, h, rng)
+	if err != nil {
+		// A search ended by the caller still returns the move the engine replied to stop with, so any error leaves the
+		// engine out of sync
+		d.failure = err
+		return 0, fmt.Errorf("cannot choose move: %w", err)
+	}
+	return m, nil
+}
+
+// search runs a single search, returning the engine's move
+func (d *Driver) search(ctx context.Context, p bitboard.Position, h board.History, rng *rand.Rand) (board.Move,
+	error) {
+	command := "go"
+	limit := d.MoveTime
+	if deadline, ok := ctx.Deadline(); ok && limit == 0 {
+		limit = time.Until(deadline) - DeadlineMargin
+		if limit < time.Millisecond {
+			limit = time.Millisecond
+		}
+	}
+	if limit > 0 {
+		command = fmt.Sprintf("go movetime %v", limit.Milliseconds())
+	}
+	for _, c := range []string{
+		fmt.Sprintf("seed %v", rng.Int63()),
+		position(p.Geometry(), h),
+		command,
+	} {
+		if err := d.send(c); err != nil {
+			return 0, err
+		}
+	}
+
+	// Once the context is done, the engine is told to stop, and given a little longer to reply with its move, which is
+	// played as any other
+	waiting, stopped := ctx, false
+	for {
+		text, err := d.next(waiting)
+		if err != nil && !stopped && ctx.Err() != nil {
+			if err := d.send("stop"); err != nil {
+				return 0, err
+			}
+			var cancel context.CancelFunc
+			waiting, cancel = context.WithTimeout(context.Background(), StopGrace)
+			defer cancel()
+			stopped = true
+			continue
+		} else if err != nil && stopped {
+			return 0, fmt.Errorf("engine didn't reply to stop within %v: %w", StopGrace, err)
+		} else if err != nil {
+			return 0, err
+		}
+
+		switch strings.SplitN(text, " ", 2)[0] {
+		case "info":
+			// Info is only ever reported, never acted on, so a line that can't be parsed, as from an engine extending
+			// the protocol, is skipped rather than failing the search
+			if i, err := parseInfo(text); err == nil && d.Info != nil {
+				d.Info(i)
+			}
+		case "bestmove":
+			m, err := parseMove(text)
+			if err != nil {
+				return 0, err
+			}
+			if !p.CanPlay(int(m)) {
+				return 0, fmt.Errorf("engine chose an illegal move: %w", IllegalMoveError(m))
+			}
+			return m, nil
+		}
+	}
+}
+
+// position returns the command setting the position reached by the given moves
+func position(g bitboard.Geometry, h board.History) string {
+	if len(h) == 0 {
+		return fmt.Sprintf("position geometry %v", g)
+	}
+	return fmt.Sprintf("position geometry %v moves %v", g, h.Notation())
+}
+
+func (d *Driver) String() string {
+	if d.Name == "" {
+		return "engine"
+	}
+	return d.Name
+}
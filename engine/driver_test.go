@@ -0,0 +1,229 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/simulate"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+// engineVariable names the environment variable that makes the test binary run as a greedy engine, for Start
+const engineVariable = "FEARSOME_TEST_ENGINE"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(engineVariable) != "" {
+		wr := &Wrapper{Name: "test engine", Engine: StrategyEngine{strategy.Greedy{}}}
+		if err := wr.Serve(context.Background(), os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// connect returns a driver of a wrapper of the given engine, served in another goroutine
+func connect(t *testing.T, e Engine) *Driver {
+	t.Helper()
+	commands, toEngine := io.Pipe()
+	replies, fromEngine := io.Pipe()
+	served := make(chan error, 1)
+	go func() {
+		served <- (&Wrapper{Engine: e}).Serve(context.Background(), commands, fromEngine)
+		fromEngine.Close()
+	}()
+
+	d, err := NewDriver(context.Background(), replies, toEngine)
+	if err != nil {
+		t.Fatalf("cannot connect to engine: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := d.Close(); err != nil {
+			t.Errorf("cannot close driver: %v", err)
+		}
+		toEngine.Close()
+		if err := <-served; err != nil {
+			t.Errorf("cannot serve engine: %v", err)
+		}
+	})
+	return d
+}
+
+// scripted runs a fake engine, which introduces itself and then replies to every go with the given line
+func scripted(t *testing.T, reply string) *Driver {
+	t.Helper()
+	commands, toEngine := io.Pipe()
+	replies, fromEngine := io.Pipe()
+	go func() {
+		defer fromEngine.Close()
+		scanner := bufio.NewScanner(commands)
+		for scanner.Scan() {
+			switch scanner.Text() {
+			case "hello":
+				fmt.Fprintln(fromEngine, "id name fake\nhellook")
+			case "go":
+				fmt.Fprintln(fromEngine, reply)
+			}
+		}
+	}()
+
+	d, err := NewDriver(context.Background(), replies, toEngine)
+	if err != nil {
+		t.Fatalf("cannot connect to engine: %v", err)
+	}
+	t.Cleanup(func() {
+		d.Close()
+		toEngine.Close()
+	})
+	return d
+}
+
+func TestDriver(t *testing.T) {
+	d := connect(t, StrategyEngine{strategy.Lookahead{Depth: 2}})
+	if d.Name != "lookahead:2" || d.String() != "lookahead:2" {
+		t.Errorf("expected engine to be named lookahead:2, observed %q", d.Name)
+	}
+
+	// Drivers take turns at a single engine, so may play many games at once, reproducibly
+	c := simulate.Config{Games: 6, Red: d, Blue: strategy.Random{}, Seed: 4, Workers: 3}
+	a, err := simulate.Run(context.Background(), c)
+	if err != nil {
+		t.Fatalf("cannot simulate: %v", err)
+	}
+	b, err := simulate.Run(context.Background(), c)
+	if err != nil {
+		t.Fatalf("cannot simulate: %v", err)
+	}
+	for i := range a.Games {
+		if a.Games[i].Moves.Notation() != b.Games[i].Moves.Notation() {
+			t.Errorf("game %v: expected identical moves, observed %q and %q", i, a.Games[i].Moves.Notation(),
+				b.Games[i].Moves.Notation())
+		}
+	}
+}
+
+func TestDriver_Move(t *testing.T) {
+	d := connect(t, &SolverEngine{})
+	var infos []Info
+	d.Info = func(i Info) { infos = append(infos, i) }
+
+	h, _ := board.ParseHistory(endgame)
+	b := board.New()
+	for _, m := range h {
+		b.Move(int(m))
+	}
+	m, err := d.Move(context.Background(), b, rand.New(rand.NewSource(1)))
+	if err != nil || m != 4 {
+		t.Errorf("expected column 5, observed %v: %v", int(m)+1, err)
+	}
+	if len(infos) == 0 || !infos[0].Scored || infos[0].Score != 12 || infos[0].PV.Notation() != "5" {
+		t.Errorf("expected a score of 12 with a variation of 5, observed %+v", infos)
+	}
+
+	p, _ := bitboard.FromHistory(bitboard.Standard, board.History{0, 1, 0, 1, 0, 1, 0})
+	if _, err := d.Choose(context.Background(), p, rand.New(rand.NewSource(1))); !errors.As(err,
+		&bitboard.GameOverError{}) {
+		t.Errorf("expected GameOverError, observed %v", err)
+	}
+}
+
+func TestDriver_stop(t *testing.T) {
+	d := connect(t, stubborn{})
+	rng := rand.New(rand.NewSource(1))
+
+	// Allow the engine longer than the deadline, so that it doesn't stop itself, and plays the move it replies with
+	d.MoveTime = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	empty := bitboard.New(bitboard.Standard)
+	if m, err := d.Choose(ctx, empty, rng); err != nil || !empty.CanPlay(int(m)) {
+		t.Errorf("expected the move replied to stop, observed %v: %v", int(m)+1, err)
+	}
+
+	// The engine replied to stop, so remains in sync
+	p, _ := bitboard.FromHistory(bitboard.Standard, board.History{0, 1, 0, 1, 0, 1})
+	if m, err := d.Choose(context.Background(), p, rng); err != nil || m != 0 {
+		t.Errorf("expected column 1, observed %v: %v", int(m)+1, err)
+	}
+
+	// Allowed the time until the deadline, the engine stops itself in time for its move to arrive before the deadline
+	d.MoveTime = 0
+	ctx, cancel = context.WithTimeout(context.Background(), 2*DeadlineMargin)
+	defer cancel()
+	if m, err := d.Choose(ctx, empty, rng); err != nil || !empty.CanPlay(int(m)) || ctx.Err() != nil {
+		t.Errorf("expected a move before the deadline, observed %v: %v (%v)", int(m)+1, err, ctx.Err())
+	}
+}
+
+func TestDriver_errors(t *testing.T) {
+	table := []struct {
+		name, reply string
+		check       func(error) bool
+	}{
+		{"illegal move", "bestmove 9", func(err error) bool { return errors.As(err, new(IllegalMoveError)) }},
+		{"no move", "bestmove", func(err error) bool { return errors.As(err, &ProtocolError{}) }},
+	}
+
+	for _, r := range table {
+		d := scripted(t, r.reply)
+		p, rng := bitboard.New(bitboard.Standard), rand.New(rand.NewSource(1))
+		if _, err := d.Choose(context.Background(), p, rng); !r.check(err) {
+			t.Errorf("%v: unexpected error %v", r.name, err)
+		}
+		// An engine breaking the protocol can't be trusted to be in sync, so is never asked again
+		if _, err := d.Choose(context.Background(), p, rng); !r.check(err) {
+			t.Errorf("%v: expected the same error again, observed %v", r.name, err)
+		}
+	}
+}
+
+// TestDriver_badInfo checks that info lines that can't be parsed are skipped, rather than failing the search
+func TestDriver_badInfo(t *testing.T) {
+	d := scripted(t, "info score mate3\ninfo depth\ninfo depth 3 score 2\nbestmove 4")
+	var infos []Info
+	d.Info = func(i Info) { infos = append(infos, i) }
+
+	p, rng := bitboard.New(bitboard.Standard), rand.New(rand.NewSource(1))
+	for i := 0; i < 2; i++ {
+		if m, err := d.Choose(context.Background(), p, rng); err != nil || m != 3 {
+			t.Errorf("expected column 4, observed %v: %v", int(m)+1, err)
+		}
+	}
+	if len(infos) != 2 || infos[0].Depth != 3 || infos[0].Score != 2 {
+		t.Errorf("expected only the well-formed info to be reported, observed %+v", infos)
+	}
+}
+
+func TestStart(t *testing.T) {
+	os.Setenv(engineVariable, "1")
+	defer os.Unsetenv(engineVariable)
+
+	d, err := Start(context.Background(), os.Args[0])
+	if err != nil {
+		t.Fatalf("cannot start engine: %v", err)
+	}
+	if d.Name != "test engine" {
+		t.Errorf("expected engine to be named %q, observed %q", "test engine", d.Name)
+	}
+	p, _ := bitboard.FromHistory(bitboard.Standard, board.History{0, 1, 0, 1, 0, 1})
+	if m, err := d.Choose(context.Background(), p, rand.New(rand.NewSource(1))); err != nil || m != 0 {
+		t.Errorf("expected column 1, observed %v: %v", int(m)+1, err)
+	}
+	if err := d.Close(); err != nil {
+		t.Errorf("cannot close engine: %v", err)
+	}
+
+	if _, err := Start(context.Background(), "/nonexistent/engine"); err == nil {
+		t.Error("expected an error starting a missing engine")
+	}
+}
@@ -0,0 +1,20 @@
+package engine
+
+import "fmt"
+
+// ProtocolError defines an error used when an engine sends a line that breaks the protocol
+type ProtocolError struct {
+	Line, Reason string
+}
+
+func (e ProtocolError) Error() string {
+	return fmt.Sprintf("engine sent %q: %v", e.Line, e.Reason)
+}
+
+// IllegalMoveError defines an error used when an engine chooses a move that can't be played, counting columns from
+// zero as everywhere outside of notation
+type IllegalMoveError int
+
+func (e IllegalMoveError) Error() string {
+	return fmt.Sprintf("column %v cannot be played", int(e)+1)
+}
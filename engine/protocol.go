@@ -0,0 +1,126 @@
+// Package engine implements a line-based text protocol through which programs choose moves, in the manner of UCI for
+// chess, such that engines written in any language can play through the library, and the library's own engines can
+// play elsewhere. The library drives an external engine with a Driver, which is a strategy.Strategy, and exposes its
+// own engines with a Wrapper.
+//
+// The driver sends the engine one command per line:
+//
+//	hello                                   asks the engine to identify itself
+//	isready                                 asks the engine to reply once it has processed every earlier command
+//	newgame                                 tells the engine that the next position belongs to a different game
+//	seed <n>                                seeds the engine's random choices, for the sake of reproducibility
+//	position [geometry 7x6] [moves 4453]    sets the position, by its board size and the moves reaching it
+//	go [movetime <ms>]                      asks the engine to choose a move, within the given time if any
+//	stop                                    asks the engine to choose a move at once
+//	quit                                    asks the engine to exit
+//
+// The engine replies with one message per line:
+//
+//	id name <name>                          identifies the engine, in reply to hello, as may id author <author>
+//	hellook                                 ends the reply to hello
+//	readyok                                 replies to isready
+//	info [depth <d>] [score <s>] [nodes <n>] [time <ms>] [pv <moves>] [string <text>]
+//	                                        reports on a search underway, at any time before bestmove
+//	bestmove <move>                         ends the search started by go, with the chosen move, or none if the
+//	                                        game is over
+//
+// Moves are written in move notation, in which columns count from one, as in board.ParseHistory. Scores are those of
+// package solver, from the perspective of the player to move. Both sides ignore lines they don't understand, such
+// that either may extend the protocol, although an engine may report them as info strings.
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/solver"
+)
+
+// Info holds the report of a search, as sent in an info line. Fields left unreported are zero
+type Info struct {
+	Depth  int
+	Scored bool // Whether the score was reported, as a score of zero is a draw
+	Score  solver.Score
+	Nodes  uint64
+	Time   time.Duration
+	PV     board.History // Principal variation
+	String string        // Free text, which extends to the end of the line
+}
+
+// format returns the info line reporting the info
+func (i Info) format() string {
+	fields := []string{"info"}
+	if i.Depth > 0 {
+		fields = append(fields, "depth", strconv.Itoa(i.Depth))
+	}
+	if i.Scored {
+		fields = append(fields, "score", strconv.Itoa(int(i.Score)))
+	}
+	if i.Nodes > 0 {
+		fields = append(fields, "nodes", strconv.FormatUint(i.Nodes, 10))
+	}
+	if i.Time > 0 {
+		fields = append(fields, "time", strconv.FormatInt(i.Time.Milliseconds(), 10))
+	}
+	if len(i.PV) > 0 {
+		fields = append(fields, "pv", i.PV.Notation())
+	}
+	if i.String != "" {
+		fields = append(fields, "string", i.String)
+	}
+	return strings.Join(fields, " ")
+}
+
+// parseInfo parses an info line, ignoring fields it doesn't know along with their values
+func parseInfo(line string) (Info, error) {
+	var i Info
+	fields := strings.Fields(line)
+	for n := 1; n < len(fields); n += 2 {
+		if fields[n] == "string" {
+			i.String = strings.Join(fields[n+1:], " ")
+			break
+		}
+		if n+1 == len(fields) {
+			return i, ProtocolError{Line: line, Reason: fmt.Sprintf("%v has no value", fields[n])}
+		}
+
+		var err error
+		value := fields[n+1]
+		switch fields[n] {
+		case "depth":
+			i.Depth, err = strconv.Atoi(value)
+		case "score":
+			var s int
+			s, err = strconv.Atoi(value)
+			i.Score, i.Scored = solver.Score(s), true
+		case "nodes":
+			i.Nodes, err = strconv.ParseUint(value, 10, 64)
+		case "time":
+			var ms int64
+			ms, err = strconv.ParseInt(value, 10, 64)
+			i.Time = time.Duration(ms) * time.Millisecond
+		case "pv":
+			i.PV, err = board.ParseHistory(value)
+		}
+		if err != nil {
+			return i, ProtocolError{Line: line, Reason: fmt.Sprintf("cannot parse %v: %v", fields[n], err)}
+		}
+	}
+	return i, nil
+}
+
+// parseMove parses the move of a bestmove line
+func parseMove(line string) (board.Move, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return 0, ProtocolError{Line: line, Reason: "expected a move"}
+	}
+	h, err := board.ParseHistory(fields[1])
+	if err != nil || len(h) != 1 {
+		return 0, ProtocolError{Line: line, Reason: "expected a single move in notation"}
+	}
+	return h[0], nil
+}
@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/talglobus/fearsome/board"
+)
+
+func TestInfo(t *testing.T) {
+	table := []struct {
+		name string
+		info Info
+		line string
+	}{
+		{"empty", Info{}, "info"},
+		{"score", Info{Scored: true, Score: -3, Nodes: 1200, Time: 15 * time.Millisecond},
+			"info score -3 nodes 1200 time 15"},
+		{"draw", Info{Scored: true}, "info score 0"},
+		{"depth and variation", Info{Depth: 8, PV: board.History{3, 3, 4}}, "info depth 8 pv 445"},
+		{"string", Info{Depth: 2, String: "search stopped early"}, "info depth 2 string search stopped early"},
+	}
+
+	for _, r := range table {
+		if line := r.info.format(); line != r.line {
+			t.Errorf("%v: expected line %q, observed %q", r.name, r.line, line)
+		}
+		info, err := parseInfo(r.line)
+		if err != nil {
+			t.Errorf("%v: cannot parse %q: %v", r.name, r.line, err)
+			continue
+		}
+		if info.format() != r.line {
+			t.Errorf("%v: expected %+v, observed %+v", r.name, r.info, info)
+		}
+	}
+}
+
+func TestParseInfo(t *testing.T) {
+	info, err := parseInfo("info hashfull 300 score 4 currmove 3")
+	if err != nil || !info.Scored || info.Score != 4 {
+		t.Errorf("expected unknown fields to be ignored, observed %+v: %v", info, err)
+	}
+
+	for _, line := range []string{"info score", "info score high", "info pv 4!", "info nodes -1"} {
+		if _, err := parseInfo(line); !errors.As(err, &ProtocolError{}) {
+			t.Errorf("%q: expected ProtocolError, observed %v", line, err)
+		}
+	}
+}
+
+func TestParseMove(t *testing.T) {
+	table := []struct {
+		line string
+		move int
+		ok   bool
+	}{
+		{"bestmove 4", 3, true},
+		{"bestmove a", 9, true},
+		{"bestmove 4 ponder 5", 3, true},
+		{"bestmove", 0, false},
+		{"bestmove none", 0, false},
+		{"bestmove 44", 0, false},
+	}
+
+	for _, r := range table {
+		m, err := parseMove(r.line)
+		if r.ok && (err != nil || int(m) != r.move) {
+			t.Errorf("%q: expected move %v, observed %v: %v", r.line, r.move, m, err)
+		} else if !r.ok && !errors.As(err, &ProtocolError{}) {
+			t.Errorf("%q: expected ProtocolError, observed %v", r.line, err)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+// Engine searches for moves on behalf of a Wrapper
+type Engine interface {
+	// Search returns a move for the player to move in an unfinished position, drawing any randomness from rng, and
+	// reporting on its progress through info, which may be called any number of times
+	Search(ctx context.Context, p bitboard.Position, rng *rand.Rand, info func(Info)) (board.Move, error)
+}
+
+// StrategyEngine adapts a strategy to an Engine, reporting nothing of its search
+type StrategyEngine struct {
+	Strategy strategy.Strategy
+}
+
+// Search returns the strategy's choice of move
+func (e StrategyEngine) Search(ctx context.Context, p bitboard.Position, rng *rand.Rand, _ func(Info)) (board.Move,
+	error) {
+	return e.Strategy.Choose(ctx, p, rng)
+}
+
+func (e StrategyEngine) String() string {
+	return strategy.Name(e.Strategy)
+}
+
+// SolverEngine plays optimally, as strategy.Perfect does, breaking ties in favor of central columns, and reports the
+// score and principal variation of every position it solves
+type SolverEngine struct {
+	// Perfect configures the solvers, constructed lazily, one per geometry, or provided by its Solvers hook. Ties are
+	// broken in favor of central columns whether or not it's Deterministic
+	Perfect strategy.Perfect
+}
+
+// Search returns the most central optimal move, reporting the score of the position and the line of optimal play
+// following the move
+func (e *SolverEngine) Search(ctx context.Context, p bitboard.Position, _ *rand.Rand, info func(Info)) (board.Move,
+	error) {
+	start := time.Now()
+	sv, err := e.Perfect.Solver(p.Geometry())
+	if err != nil {
+		return 0, fmt.Errorf("cannot search: %w", err)
+	}
+	nodes := sv.Nodes()
+	moves, score, err := strategy.Optimal(ctx, sv, p)
+	if err != nil {
+		return 0, fmt.Errorf("cannot search: %w", err)
+	}
+
+	line, err := sv.Variation(ctx, p.Play(int(moves[0])), 0)
+	if err != nil {
+		return 0, fmt.Errorf("cannot search: %w", err)
+	}
+	info(Info{Scored: true, Score: score, Nodes: sv.Nodes() - nodes, Time: time.Since(start),
+		PV: append(board.History{moves[0]}, line...)})
+	return moves[0], nil
+}
+
+func (e *SolverEngine) String() string {
+	return "solver"
+}
+
+// Wrapper exposes an engine through the protocol
+type Wrapper struct {
+	Name   string // Name by which the engine identifies itself, defaulting to the engine's String, if any
+	Author string // Author by which the engine identifies itself, if not empty
+	Engine Engine
+}
+
+// Serve reads commands from r and writes replies to w until it reads quit, r ends, or the context is done. Searches
+// run while further commands are read, such that stop interrupts them. A search interrupted before finding a move
+// plays as strategy.Greedy instead, as every go must be answered with a move. Positions of any geometry are accepted
+func (wr *Wrapper) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s := session{wrapper: wr, w: w, geometry: bitboard.Standard, rng: rand.New(rand.NewSource(0))}
+	s.position = bitboard.New(s.geometry)
+	defer s.stop()
+
+	// The goroutine reading commands is released once Serve returns, whether or not r has ended
+	lines, errs, done := make(chan string), make(chan error, 1), make(chan struct{})
+	defer close(done)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-done:
+				return
+			}
+		}
+		errs <- scanner.Err()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errs:
+			return err
+		case line := <-lines:
+			if quit := s.handle(ctx, line); quit {
+				return nil
+			}
+		}
+		if err := s.err(); err != nil {
+			return err
+		}
+	}
+}
+
+// session holds the state of a single connection to a Wrapper
+type session struct {
+	wrapper  *Wrapper
+	geometry bitboard.Geometry
+	position bitboard.Position
+	rng      *rand.Rand
+
+	mutex     sync.Mutex // Guards w and failure, which searches write to
+	w         io.Writer
+	failure   error
+	cancel    context.CancelFunc
+	searching sync.WaitGroup
+}
+
+// send writes a line, remembering the first failure to write
+func (s *session) send(format string, args ...interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.failure != nil {
+		return
+	}
+	if _, err := fmt.Fprintf(s.w, format+"\n", args...); err != nil {
+		s.failure = fmt.Errorf("cannot write reply: %w", err)
+	}
+}
+
+// err returns the first failure to write, if any
+func (s *session) err() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.failure
+}
+
+// stop interrupts the search underway, if any, waiting for it to send its move
+func (s *session) stop() {
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	s.searching.Wait()
+}
+
+// handle handles a single command, returning whether it was quit
+func (s *session) handle(ctx context.Context, line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false
+	}
+	command, args := fields[0], fields[1:]
+
+	// Commands other than isready change the session's state, so only once the search underway has ended
+	if command != "isready" {
+		s.stop()
+	}
+
+	switch command {
+	case "hello":
+		name := s.wrapper.Name
+		if n, ok := s.wrapper.Engine.(fmt.Stringer); ok && name == "" {
+			name = n.String()
+		}
+		if name != "" {
+			s.send("id name %v", name)
+		}
+		if s.wrapper.Author != "" {
+			s.send("id author %v", s.wrapper.Author)
+		}
+		s.send("hellook")
+	case "isready":
+		s.send("readyok")
+	case "newgame":
+		s.position = bitboard.New(s.geometry)
+	case "seed":
+		seed, err := strconv.ParseInt(strings.Join(args, ""), 10, 64)
+		if err != nil || len(args) != 1 {
+			s.send("info string cannot parse seed %q", strings.Join(args, " "))
+			break
+		}
+		s.rng = rand.New(rand.NewSource(seed))
+	case "position":
+		if err := s.setPosition(args); err != nil {
+			s.send("info string cannot set position: %v", err)
+		}
+	case "go":
+		s.search(ctx, args)
+	case "stop":
+	case "quit":
+		return true
+	default:
+		s.send("info string unknown command %q", command)
+	}
+	return false
+}
+
+// setPosition handles the arguments of a position command
+func (s *session) setPosition(args []string) error {
+	g, h := bitboard.Standard, board.History(nil)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 == len(args) && args[i] == "moves" {
+			break
+		} else if i+1 == len(args) {
+			return fmt.Errorf("%v has no value", args[i])
+		}
+		var err error
+		switch args[i] {
+		case "geometry":
+			g, err = bitboard.ParseGeometry(args[i+1])
+		case "moves":
+			h, err = board.ParseHistory(args[i+1])
+		default:
+			err = fmt.Errorf("unknown argument %q", args[i])
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	p, err := bitboard.FromHistory(g, h)
+	if err != nil {
+		return err
+	}
+	s.geometry, s.position = g, p
+	return nil
+}
+
+// search handles the arguments of a go command, starting a search that runs until it finds a move, the move time
+// runs out, or it's stopped
+func (s *session) search(ctx context.Context, args []string) {
+	var limit time.Duration
+	for i := 0; i+1 < len(args); i += 2 {
+		if args[i] != "movetime" {
+			continue
+		}
+		ms, err := strconv.Atoi(args[i+1])
+		if err != nil || ms <= 0 {
+			s.send("info string cannot parse move time %q", args[i+1])
+			continue
+		}
+		limit = time.Duration(ms) * time.Millisecond
+	}
+	var cancel context.CancelFunc
+	if limit > 0 {
+		ctx, cancel = context.WithTimeout(ctx, limit)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	s.cancel = cancel
+
+	p, rng := s.position, s.rng
+	if p.IsOver() {
+		s.send("info string game is over")
+		s.send("bestmove none")
+		cancel()
+		return
+	}
+
+	s.searching.Add(1)
+	go func() {
+		defer s.searching.Done()
+		defer cancel()
+		start := time.Now()
+		m, err := s.wrapper.Engine.Search(ctx, p, rng, func(i Info) { s.send("%v", i.format()) })
+		if err == nil && !p.CanPlay(int(m)) {
+			err = IllegalMoveError(m)
+		}
+		if err != nil {
+			s.send("info string search failed, playing greedily: %v", err)
+			m, _ = strategy.Greedy{}.Choose(context.Background(), p, rng)
+		}
+		s.send("info time %v", time.Since(start).Milliseconds())
+		s.send("bestmove %v", board.History{m}.Notation())
+	}()
+}
@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+// endgame holds the moves of a position solved quickly, in which RED, to move, wins at once in column 1 or 5
+const endgame = "444444333333222222"
+
+// stubborn searches the empty position until stopped, and otherwise plays greedily
+type stubborn struct{}
+
+func (stubborn) Search(ctx context.Context, p bitboard.Position, rng *rand.Rand, _ func(Info)) (board.Move, error) {
+	if p.Moves() == 0 {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}
+	return strategy.Greedy{}.Choose(ctx, p, rng)
+}
+
+// serve runs a wrapper over a script of commands, returning its replies
+func serve(t *testing.T, wr *Wrapper, script string) string {
+	t.Helper()
+	var out bytes.Buffer
+	if err := wr.Serve(context.Background(), strings.NewReader(script), &out); err != nil {
+		t.Fatalf("cannot serve script %q: %v", script, err)
+	}
+	return out.String()
+}
+
+func TestWrapper(t *testing.T) {
+	greedy := &Wrapper{Engine: StrategyEngine{strategy.Greedy{}}}
+	table := []struct {
+		name    string
+		wrapper *Wrapper
+		script  string
+		want    []string
+	}{
+		{"hello", greedy, "hello\nisready\n", []string{"id name greedy\nhellook\nreadyok\n"}},
+		{"named", &Wrapper{Name: "Deep Four", Author: "someone", Engine: stubborn{}}, "hello\n",
+			[]string{"id name Deep Four\nid author someone\nhellook\n"}},
+		{"winning move", greedy, "position moves 121212\ngo\n", []string{"bestmove 1\n"}},
+		{"geometry", greedy, "position geometry 4x4 moves 121212\ngo movetime 100\n", []string{"bestmove 1\n"}},
+		{"solver", &Wrapper{Engine: &SolverEngine{}}, "position moves " + endgame + "\ngo\n",
+			[]string{"info score 12 nodes ", " pv 5\n", "bestmove 5\n"}},
+		{"game over", greedy, "position moves 1212121\ngo\n", []string{"bestmove none\n"}},
+		{"new game", greedy, "position moves 121212\nnewgame\ngo\n", []string{"bestmove "}},
+		{"stopped", &Wrapper{Engine: stubborn{}}, "go\nstop\n", []string{"info string search failed, playing greedily",
+			"bestmove "}},
+		{"move time", &Wrapper{Engine: stubborn{}}, "go movetime 10\n", []string{"info string search failed",
+			"bestmove "}},
+		{"illegal position", greedy, "position moves 1111111\n", []string{"info string cannot set position"}},
+		{"bad geometry", greedy, "position geometry 9x9\n", []string{"info string cannot set position"}},
+		{"bad seed", greedy, "seed soon\n", []string{"info string cannot parse seed"}},
+		{"unknown command", greedy, "ponderhit\n", []string{`info string unknown command "ponderhit"`}},
+		{"quit", greedy, "quit\nhello\n", nil},
+	}
+
+	for _, r := range table {
+		out := serve(t, r.wrapper, r.script)
+		for _, w := range r.want {
+			if !strings.Contains(out, w) {
+				t.Errorf("%v: expected replies to contain %q, observed:\n%v", r.name, w, out)
+			}
+		}
+		if r.want == nil && out != "" {
+			t.Errorf("%v: expected no replies, observed:\n%v", r.name, out)
+		}
+	}
+}
+
+func TestWrapper_seed(t *testing.T) {
+	wr := &Wrapper{Engine: StrategyEngine{strategy.Random{}}}
+	script := "seed 7\ngo\ngo\ngo\ngo\ngo\n"
+	if a, b := serve(t, wr, script), serve(t, wr, script); stripTimes(a) != stripTimes(b) {
+		t.Errorf("expected identical moves from the same seed, observed:\n%v\nand:\n%v", a, b)
+	}
+}
+
+// stripTimes removes the info lines reporting search times, which vary between runs
+func stripTimes(replies string) string {
+	var lines []string
+	for _, line := range strings.Split(replies, "\n") {
+		if !strings.HasPrefix(line, "info time") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,9 @@
+package exact
+
+// DistributionError defines an error used when a policy gives a distribution that isn't a probability distribution
+// over the playable columns
+type DistributionError string
+
+func (e DistributionError) Error() string {
+	return "invalid distribution: " + string(e)
+}
@@ -0,0 +1,137 @@
+// Package exact computes the exact probabilities of each outcome of a game between two policies, where a policy gives
+// the probability of each move in a position rather than sampling one. Every line of play is enumerated, memoized
+// across transpositions, and the probabilities are carried as rational numbers, so that a question like "how often
+// does perfect play lose to random play" has an exact answer on boards small enough to enumerate.
+package exact
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+// Outcome holds the probability of each result of a game
+type Outcome struct {
+	Red, Blue, Draw *big.Rat
+}
+
+// String formats each probability as an exact fraction followed by its decimal approximation
+func (o Outcome) String() string {
+	return fmt.Sprintf("RED %v (%v), BLUE %v (%v), draw %v (%v)", o.Red.RatString(), o.Red.FloatString(6),
+		o.Blue.RatString(), o.Blue.FloatString(6), o.Draw.RatString(), o.Draw.FloatString(6))
+}
+
+// certain returns the outcome in which the given player wins with probability one, or a draw is certain if NONE
+func certain(winner board.Type) Outcome {
+	o := Outcome{Red: new(big.Rat), Blue: new(big.Rat), Draw: new(big.Rat)}
+	switch winner {
+	case board.RED:
+		o.Red.SetInt64(1)
+	case board.BLUE:
+		o.Blue.SetInt64(1)
+	default:
+		o.Draw.SetInt64(1)
+	}
+	return o
+}
+
+// memoKey identifies a position across geometries, as keys are only unique within a single geometry
+type memoKey struct {
+	geometry bitboard.Geometry
+	key      uint64
+}
+
+// Evaluator computes outcome probabilities for a pair of policies, remembering the outcome of every position it has
+// evaluated, such that successive evaluations share their work. As outcomes are remembered by position, policies must
+// give the same distribution whenever they are asked about the same position. An Evaluator is not safe for concurrent
+// use
+type Evaluator struct {
+	Red, Blue Policy
+
+	memo map[memoKey]Outcome
+}
+
+// New returns an evaluator for games in which RED plays the red policy and BLUE plays the blue policy
+func New(red, blue Policy) *Evaluator {
+	return &Evaluator{Red: red, Blue: blue, memo: map[memoKey]Outcome{}}
+}
+
+// Positions returns the number of distinct unfinished positions evaluated so far
+func (e *Evaluator) Positions() int {
+	return len(e.memo)
+}
+
+// Board returns the probabilities of each outcome of the game continuing from a board
+func (e *Evaluator) Board(ctx context.Context, b board.Board) (Outcome, error) {
+	p, err := bitboard.FromBoard(b)
+	if err != nil {
+		return Outcome{}, fmt.Errorf("cannot evaluate board: %w", err)
+	}
+	return e.Position(ctx, p)
+}
+
+// Position returns the probabilities of each outcome of the game continuing from a position. Positions in which the
+// game has already ended have a certain outcome. Position returns the context's error if the context is done before
+// the evaluation completes
+func (e *Evaluator) Position(ctx context.Context, p bitboard.Position) (Outcome, error) {
+	o, err := e.evaluate(ctx, p)
+	if err != nil {
+		return Outcome{}, err
+	}
+
+	// Copy the outcome, as the memoized outcomes are shared between positions and must not be modified
+	return Outcome{Red: new(big.Rat).Set(o.Red), Blue: new(big.Rat).Set(o.Blue), Draw: new(big.Rat).Set(o.Draw)}, nil
+}
+
+// evaluate returns the memoized outcome of a position, computing it if necessary
+func (e *Evaluator) evaluate(ctx context.Context, p bitboard.Position) (Outcome, error) {
+	if p.IsOver() {
+		return certain(p.Winner()), nil
+	}
+	k := memoKey{p.Geometry(), p.Key()}
+	if o, ok := e.memo[k]; ok {
+		return o, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return Outcome{}, err
+	}
+
+	policy := e.Red
+	if p.Turn() == board.BLUE {
+		policy = e.Blue
+	}
+	d, err := policy.Distribution(ctx, p)
+	if err != nil {
+		return Outcome{}, fmt.Errorf("cannot evaluate position: %w", err)
+	}
+	if err := d.validate(p); err != nil {
+		return Outcome{}, fmt.Errorf("cannot evaluate position: %w", err)
+	}
+
+	o := Outcome{Red: new(big.Rat), Blue: new(big.Rat), Draw: new(big.Rat)}
+	term := new(big.Rat)
+	for _, w := range d {
+		if w.Probability.Sign() == 0 {
+			continue
+		}
+		child, err := e.evaluate(ctx, p.Play(int(w.Move)))
+		if err != nil {
+			return Outcome{}, err
+		}
+		o.Red.Add(o.Red, term.Mul(w.Probability, child.Red))
+		o.Blue.Add(o.Blue, term.Mul(w.Probability, child.Blue))
+		o.Draw.Add(o.Draw, term.Mul(w.Probability, child.Draw))
+	}
+
+	e.memo[k] = o
+	return o, nil
+}
+
+// Evaluate returns the probabilities of each outcome of the game continuing from a position, with RED playing the
+// red policy and BLUE playing the blue policy
+func Evaluate(ctx context.Context, p bitboard.Position, red, blue Policy) (Outcome, error) {
+	return New(red, blue).Position(ctx, p)
+}
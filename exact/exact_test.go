@@ -0,0 +1,223 @@
+package exact
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/simulate"
+	"github.com/talglobus/fearsome/solver"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+// reference computes the probabilities of RED winning, BLUE winning and a draw between uniformly random players by
+// brute force, without memoization
+func reference(p bitboard.Position) [3]float64 {
+	if p.IsOver() {
+		switch p.Winner() {
+		case board.RED:
+			return [3]float64{1, 0, 0}
+		case board.BLUE:
+			return [3]float64{0, 1, 0}
+		}
+		return [3]float64{0, 0, 1}
+	}
+	var o [3]float64
+	moves := p.LegalMoves()
+	for _, m := range moves {
+		child := reference(p.Play(int(m)))
+		for i := range o {
+			o[i] += child[i] / float64(len(moves))
+		}
+	}
+	return o
+}
+
+// sumsToOne fails the test if the probabilities of an outcome don't sum to exactly one
+func sumsToOne(t *testing.T, o Outcome) {
+	t.Helper()
+	sum := new(big.Rat).Add(o.Red, o.Blue)
+	sum.Add(sum, o.Draw)
+	if sum.Cmp(big.NewRat(1, 1)) != 0 {
+		t.Errorf("expected probabilities summing to one, observed %v summing to %v", o, sum.RatString())
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		geometry bitboard.Geometry
+		start    board.History
+	}{
+		{bitboard.Geometry{Width: 3, Height: 4}, nil},
+		{bitboard.Geometry{Width: 4, Height: 3}, nil},
+		{bitboard.Geometry{Width: 4, Height: 4}, board.History{0, 1, 2, 1, 0}},
+		{bitboard.Geometry{Width: 1, Height: 8}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.geometry.String(), func(t *testing.T) {
+			p, err := bitboard.FromHistory(test.geometry, test.start)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			o, err := Evaluate(context.Background(), p, Uniform{}, Uniform{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			sumsToOne(t, o)
+
+			expected := reference(p)
+			for i, r := range []*big.Rat{o.Red, o.Blue, o.Draw} {
+				if f, _ := r.Float64(); math.Abs(f-expected[i]) > 1e-12 {
+					t.Errorf("expected probabilities %v, observed %v", expected, o)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluate_finished(t *testing.T) {
+	g := bitboard.Geometry{Width: 4, Height: 4}
+	p, _ := bitboard.FromHistory(g, board.History{0, 1, 0, 1, 0, 1, 0})
+	o, err := Evaluate(context.Background(), p, Uniform{}, Uniform{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if o.Red.Cmp(big.NewRat(1, 1)) != 0 {
+		t.Errorf("expected a certain RED win, observed %v", o)
+	}
+}
+
+// TestEvaluate_perfect asserts that perfect play never loses a game it can't lose, here as RED on a 4x4 board, which
+// is drawn with perfect play from both sides
+func TestEvaluate_perfect(t *testing.T) {
+	g := bitboard.Geometry{Width: 4, Height: 4}
+	sv, err := solver.New(g, solver.Config{Workers: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	e := New(Perfect{Solver: sv}, Uniform{})
+	o, err := e.Position(context.Background(), bitboard.New(g))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sumsToOne(t, o)
+	if o.Blue.Sign() != 0 || o.Red.Sign() == 0 {
+		t.Errorf("expected perfect play to win sometimes and never lose, observed %v", o)
+	}
+	if e.Positions() == 0 {
+		t.Errorf("expected positions to be evaluated")
+	}
+
+	// Perfect play on both sides can only draw
+	o, err = Evaluate(context.Background(), bitboard.New(g), Perfect{Solver: sv}, Perfect{Solver: sv})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if o.Draw.Cmp(big.NewRat(1, 1)) != 0 {
+		t.Errorf("expected a certain draw, observed %v", o)
+	}
+}
+
+// TestEvaluate_simulate asserts that the exact probabilities of greedy play agree with simulated games of the
+// equivalent strategies
+func TestEvaluate_simulate(t *testing.T) {
+	g := bitboard.Geometry{Width: 4, Height: 4}
+	o, err := Evaluate(context.Background(), bitboard.New(g), Greedy{}, Uniform{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sumsToOne(t, o)
+
+	r, err := simulate.Run(context.Background(), simulate.Config{Games: 2000, Red: strategy.Greedy{},
+		Blue: strategy.Random{}, Geometry: g, Seed: 1, Confidence: 0.999})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, c := range []struct {
+		exact    *big.Rat
+		interval simulate.Interval
+	}{{o.Red, r.RedRate}, {o.Blue, r.BlueRate}, {o.Draw, r.DrawRate}} {
+		if f, _ := c.exact.Float64(); f < c.interval.Low || f > c.interval.High {
+			t.Errorf("exact probability %v should lie within simulated interval %v", f, c.interval)
+		}
+	}
+}
+
+func TestEvaluator_Board(t *testing.T) {
+	// Find a random game that is still underway after 32 moves, leaving few enough positions to enumerate
+	rng := rand.New(rand.NewSource(1))
+	var h board.History
+	for p := bitboard.New(bitboard.Standard); len(h) < 32; {
+		moves := p.LegalMoves()
+		m := moves[rng.Intn(len(moves))]
+		if next := p.Play(int(m)); !next.IsOver() {
+			p, h = next, append(h, m)
+		}
+	}
+
+	b := board.New()
+	for _, m := range h {
+		if _, _, err := b.Move(int(m)); err != nil {
+			t.Fatalf("unexpected error making move: %v", err)
+		}
+	}
+
+	e := New(Uniform{}, Greedy{})
+	o, err := e.Board(context.Background(), b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sumsToOne(t, o)
+
+	p, _ := bitboard.FromHistory(bitboard.Standard, h)
+	o2, err := e.Position(context.Background(), p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if o.Red.Cmp(o2.Red) != 0 || o.Blue.Cmp(o2.Blue) != 0 || o.Draw.Cmp(o2.Draw) != 0 {
+		t.Errorf("expected the board and its position to agree, observed %v and %v", o, o2)
+	}
+
+	// Modifying a returned outcome mustn't affect the memoized outcome
+	o.Red.SetInt64(7)
+	if o3, _ := e.Board(context.Background(), b); o3.Red.Cmp(o2.Red) != 0 {
+		t.Errorf("expected memoized outcome %v to be unaffected, observed %v", o2, o3)
+	}
+}
+
+// lopsided gives a distribution that doesn't sum to one
+type lopsided struct{}
+
+func (lopsided) Distribution(_ context.Context, p bitboard.Position) (Distribution, error) {
+	return Distribution{{Move: p.LegalMoves()[0], Probability: big.NewRat(1, 2)}}, nil
+}
+
+// offBoard plays a column off the board with certainty
+type offBoard struct{}
+
+func (offBoard) Distribution(context.Context, bitboard.Position) (Distribution, error) {
+	return Distribution{{Move: 9, Probability: big.NewRat(1, 1)}}, nil
+}
+
+func TestEvaluate_errors(t *testing.T) {
+	p := bitboard.New(bitboard.Geometry{Width: 4, Height: 4})
+	for _, policy := range []Policy{lopsided{}, offBoard{}} {
+		_, err := Evaluate(context.Background(), p, Uniform{}, policy)
+		if !errors.As(err, new(DistributionError)) {
+			t.Errorf("expected a distribution error from %T, observed %v", policy, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Evaluate(ctx, p, Uniform{}, Uniform{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected cancellation, observed %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package exact
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"math/rand"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/solver"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+// Weighted holds a move along with the probability of it being played
+type Weighted struct {
+	Move        board.Move
+	Probability *big.Rat
+}
+
+// Distribution holds the probability of each move a policy might play in a position. Moves absent from the
+// distribution are never played, and the probabilities must sum to exactly one
+type Distribution []Weighted
+
+// validate checks that the distribution is a probability distribution over the playable columns of a position
+func (d Distribution) validate(p bitboard.Position) error {
+	sum := new(big.Rat)
+	for _, w := range d {
+		switch {
+		case w.Probability == nil || w.Probability.Sign() < 0:
+			return DistributionError(fmt.Sprintf("column %v has probability %v", int(w.Move)+1, w.Probability))
+		case w.Probability.Sign() > 0 && !p.CanPlay(int(w.Move)):
+			return DistributionError(fmt.Sprintf("column %v cannot be played", int(w.Move)+1))
+		}
+		sum.Add(sum, w.Probability)
+	}
+	if sum.Cmp(big.NewRat(1, 1)) != 0 {
+		return DistributionError(fmt.Sprintf("probabilities sum to %v", sum.RatString()))
+	}
+	return nil
+}
+
+// uniform returns the distribution playing each of the moves with equal probability
+func uniform(moves []board.Move) Distribution {
+	d := make(Distribution, len(moves))
+	for i, m := range moves {
+		d[i] = Weighted{Move: m, Probability: big.NewRat(1, int64(len(moves)))}
+	}
+	return d
+}
+
+// Policy gives the probability of each move for the player to move in an unfinished position
+type Policy interface {
+	Distribution(ctx context.Context, p bitboard.Position) (Distribution, error)
+}
+
+// Uniform plays each playable column with equal probability, as strategy.Random does
+type Uniform struct{}
+
+// Distribution returns the uniform distribution over the playable columns
+func (Uniform) Distribution(_ context.Context, p bitboard.Position) (Distribution, error) {
+	return uniform(p.LegalMoves()), nil
+}
+
+func (Uniform) String() string {
+	return "random"
+}
+
+// Greedy plays with the distribution of strategy.Greedy, taking an immediate win, then blocking, then choosing
+// uniformly among the moves that don't hand the opponent an immediate win, where there are any
+type Greedy struct{}
+
+// Distribution returns the distribution of the moves strategy.Greedy might choose
+func (Greedy) Distribution(_ context.Context, p bitboard.Position) (Distribution, error) {
+	moves := p.LegalMoves()
+	for _, m := range moves {
+		if p.IsWinningMove(int(m)) {
+			return uniform([]board.Move{m}), nil
+		}
+	}
+
+	var safe []board.Move
+	nonLosing := p.NonLosingMoves()
+	for _, m := range moves {
+		if nonLosing&p.ColumnMask(int(m)) != 0 {
+			safe = append(safe, m)
+		}
+	}
+	if len(safe) > 0 {
+		moves = safe
+	}
+	return uniform(moves), nil
+}
+
+func (Greedy) String() string {
+	return "greedy"
+}
+
+// Perfect plays with the distribution of a non-deterministic strategy.Perfect, choosing uniformly among the moves
+// achieving the exact score of the position
+type Perfect struct {
+	Solver *solver.Solver
+}
+
+// Distribution returns the uniform distribution over the optimal moves
+func (s Perfect) Distribution(ctx context.Context, p bitboard.Position) (Distribution, error) {
+	moves, _, err := strategy.Optimal(ctx, s.Solver, p)
+	if err != nil {
+		return nil, err
+	}
+	return uniform(moves), nil
+}
+
+func (Perfect) String() string {
+	return "perfect"
+}
+
+// Pure plays whichever move a deterministic strategy chooses, with probability one. The strategy is given a source
+// of randomness with a fixed seed, so a strategy that draws on it plays as though always making the same choice
+type Pure struct {
+	Strategy strategy.Strategy
+}
+
+// Distribution returns the distribution playing the strategy's move with certainty
+func (s Pure) Distribution(ctx context.Context, p bitboard.Position) (Distribution, error) {
+	m, err := s.Strategy.Choose(ctx, p, rand.New(rand.NewSource(0)))
+	if err != nil {
+		return nil, err
+	}
+	return uniform([]board.Move{m}), nil
+}
+
+func (s Pure) String() string {
+	return strategy.Name(s.Strategy)
+}
@@ -0,0 +1,105 @@
+package exact
+
+import (
+	"context"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/solver"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+// support returns the moves a distribution plays with nonzero probability
+func support(d Distribution) map[board.Move]bool {
+	s := map[board.Move]bool{}
+	for _, w := range d {
+		if w.Probability.Sign() > 0 {
+			s[w.Move] = true
+		}
+	}
+	return s
+}
+
+// TestPolicies asserts that each policy is a valid distribution, covering exactly the moves its strategy might choose
+func TestPolicies(t *testing.T) {
+	g := bitboard.Geometry{Width: 5, Height: 4}
+	sv, err := solver.New(g, solver.Config{Workers: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		policy   Policy
+		strategy strategy.Strategy
+	}{
+		{Uniform{}, strategy.Random{}},
+		{Greedy{}, strategy.Greedy{}},
+		{Perfect{Solver: sv}, &strategy.Perfect{Config: solver.Config{Workers: 1}}},
+		{Pure{Strategy: &strategy.Perfect{Deterministic: true}}, &strategy.Perfect{Deterministic: true}},
+	}
+
+	rng := rand.New(rand.NewSource(2))
+	for _, test := range tests {
+		t.Run(strategy.Name(test.strategy), func(t *testing.T) {
+			for _, h := range []board.History{nil, {2, 2, 1}, {0, 1, 0, 1, 0}, {4, 3, 4, 3, 2, 4}} {
+				p, err := bitboard.FromHistory(g, h)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				d, err := test.policy.Distribution(context.Background(), p)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if err := d.validate(p); err != nil {
+					t.Fatalf("moves %v: invalid distribution %v: %v", h, d, err)
+				}
+
+				s := support(d)
+				chosen := map[board.Move]bool{}
+				for i := 0; i < 50; i++ {
+					m, err := test.strategy.Choose(context.Background(), p, rng)
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					chosen[m] = true
+				}
+				if len(chosen) != len(s) {
+					t.Errorf("moves %v: expected the strategy to choose from %v, observed %v", h, s, chosen)
+				}
+				for m := range chosen {
+					if !s[m] {
+						t.Errorf("moves %v: strategy chose %v outside the distribution's support %v", h, m, s)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDistribution_validate(t *testing.T) {
+	p, _ := bitboard.FromHistory(bitboard.Geometry{Width: 2, Height: 2}, board.History{0, 0})
+	tests := []struct {
+		name  string
+		d     Distribution
+		valid bool
+	}{
+		{"certain", Distribution{{1, big.NewRat(1, 1)}}, true},
+		{"unplayable with no probability", Distribution{{0, new(big.Rat)}, {1, big.NewRat(1, 1)}}, true},
+		{"unplayable", Distribution{{0, big.NewRat(1, 1)}}, false},
+		{"short", Distribution{{1, big.NewRat(2, 3)}}, false},
+		{"negative", Distribution{{1, big.NewRat(-1, 1)}, {1, big.NewRat(2, 1)}}, false},
+		{"nil", Distribution{{1, nil}}, false},
+		{"empty", nil, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.d.validate(p); (err == nil) != test.valid {
+				t.Errorf("expected valid %v, observed error %v", test.valid, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package experiment
+
+import "fmt"
+
+// SpecError defines an error used when a spec is invalid
+type SpecError string
+
+func (e SpecError) Error() string {
+	return string(e)
+}
+
+// DirectoryError defines an error used when the results directory already holds files, which a run would overwrite
+type DirectoryError string
+
+func (e DirectoryError) Error() string {
+	return fmt.Sprintf("results directory %v is not empty", string(e))
+}
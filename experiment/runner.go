@@ -0,0 +1,212 @@
+package experiment
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/talglobus/fearsome/record"
+	"github.com/talglobus/fearsome/simulate"
+	"github.com/talglobus/fearsome/stats"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+// File describes a file written by a run, relative to the results directory
+type File struct {
+	Path   string `json:"path"`
+	Bytes  int    `json:"bytes"`
+	SHA256 string `json:"sha256"`
+}
+
+// Result holds the outcome of a single run
+type Result struct {
+	Run
+	RedWins  int     `json:"red_wins"`
+	BlueWins int     `json:"blue_wins"`
+	Draws    int     `json:"draws"`
+	Error    string  `json:"error,omitempty"`
+	Seconds  float64 `json:"seconds"`
+	Files    []File  `json:"files,omitempty"`
+}
+
+// Manifest describes an executed experiment
+type Manifest struct {
+	Name    string   `json:"name"`
+	Go      string   `json:"go"` // Version of Go that built the runner
+	Spec    Spec     `json:"spec"`
+	Runs    []Result `json:"runs"`
+	Seconds float64  `json:"seconds"`
+}
+
+// Execute runs every combination listed by the spec, with as many runs at once as the spec allows, writing each run's
+// outputs beneath dir/runs/<id>, a copy of the spec to dir/spec.json, and the manifest to dir/manifest.json. The
+// directory is created if necessary, and must otherwise be empty. Progress, if not nil, is called with each result
+// as soon as its run ends, from any goroutine.
+//
+// A failing run is recorded in the manifest with its error, without stopping the others. If the context is done
+// before every run ends, Execute writes the manifest of the runs ended until then, and returns the context's error
+func Execute(ctx context.Context, spec Spec, dir string, progress func(Result)) (Manifest, error) {
+	runs, err := spec.Expand()
+	if err != nil {
+		return Manifest{}, err
+	}
+	spec = spec.normalize()
+	if spec.Parallel == 0 {
+		spec.Parallel = runtime.NumCPU()
+	}
+
+	// Each strategy is parsed once and shared by every run naming it, such that runs of a perfect player share its
+	// solvers, and their transposition tables, rather than each building its own. Strategies are safe for concurrent
+	// use, as a simulation's workers already share them
+	strategies := map[string]strategy.Strategy{}
+	for _, run := range runs {
+		for _, name := range []string{run.Red, run.Blue} {
+			if _, ok := strategies[name]; ok {
+				continue
+			}
+			if strategies[name], err = strategy.Parse(name); err != nil {
+				return Manifest{}, fmt.Errorf("invalid spec: %w", err)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return Manifest{}, fmt.Errorf("cannot create results directory: %w", err)
+	}
+	if entries, err := ioutil.ReadDir(dir); err != nil {
+		return Manifest{}, fmt.Errorf("cannot read results directory: %w", err)
+	} else if len(entries) > 0 {
+		return Manifest{}, fmt.Errorf("cannot execute experiment: %w", DirectoryError(dir))
+	}
+	if err := writeJSON(filepath.Join(dir, "spec.json"), spec); err != nil {
+		return Manifest{}, err
+	}
+
+	began := time.Now()
+	results, done := make([]Result, len(runs)), make([]bool, len(runs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < spec.Parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = execute(ctx, spec, dir, runs[i], strategies)
+				if ctx.Err() != nil {
+					continue
+				}
+				done[i] = true
+				if progress != nil {
+					progress(results[i])
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for i := range runs {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	m := Manifest{Name: spec.Name, Go: runtime.Version(), Spec: spec, Seconds: time.Since(began).Seconds()}
+	for i, r := range results {
+		if done[i] {
+			m.Runs = append(m.Runs, r)
+		}
+	}
+	if err := writeJSON(filepath.Join(dir, "manifest.json"), m); err != nil {
+		return m, err
+	}
+	return m, ctx.Err()
+}
+
+// execute performs a single run with the parsed strategies it names, writing its outputs
+func execute(ctx context.Context, spec Spec, dir string, run Run, strategies map[string]strategy.Strategy) Result {
+	began := time.Now()
+	r := Result{Run: run}
+	fail := func(err error) Result {
+		r.Error, r.Seconds = err.Error(), time.Since(began).Seconds()
+		return r
+	}
+
+	// Runs execute in parallel with each other, so each plays its own games one at a time
+	report, err := simulate.Run(ctx, simulate.Config{Games: run.Games, Red: strategies[run.Red],
+		Blue: strategies[run.Blue], Seed: run.Seed, Geometry: run.Geometry, Start: run.Start,
+		Confidence: spec.Confidence, Workers: 1})
+	if err != nil {
+		return fail(err)
+	}
+	r.RedWins, r.BlueWins, r.Draws = report.Red, report.Blue, report.Draws
+
+	summary, err := stats.Summarize(run.Geometry, report.Histories(), spec.Confidence)
+	if err != nil {
+		return fail(err)
+	}
+
+	runDir := filepath.Join("runs", run.ID)
+	if err := os.MkdirAll(filepath.Join(dir, runDir), 0755); err != nil {
+		return fail(err)
+	}
+	outputs := append([]Output{}, spec.Outputs...)
+	sort.Slice(outputs, func(i, j int) bool { return files[outputs[i]] < files[outputs[j]] })
+	for _, o := range outputs {
+		var buf bytes.Buffer
+		switch o {
+		case Summary:
+			err = stats.WriteTable(&buf, summary)
+		case CSV:
+			err = stats.WriteCSV(&buf, summary)
+		case Records:
+			w := record.NewWriter(&buf)
+			for _, g := range report.Games {
+				if err = w.Write(record.Record{Event: spec.Name + "/" + run.ID, Index: g.Index, Red: run.Red,
+					Blue: run.Blue, Geometry: run.Geometry, Start: run.Start, Moves: g.Moves,
+					Result: record.Finished(g.Winner), Seed: g.Seed}); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return fail(err)
+		}
+
+		path := filepath.Join(runDir, files[o])
+		if err := ioutil.WriteFile(filepath.Join(dir, path), buf.Bytes(), 0644); err != nil {
+			return fail(err)
+		}
+		digest := sha256.Sum256(buf.Bytes())
+		r.Files = append(r.Files, File{Path: filepath.ToSlash(path), Bytes: buf.Len(),
+			SHA256: hex.EncodeToString(digest[:])})
+	}
+
+	r.Seconds = time.Since(began).Seconds()
+	return r
+}
+
+// writeJSON writes a value to a file as indented JSON
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot encode %v: %w", filepath.Base(path), err)
+	}
+	if err := ioutil.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("cannot write %v: %w", filepath.Base(path), err)
+	}
+	return nil
+}
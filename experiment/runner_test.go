@@ -0,0 +1,142 @@
+package experiment
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/record"
+)
+
+// sweep is a small experiment, quick enough to execute repeatedly
+var sweep = Spec{Name: "sweep", Geometries: []string{"4x4", "5x4"}, Red: []string{"greedy", "random"},
+	Blue: []string{"random"}, Games: []int{20}, Seeds: []int64{1, 2}, Starts: []board.History{nil},
+	Outputs: []Output{Summary, CSV, Records}, Parallel: 3}
+
+func TestExecute(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "results")
+	var progress int32
+	m, err := Execute(context.Background(), sweep, dir, func(Result) { atomic.AddInt32(&progress, 1) })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(m.Runs) != 8 || progress != 8 {
+		t.Fatalf("expected 8 runs reported, observed %v in the manifest and %v in progress", len(m.Runs), progress)
+	}
+
+	for i, r := range m.Runs {
+		if r.ID != string(rune('0'+i)) || r.Error != "" {
+			t.Errorf("run %v: unexpected result %+v", i, r)
+		}
+		if r.RedWins+r.BlueWins+r.Draws != r.Games {
+			t.Errorf("run %v: expected %v games, observed %v", r.ID, r.Games, r.RedWins+r.BlueWins+r.Draws)
+		}
+		if len(r.Files) != 3 {
+			t.Fatalf("run %v: expected 3 files, observed %v", r.ID, r.Files)
+		}
+		for _, f := range r.Files {
+			data, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(f.Path)))
+			if err != nil {
+				t.Fatalf("run %v: cannot read %v: %v", r.ID, f.Path, err)
+			}
+			digest := sha256.Sum256(data)
+			if len(data) != f.Bytes || hex.EncodeToString(digest[:]) != f.SHA256 {
+				t.Errorf("run %v: %v doesn't match its manifest entry", r.ID, f.Path)
+			}
+		}
+	}
+
+	// The records of a run must replay to the tallied results
+	data, _ := ioutil.ReadFile(filepath.Join(dir, "runs", "0", "records.ndjson"))
+	records, err := record.ReadAll(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 20 {
+		t.Fatalf("expected 20 records, observed %v", len(records))
+	}
+	for _, rec := range records {
+		if err := rec.Validate(); err != nil {
+			t.Errorf("invalid record: %v", err)
+		}
+	}
+
+	var written Manifest
+	data, err = ioutil.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		t.Fatalf("cannot read manifest: %v", err)
+	}
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatalf("cannot decode manifest: %v", err)
+	}
+	if len(written.Runs) != len(m.Runs) || written.Runs[3].Files[0].SHA256 != m.Runs[3].Files[0].SHA256 {
+		t.Errorf("written manifest differs from the returned manifest")
+	}
+	if _, err := Load(filepath.Join(dir, "spec.json")); err != nil {
+		t.Errorf("written spec cannot be loaded: %v", err)
+	}
+}
+
+// TestExecute_deterministic asserts that executing the same spec twice writes identical outputs
+func TestExecute_deterministic(t *testing.T) {
+	m1, err := Execute(context.Background(), sweep, t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := sweep
+	s.Parallel = 1
+	m2, err := Execute(context.Background(), s, t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := range m1.Runs {
+		for j := range m1.Runs[i].Files {
+			if m1.Runs[i].Files[j] != m2.Runs[i].Files[j] {
+				t.Errorf("run %v: %v differs between executions", m1.Runs[i].ID, m1.Runs[i].Files[j].Path)
+			}
+		}
+	}
+}
+
+func TestExecute_errors(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "stale.txt"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Execute(context.Background(), sweep, dir, nil); !errors.As(err, new(DirectoryError)) {
+		t.Errorf("expected a directory error, observed %v", err)
+	}
+
+	s := sweep
+	s.Games = nil
+	if _, err := Execute(context.Background(), s, t.TempDir(), nil); !errors.As(err, new(SpecError)) {
+		t.Errorf("expected a spec error, observed %v", err)
+	}
+}
+
+func TestExecute_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	dir := t.TempDir()
+	m, err := Execute(ctx, sweep, dir, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected %v, observed %v", context.Canceled, err)
+	}
+	if len(m.Runs) == len(sweep.Geometries)*4 {
+		t.Errorf("expected a partial manifest, observed every run")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "manifest.json")); err != nil {
+		t.Errorf("expected a manifest to be written: %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+// Package experiment runs declarative experiments: a JSON spec names the board geometries, players, game counts,
+// seeds and starting positions to try, and the runner plays every combination of them in parallel, writing each
+// run's outputs into its own directory beneath a results directory, along with a copy of the spec and a manifest
+// recording every run's parameters, results, and the digest of every file written, such that an experiment can be
+// reviewed without rerunning it, and reproduced exactly by rerunning its spec.
+package experiment
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+// Output names a kind of file written for every run
+type Output string
+
+// Summary writes summary.txt, a human-readable table of outcomes, game lengths and column frequencies. CSV writes
+// summary.csv, the same statistics in long-form CSV. Records writes records.ndjson, the record of every game
+const (
+	Summary Output = "summary"
+	CSV     Output = "csv"
+	Records Output = "records"
+)
+
+// files maps each output to the name of the file it's written to
+var files = map[Output]string{Summary: "summary.txt", CSV: "summary.csv", Records: "records.ndjson"}
+
+// Spec holds an experiment, in which every combination of the listed values is run. Lists left empty take a single
+// default value where one exists
+type Spec struct {
+	Name       string          `json:"name"`
+	Geometries []string        `json:"geometries,omitempty"` // Board sizes as "<cols>x<rows>", defaulting to "7x6"
+	Red        []string        `json:"red"`                  // Strategies of RED, by name as accepted by strategy.Parse
+	Blue       []string        `json:"blue"`                 // Strategies of BLUE, by name
+	Games      []int           `json:"games"`                // Numbers of games per run
+	Seeds      []int64         `json:"seeds,omitempty"`      // Master seeds, defaulting to zero
+	Starts     []board.History `json:"starts,omitempty"`     // Starting moves in notation, defaulting to none
+	Outputs    []Output        `json:"outputs,omitempty"`    // Files written for every run, defaulting to summary
+	Confidence float64         `json:"confidence,omitempty"` // Confidence level of intervals, defaulting to 0.95
+	Parallel   int             `json:"parallel,omitempty"`   // Runs executed at once, defaulting to the CPU count
+}
+
+// Run holds the parameters of a single run, being one combination of the values listed by a spec
+type Run struct {
+	ID       string            `json:"id"`
+	Geometry bitboard.Geometry `json:"geometry"`
+	Red      string            `json:"red"`
+	Blue     string            `json:"blue"`
+	Games    int               `json:"games"`
+	Seed     int64             `json:"seed"`
+	Start    board.History     `json:"start"`
+}
+
+// Load reads a spec from a JSON file, rejecting unknown fields, as they're most likely misspellings
+func Load(path string) (Spec, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Spec{}, fmt.Errorf("cannot load spec: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var s Spec
+	if err := dec.Decode(&s); err != nil {
+		return Spec{}, fmt.Errorf("cannot load spec %v: %w", path, err)
+	}
+	return s, nil
+}
+
+// normalize fills in defaults
+func (s Spec) normalize() Spec {
+	if len(s.Geometries) == 0 {
+		s.Geometries = []string{bitboard.Standard.String()}
+	}
+	if len(s.Seeds) == 0 {
+		s.Seeds = []int64{0}
+	}
+	if len(s.Starts) == 0 {
+		s.Starts = []board.History{nil}
+	}
+	if len(s.Outputs) == 0 {
+		s.Outputs = []Output{Summary}
+	}
+	if s.Confidence == 0 {
+		s.Confidence = 0.95
+	}
+	return s
+}
+
+// Expand validates the spec and returns every run it describes, in a fixed order, varying the geometry slowest and
+// the starting moves fastest
+func (s Spec) Expand() ([]Run, error) {
+	s = s.normalize()
+	switch {
+	case len(s.Red) == 0 || len(s.Blue) == 0:
+		return nil, fmt.Errorf("invalid spec: %w", SpecError("red and blue strategies must be listed"))
+	case len(s.Games) == 0:
+		return nil, fmt.Errorf("invalid spec: %w", SpecError("game counts must be listed"))
+	case s.Confidence <= 0 || s.Confidence >= 1:
+		return nil, fmt.Errorf("invalid spec: %w", SpecError("confidence must be between 0 and 1"))
+	case s.Parallel < 0:
+		return nil, fmt.Errorf("invalid spec: %w", SpecError("parallel must not be negative"))
+	}
+	for _, o := range s.Outputs {
+		if _, ok := files[o]; !ok {
+			return nil, fmt.Errorf("invalid spec: %w", SpecError(fmt.Sprintf("unknown output %q", o)))
+		}
+	}
+	for _, n := range s.Games {
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid spec: %w", SpecError("game counts must be positive"))
+		}
+	}
+	for _, name := range append(append([]string{}, s.Red...), s.Blue...) {
+		if _, err := strategy.Parse(name); err != nil {
+			return nil, fmt.Errorf("invalid spec: %w", err)
+		}
+	}
+
+	var geometries []bitboard.Geometry
+	for _, text := range s.Geometries {
+		g, err := bitboard.ParseGeometry(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid spec: %w", err)
+		}
+		for _, start := range s.Starts {
+			if _, err := bitboard.FromHistory(g, start); err != nil {
+				return nil, fmt.Errorf("invalid spec: starting moves %q on %v: %w", start.Notation(), g, err)
+			}
+		}
+		geometries = append(geometries, g)
+	}
+
+	var runs []Run
+	for _, g := range geometries {
+		for _, red := range s.Red {
+			for _, blue := range s.Blue {
+				for _, games := range s.Games {
+					for _, seed := range s.Seeds {
+						for _, start := range s.Starts {
+							runs = append(runs, Run{Geometry: g, Red: red, Blue: blue, Games: games, Seed: seed,
+								Start: start})
+						}
+					}
+				}
+			}
+		}
+	}
+
+	width := len(fmt.Sprint(len(runs) - 1))
+	for i := range runs {
+		runs[i].ID = fmt.Sprintf("%0*d", width, i)
+	}
+	return runs, nil
+}
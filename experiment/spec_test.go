@@ -0,0 +1,101 @@
+package experiment
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+func TestSpec_Expand(t *testing.T) {
+	s := Spec{Geometries: []string{"5x4", "7x6"}, Red: []string{"greedy", "random"}, Blue: []string{"random"},
+		Games: []int{10}, Seeds: []int64{1, 2, 3}, Starts: []board.History{nil, {3}}}
+	runs, err := s.Expand()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runs) != 2*2*1*1*3*2 {
+		t.Fatalf("expected 24 runs, observed %v", len(runs))
+	}
+
+	first, last := runs[0], runs[len(runs)-1]
+	if first.ID != "00" || first.Geometry != (bitboard.Geometry{Width: 5, Height: 4}) || first.Red != "greedy" ||
+		first.Seed != 1 || len(first.Start) != 0 {
+		t.Errorf("unexpected first run %+v", first)
+	}
+	if last.ID != "23" || last.Geometry != bitboard.Standard || last.Red != "random" || last.Seed != 3 ||
+		!last.Start.Equals(board.History{3}) {
+		t.Errorf("unexpected last run %+v", last)
+	}
+	if runs[1].Seed != 1 || !runs[1].Start.Equals(board.History{3}) {
+		t.Errorf("expected starting moves to vary fastest, observed %+v", runs[1])
+	}
+}
+
+func TestSpec_Expand_defaults(t *testing.T) {
+	runs, err := Spec{Red: []string{"greedy"}, Blue: []string{"random"}, Games: []int{5}}.Expand()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runs) != 1 || runs[0].ID != "0" || runs[0].Geometry != bitboard.Standard || runs[0].Seed != 0 {
+		t.Errorf("unexpected runs %+v", runs)
+	}
+}
+
+func TestSpec_Expand_errors(t *testing.T) {
+	valid := func() Spec {
+		return Spec{Red: []string{"greedy"}, Blue: []string{"random"}, Games: []int{5}}
+	}
+	table := []struct {
+		name   string
+		modify func(*Spec)
+		target interface{}
+	}{
+		{"no red", func(s *Spec) { s.Red = nil }, new(SpecError)},
+		{"no games", func(s *Spec) { s.Games = nil }, new(SpecError)},
+		{"zero games", func(s *Spec) { s.Games = []int{0} }, new(SpecError)},
+		{"unknown output", func(s *Spec) { s.Outputs = []Output{"pdf"} }, new(SpecError)},
+		{"confidence", func(s *Spec) { s.Confidence = 2 }, new(SpecError)},
+		{"unknown strategy", func(s *Spec) { s.Blue = []string{"oracle"} }, new(strategy.ParseError)},
+		{"geometry", func(s *Spec) { s.Geometries = []string{"8x8"} }, new(bitboard.GeometryError)},
+		{"start", func(s *Spec) { s.Geometries, s.Starts = []string{"4x4"}, []board.History{{5}} },
+			new(bitboard.ColumnRangeError)},
+	}
+
+	for _, r := range table {
+		s := valid()
+		r.modify(&s)
+		if _, err := s.Expand(); !errors.As(err, r.target) {
+			t.Errorf("%v: expected error of type %T, observed %v", r.name, r.target, err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "spec.json")
+	data := `{"name": "test", "geometries": ["6x5"], "red": ["greedy"], "blue": ["random", "lookahead:2"],
+		"games": [100], "starts": ["", "44"], "outputs": ["summary", "records"]}`
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Name != "test" || len(s.Blue) != 2 || !s.Starts[1].Equals(board.History{3, 3}) || s.Outputs[1] != Records {
+		t.Errorf("unexpected spec %+v", s)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(`{"name": "test", "gmaes": [100]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Errorf("expected an error loading a spec with an unknown field")
+	}
+}
@@ -0,0 +1,48 @@
+package atomicfile
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "atomicfile")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "file")
+
+	failed := errors.New("failed")
+	table := []struct {
+		name     string
+		data     string
+		err      error
+		expected string // Contents of the file after the write
+	}{
+		{"new file", "first", nil, "first"},
+		{"replaced file", "second", nil, "second"},
+		{"failed write", "third", failed, "second"},
+	}
+	for _, r := range table {
+		err := Write(path, func(w io.Writer) error {
+			if _, err := io.WriteString(w, r.data); err != nil {
+				return err
+			}
+			return r.err
+		})
+		if !errors.Is(err, r.err) {
+			t.Errorf("%v: expected %v, observed %v", r.name, r.err, err)
+		}
+		if data, err := ioutil.ReadFile(path); err != nil || string(data) != r.expected {
+			t.Errorf("%v: expected file to hold %q, observed %q (%v)", r.name, r.expected, data, err)
+		}
+		if entries, _ := ioutil.ReadDir(dir); len(entries) != 1 {
+			t.Errorf("%v: expected no temporary files left, observed %v entries", r.name, len(entries))
+		}
+	}
+}
@@ -0,0 +1,31 @@
+// Package chunked reads sequences of records whose length is given by untrusted data, such as the header of a file.
+// Records are allocated as they're read, a chunk at a time, rather than up front, such that a corrupt length can't
+// claim more memory than the data holds.
+package chunked
+
+import (
+	"errors"
+	"io"
+)
+
+// Size sets the greatest number of records read at once
+const Size = 1 << 16
+
+// Read calls read with the number of records to allocate and read next, at most Size at a time, until n records are
+// read in all. As n records are expected, data ending early, whether or not within a record, is reported as
+// io.ErrUnexpectedEOF
+func Read(n uint64, read func(count int) error) error {
+	for done := uint64(0); done < n; {
+		count := n - done
+		if count > Size {
+			count = Size
+		}
+		if err := read(int(count)); errors.Is(err, io.EOF) {
+			return io.ErrUnexpectedEOF
+		} else if err != nil {
+			return err
+		}
+		done += count
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package chunked
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	var buf bytes.Buffer
+	for i := 0; i < Size+3; i++ {
+		binary.Write(&buf, binary.LittleEndian, uint32(i))
+	}
+
+	table := []struct {
+		n      uint64
+		counts []int
+		err    error
+	}{
+		{0, nil, nil},
+		{5, []int{5}, nil},
+		{Size + 3, []int{Size, 3}, nil},
+		{Size + 4, []int{Size, 4}, io.ErrUnexpectedEOF},
+		{1 << 60, []int{Size, Size}, io.ErrUnexpectedEOF},
+	}
+	for _, r := range table {
+		rd := bytes.NewReader(buf.Bytes())
+		var counts []int
+		var values []uint32
+		err := Read(r.n, func(count int) error {
+			counts = append(counts, count)
+			chunk := make([]uint32, count)
+			if err := binary.Read(rd, binary.LittleEndian, chunk); err != nil {
+				return err
+			}
+			values = append(values, chunk...)
+			return nil
+		})
+		if !errors.Is(err, r.err) || len(counts) != len(r.counts) {
+			t.Errorf("%v records: expected counts %v and error %v, observed %v and %v", r.n, r.counts, r.err, counts,
+				err)
+			continue
+		}
+		for i := range counts {
+			if counts[i] != r.counts[i] {
+				t.Errorf("%v records: expected counts %v, observed %v", r.n, r.counts, counts)
+			}
+		}
+		for i, v := range values {
+			if v != uint32(i) {
+				t.Fatalf("%v records: expected record %v to be %v, observed %v", r.n, i, i, v)
+			}
+		}
+	}
+}
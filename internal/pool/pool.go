@@ -0,0 +1,58 @@
+// Package pool runs indexed jobs across a number of workers, such as the games of a simulation or a tournament,
+// stopping at the first job to fail.
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Run calls do with each of the indices, in order, across the given number of workers, until every call returns, the
+// context is done, or a call fails, cancelling the context of the calls underway. Run returns the error of the first
+// call to fail, unless the caller's context ended the run, in which case it returns the context's own error rather
+// than the errors it caused in calls underway
+func Run(ctx context.Context, workers int, indices []int, do func(ctx context.Context, index int) error) error {
+	run, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var once sync.Once
+	var failure error
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := do(run, i); err != nil {
+					once.Do(func() {
+						failure = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+	// No job is dispatched once the run is seen to be cancelled, though a worker may take one as it's cancelled
+dispatch:
+	for _, i := range indices {
+		if run.Err() != nil {
+			break
+		}
+		select {
+		case jobs <- i:
+		case <-run.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil &&
+		(failure == nil || errors.Is(failure, context.Canceled) || errors.Is(failure, context.DeadlineExceeded)) {
+		return err
+	}
+	return failure
+}
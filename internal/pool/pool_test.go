@@ -0,0 +1,74 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	failed := errors.New("failed")
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	table := []struct {
+		name    string
+		ctx     context.Context
+		workers int
+		fail    int // Index of the job to fail, or -1 for none
+		err     error
+	}{
+		{"every job", context.Background(), 3, -1, nil},
+		{"single worker", context.Background(), 1, -1, nil},
+		{"failure", context.Background(), 1, 4, failed},
+		{"failure across workers", context.Background(), 4, 4, failed},
+		{"cancelled", cancelled, 2, -1, context.Canceled},
+	}
+	for _, r := range table {
+		indices := []int{0, 2, 4, 6, 8, 10}
+		var mutex sync.Mutex
+		done := map[int]bool{}
+		err := Run(r.ctx, r.workers, indices, func(ctx context.Context, i int) error {
+			if i == r.fail {
+				return fmt.Errorf("job %v: %w", i, failed)
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			done[i] = true
+			return nil
+		})
+		if !errors.Is(err, r.err) || (err == nil) != (r.err == nil) {
+			t.Errorf("%v: expected %v, observed %v", r.name, r.err, err)
+		}
+
+		switch {
+		case r.err == nil && len(done) != len(indices):
+			t.Errorf("%v: expected every job done, observed %v", r.name, done)
+		case r.fail >= 0 && r.workers == 1 && len(done) > 3: // One more job may be taken as the run is cancelled
+			t.Errorf("%v: expected jobs after the failure to be skipped, observed %v", r.name, done)
+		case r.ctx.Err() != nil && len(done) > r.workers:
+			t.Errorf("%v: expected at most a job per worker once cancelled, observed %v", r.name, done)
+		}
+	}
+}
+
+// TestRun_cancelsUnderway checks that a failure cancels the context of the jobs underway, and that the errors caused
+// by cancelling them don't displace it
+func TestRun_cancelsUnderway(t *testing.T) {
+	failed := errors.New("failed")
+	started := make(chan struct{})
+	err := Run(context.Background(), 2, []int{0, 1}, func(ctx context.Context, i int) error {
+		if i == 0 {
+			<-started
+			return failed
+		}
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(err, failed) {
+		t.Errorf("expected %v, observed %v", failed, err)
+	}
+}
@@ -0,0 +1,301 @@
+package netplay
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+// Client plays the remote side of a game hosted by a Host. A Client whose connection drops may reconnect, resuming the
+// game where it left off. Client is a Player
+type Client struct {
+	addr  string
+	moves sync.Mutex // Serializes calls to Move, as replies to MOVE don't identify the move they answer
+
+	mutex    sync.Mutex
+	conn     net.Conn // Connection to the host, or nil if disconnected
+	color    board.Type
+	token    string
+	history  board.History
+	err      error      // Why the last connection ended
+	pending  chan error // Receives the host's answer to the MOVE awaiting one, if any
+	column   int        // Column of the MOVE awaiting an answer
+	notifier notifier
+}
+
+// Join joins the game hosted at a TCP address, taking its remote seat
+func Join(ctx context.Context, addr string) (*Client, error) {
+	c := &Client{addr: addr}
+	if err := c.connect(ctx, "HELLO "+Version); err != nil {
+		return nil, fmt.Errorf("cannot join game at %v: %w", addr, err)
+	}
+	return c, nil
+}
+
+// Resume resumes a game hosted at a TCP address with the token of its remote seat, as given by Token, reclaiming the
+// seat even if the client that held it has gone, such as when its process has ended
+func Resume(ctx context.Context, addr, token string) (*Client, error) {
+	c := &Client{addr: addr, token: token}
+	if err := c.connect(ctx, fmt.Sprintf("RESUME %v %v", Version, token)); err != nil {
+		return nil, fmt.Errorf("cannot resume game at %v: %w", addr, err)
+	}
+	return c, nil
+}
+
+// Reconnect reconnects to the host after the connection has dropped, resuming the game with the seat's token. Any
+// connection still open is closed first
+func (c *Client) Reconnect(ctx context.Context) error {
+	c.mutex.Lock()
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	token := c.token
+	c.mutex.Unlock()
+
+	if err := c.connect(ctx, fmt.Sprintf("RESUME %v %v", Version, token)); err != nil {
+		return fmt.Errorf("cannot resume game at %v: %w", c.addr, err)
+	}
+	return nil
+}
+
+// Close leaves the game, which may still be resumed by reconnecting
+func (c *Client) Close() error {
+	c.mutex.Lock()
+	conn := c.conn
+	c.mutex.Unlock()
+	if conn == nil {
+		return nil
+	}
+
+	conn.SetWriteDeadline(time.Now().Add(WriteTimeout))
+	fmt.Fprintln(conn, "BYE")
+	return conn.Close()
+}
+
+// Color returns the color played by the client
+func (c *Client) Color() board.Type {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.color
+}
+
+// Token returns the token with which the game may be resumed
+func (c *Client) Token() string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.token
+}
+
+// Err returns why the connection to the host ended, or nil if it remains connected
+func (c *Client) Err() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.conn != nil {
+		return nil
+	}
+	return c.err
+}
+
+// State returns the state of the game, along with a channel closed once it next changes
+func (c *Client) State() (State, <-chan struct{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return State{Moves: append(board.History{}, c.history...), Opponent: c.conn != nil}, c.notifier.wait()
+}
+
+// Move plays a move for the client's side, returning once the host has accepted or rejected it. Moves rejected by
+// the host return the error the host would return had it been played locally, such as board.TurnValidityError
+func (c *Client) Move(ctx context.Context, col int) error {
+	c.moves.Lock()
+	defer c.moves.Unlock()
+
+	c.mutex.Lock()
+	conn, color := c.conn, c.color
+	if conn == nil {
+		c.mutex.Unlock()
+		return fmt.Errorf("cannot play column %v: %w", col+1, DisconnectedError{})
+	}
+	if col < 0 || col >= board.COLS {
+		c.mutex.Unlock()
+		return fmt.Errorf("cannot play column %v: %w", col+1, bitboard.ColumnRangeError(col))
+	}
+	result := make(chan error, 1)
+	c.pending, c.column = result, col
+	c.mutex.Unlock()
+
+	defer func() {
+		c.mutex.Lock()
+		if c.pending == result {
+			c.pending = nil
+		}
+		c.mutex.Unlock()
+	}()
+
+	conn.SetWriteDeadline(time.Now().Add(WriteTimeout))
+	if _, err := fmt.Fprintf(conn, "MOVE %v\n", board.History{board.Move(col)}.Notation()); err != nil {
+		conn.Close()
+		return fmt.Errorf("cannot play column %v: %w", col+1, DisconnectedError{})
+	}
+
+	select {
+	case err := <-result:
+		if err != nil {
+			return fmt.Errorf("cannot play %v move in column %v: %w", colorName(color), col+1, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// connect dials the host and completes the handshake beginning with the given line, then reads from the host until
+// the connection ends
+func (c *Client) connect(ctx context.Context, hello string) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return err
+	}
+	lines := bufio.NewScanner(conn)
+	conn.SetDeadline(time.Now().Add(HandshakeTimeout))
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	color, token, history, err := handshake(conn, lines, hello)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	conn.SetDeadline(time.Time{})
+
+	c.mutex.Lock()
+	c.conn, c.color, c.token, c.history, c.err = conn, color, token, history, nil
+	c.notifier.notify()
+	c.mutex.Unlock()
+
+	go c.read(conn, lines)
+	return nil
+}
+
+// handshake sends the first line of a connection, then reads the WELCOME and STATE lines answering it
+func handshake(conn net.Conn, lines *bufio.Scanner, hello string) (board.Type, string, board.History, error) {
+	if _, err := fmt.Fprintln(conn, hello); err != nil {
+		return board.NONE, "", nil, err
+	}
+
+	var color board.Type
+	var token string
+	for lines.Scan() {
+		fields := strings.Fields(lines.Text())
+		switch {
+		case len(fields) == 0:
+		case fields[0] == "ERROR":
+			return board.NONE, "", nil, HostError(strings.TrimSpace(strings.TrimPrefix(lines.Text(), "ERROR")))
+		case fields[0] == "WELCOME" && len(fields) == 4:
+			if fields[1] != Version {
+				return board.NONE, "", nil, VersionError(fields[1])
+			}
+			var ok bool
+			if color, ok = parseColor(fields[2]); !ok {
+				return board.NONE, "", nil, ProtocolError{Line: lines.Text(), Reason: "unknown color"}
+			}
+			token = fields[3]
+		case fields[0] == "STATE" && len(fields) == 2 && token != "":
+			history, err := parseMoves(fields[1])
+			if err != nil {
+				return board.NONE, "", nil, ProtocolError{Line: lines.Text(), Reason: err.Error()}
+			}
+			return color, token, history, nil
+		default:
+			return board.NONE, "", nil, ProtocolError{Line: lines.Text(), Reason: "expected WELCOME then STATE"}
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return board.NONE, "", nil, err
+	}
+	return board.NONE, "", nil, DisconnectedError{}
+}
+
+// read reads lines from the host until the connection ends, then marks the client as disconnected
+func (c *Client) read(conn net.Conn, lines *bufio.Scanner) {
+	err := c.receive(lines)
+	conn.Close()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.conn != conn {
+		return
+	}
+	c.conn, c.err = nil, err
+	if c.pending != nil {
+		c.pending <- DisconnectedError{}
+		c.pending = nil
+	}
+	c.notifier.notify()
+}
+
+// receive handles lines from the host, returning why the connection ended
+func (c *Client) receive(lines *bufio.Scanner) error {
+	for lines.Scan() {
+		fields := strings.Fields(lines.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "STATE":
+			if len(fields) != 2 {
+				return ProtocolError{Line: lines.Text(), Reason: "expected moves"}
+			}
+			history, err := parseMoves(fields[1])
+			if err != nil {
+				return ProtocolError{Line: lines.Text(), Reason: err.Error()}
+			}
+			c.mutex.Lock()
+			c.history = history
+			c.notifier.notify()
+			c.mutex.Unlock()
+		case "MOVED":
+			color, ok := board.NONE, false
+			if len(fields) == 3 {
+				color, ok = parseColor(fields[1])
+			}
+			mv, err := board.ParseHistory(fields[len(fields)-1])
+			if !ok || err != nil || len(mv) != 1 {
+				return ProtocolError{Line: lines.Text(), Reason: "expected a color and a column"}
+			}
+			c.mutex.Lock()
+			c.history = append(c.history, mv[0])
+			if color == c.color && c.pending != nil {
+				c.pending <- nil
+				c.pending = nil
+			}
+			c.notifier.notify()
+			c.mutex.Unlock()
+		case "REJECTED":
+			if len(fields) < 2 {
+				return ProtocolError{Line: lines.Text(), Reason: "expected a reason"}
+			}
+			message := strings.Join(fields[2:], " ")
+			c.mutex.Lock()
+			if c.pending != nil {
+				c.pending <- rejection(fields[1], message, c.color, c.column)
+				c.pending = nil
+			}
+			c.mutex.Unlock()
+		case "ERROR":
+			return HostError(strings.TrimSpace(strings.TrimPrefix(lines.Text(), "ERROR")))
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return err
+	}
+	return DisconnectedError{}
+}
@@ -0,0 +1,188 @@
+package netplay
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+// join joins a game hosted by a Host, leaving it once the test ends
+func join(t *testing.T, h *Host) *Client {
+	t.Helper()
+	c, err := Join(context.Background(), h.Addr().String())
+	if err != nil {
+		t.Fatalf("cannot join game: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestJoin(t *testing.T) {
+	h := newHost(t, board.RED)
+	c := join(t, h)
+	if c.Color() != board.BLUE || len(c.Token()) != 32 || c.Err() != nil {
+		t.Errorf("expected to play BLUE with a token, observed %v, %q and %v", c.Color(), c.Token(), c.Err())
+	}
+
+	_, err := Join(context.Background(), h.Addr().String())
+	if !errors.As(err, new(HostError)) {
+		t.Errorf("expected HostError joining a taken seat, observed %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Join(ctx, h.Addr().String()); err == nil {
+		t.Error("expected error joining with a done context")
+	}
+}
+
+func TestClient_Move(t *testing.T) {
+	h := newHost(t, board.BLUE)
+	c := join(t, h)
+	ctx := context.Background()
+
+	table := []struct {
+		name   string
+		host   bool
+		col    int
+		target interface{}
+	}{
+		{"host out of turn", true, 3, new(board.TurnValidityError)},
+		{"client", false, 3, nil},
+		{"client out of turn", false, 3, new(board.TurnValidityError)},
+		{"host", true, 3, nil},
+		{"missing column", false, 7, new(bitboard.ColumnRangeError)},
+		{"client again", false, 3, nil},
+		{"host again", true, 3, nil},
+		{"client once more", false, 3, nil},
+		{"host once more", true, 3, nil},
+		{"full column", false, 3, new(board.FullColumnError)},
+	}
+	for _, r := range table {
+		var p Player = c
+		if r.host {
+			p = h
+		}
+		err := p.Move(ctx, r.col)
+		if r.target == nil && err != nil {
+			t.Errorf("%v: cannot move: %v", r.name, err)
+		} else if r.target != nil && !errors.As(err, r.target) {
+			t.Errorf("%v: expected %T, observed %v", r.name, r.target, err)
+		}
+	}
+
+	s := wait(t, c, func(s State) bool { return len(s.Moves) == 6 })
+	if hs, _ := h.State(); s.Moves.Notation() != "444444" || hs.Moves.Notation() != s.Moves.Notation() {
+		t.Errorf("expected both sides to see moves 444444, observed %q and %q", s.Moves.Notation(),
+			hs.Moves.Notation())
+	}
+
+	for _, col := range "1213121" {
+		var p Player = c
+		if s, _ := h.State(); s.Turn() == board.BLUE {
+			p = h
+		}
+		if err := p.Move(ctx, int(col-'1')); err != nil {
+			t.Fatalf("cannot move: %v", err)
+		}
+	}
+	s = wait(t, c, func(s State) bool { return s.Over() })
+	if err := c.Move(ctx, 5); s.Winner() != board.RED || !errors.As(err, &bitboard.GameOverError{}) {
+		t.Errorf("expected RED to have won and further moves to be rejected, observed %v and %v", s.Winner(), err)
+	}
+}
+
+func TestClient_Reconnect(t *testing.T) {
+	h := newHost(t, board.RED)
+	c := join(t, h)
+	ctx := context.Background()
+	if err := h.Move(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	wait(t, c, func(s State) bool { return len(s.Moves) == 1 })
+
+	// Drop the connection abruptly, as a network failure would
+	c.mutex.Lock()
+	c.conn.Close()
+	c.mutex.Unlock()
+	wait(t, c, func(s State) bool { return !s.Opponent })
+	wait(t, h, func(s State) bool { return !s.Opponent })
+	if err := c.Move(ctx, 3); !errors.As(err, &DisconnectedError{}) {
+		t.Errorf("expected DisconnectedError moving while disconnected, observed %v", err)
+	}
+	if c.Err() == nil {
+		t.Error("expected error explaining the disconnect")
+	}
+
+	// The host still can't move out of turn while the client is away
+	if err := h.Move(ctx, 3); !errors.As(err, new(board.TurnValidityError)) {
+		t.Errorf("expected TurnValidityError, observed %v", err)
+	}
+
+	token := c.Token()
+	if err := c.Reconnect(ctx); err != nil {
+		t.Fatalf("cannot reconnect: %v", err)
+	}
+	if c.Token() != token || c.Color() != board.BLUE {
+		t.Errorf("expected to resume as BLUE with token %q, observed %v and %q", token, c.Color(), c.Token())
+	}
+	wait(t, h, func(s State) bool { return s.Opponent })
+	if err := c.Move(ctx, 4); err != nil {
+		t.Fatalf("cannot move after reconnecting: %v", err)
+	}
+	if s, _ := h.State(); s.Moves.Notation() != "45" {
+		t.Errorf("expected moves 45, observed %q", s.Moves.Notation())
+	}
+
+	// Leaving keeps the seat, which may still be resumed
+	c.Close()
+	wait(t, h, func(s State) bool { return !s.Opponent })
+	if err := c.Reconnect(ctx); err != nil {
+		t.Fatalf("cannot reconnect after leaving: %v", err)
+	}
+	if s := wait(t, c, func(s State) bool { return s.Opponent }); s.Moves.Notation() != "45" {
+		t.Errorf("expected moves 45, observed %q", s.Moves.Notation())
+	}
+}
+
+func TestResume(t *testing.T) {
+	h := newHost(t, board.BLUE)
+	c := join(t, h)
+	ctx := context.Background()
+	if err := c.Move(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	// Drop the connection without leaving, as when the joining process ends
+	c.mutex.Lock()
+	c.conn.Close()
+	c.mutex.Unlock()
+	wait(t, h, func(s State) bool { return !s.Opponent })
+
+	if _, err := Join(ctx, h.Addr().String()); !errors.As(err, new(HostError)) {
+		t.Errorf("expected HostError joining a reserved seat, observed %v", err)
+	}
+	if _, err := Resume(ctx, h.Addr().String(), "0123"); !errors.As(err, new(HostError)) {
+		t.Errorf("expected HostError resuming with the wrong token, observed %v", err)
+	}
+
+	resumed, err := Resume(ctx, h.Addr().String(), c.Token())
+	if err != nil {
+		t.Fatalf("cannot resume: %v", err)
+	}
+	defer resumed.Close()
+	if s, _ := resumed.State(); resumed.Color() != board.RED || resumed.Token() != c.Token() ||
+		s.Moves.Notation() != "4" {
+		t.Errorf("expected to resume as RED after moves 4, observed %v, %q and %+v", resumed.Color(),
+			resumed.Token(), s)
+	}
+	if err := h.Move(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := resumed.Move(ctx, 4); err != nil {
+		t.Errorf("cannot move after resuming: %v", err)
+	}
+}
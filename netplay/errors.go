@@ -0,0 +1,48 @@
+package netplay
+
+import "fmt"
+
+// VersionError defines an error used when the other side of a connection speaks an unsupported protocol version
+type VersionError string
+
+func (e VersionError) Error() string {
+	return fmt.Sprintf("unsupported protocol version %q, expected %q", string(e), Version)
+}
+
+// SeatError defines an error used when joining a game whose remote seat is taken, which only the resume token of its
+// player can reclaim, or when resuming with a token that doesn't match
+type SeatError struct{}
+
+func (e SeatError) Error() string {
+	return "seat is taken, and can only be resumed with its token"
+}
+
+// DisconnectedError defines an error used when moving while disconnected from the host
+type DisconnectedError struct{}
+
+func (e DisconnectedError) Error() string {
+	return "not connected to the host"
+}
+
+// RejectedError defines an error used when the host rejects a move for a reason not otherwise represented
+type RejectedError string
+
+func (e RejectedError) Error() string {
+	return fmt.Sprintf("host rejected move: %v", string(e))
+}
+
+// ProtocolError defines an error used when the other side of a connection sends a line that breaks the protocol
+type ProtocolError struct {
+	Line, Reason string
+}
+
+func (e ProtocolError) Error() string {
+	return fmt.Sprintf("received %q: %v", e.Line, e.Reason)
+}
+
+// HostError defines an error used when the host reports a fatal error before closing the connection
+type HostError string
+
+func (e HostError) Error() string {
+	return fmt.Sprintf("host reported error: %v", string(e))
+}
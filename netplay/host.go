@@ -0,0 +1,257 @@
+package netplay
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+// Host hosts a game, holding its authoritative board, playing one side itself and accepting a connection from a
+// player of the other side. Host is a Player, for its own side
+type Host struct {
+	local    board.Type
+	listener net.Listener
+
+	mutex    sync.Mutex
+	board    board.Board
+	token    string // Token of the remote seat, or empty until a player first joins
+	remote   *remote
+	conns    map[net.Conn]struct{} // Every open connection, including those yet to complete the handshake
+	notifier notifier
+}
+
+// remote holds the connection of the remote player
+type remote struct {
+	conn  net.Conn
+	mutex sync.Mutex // Guards writes
+}
+
+// send writes a line to the remote player, closing the connection if it can't be written in time
+func (r *remote) send(format string, args ...interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.conn.SetWriteDeadline(time.Now().Add(WriteTimeout))
+	if _, err := fmt.Fprintf(r.conn, format+"\n", args...); err != nil {
+		r.conn.Close()
+	}
+}
+
+// NewHost constructs a Host playing the given color, accepting the remote player through the listener once served
+func NewHost(l net.Listener, local board.Type) (*Host, error) {
+	if local != board.RED && local != board.BLUE {
+		return nil, fmt.Errorf("cannot host game as %v: %w", local, board.TurnValidityError(local))
+	}
+	return &Host{local: local, listener: l, board: board.New(), conns: map[net.Conn]struct{}{}}, nil
+}
+
+// Listen constructs a Host playing the given color, listening for the remote player on a TCP address
+func Listen(addr string, local board.Type) (*Host, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot host game: %w", err)
+	}
+	h, err := NewHost(l, local)
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	return h, nil
+}
+
+// Addr returns the address on which the host listens
+func (h *Host) Addr() net.Addr {
+	return h.listener.Addr()
+}
+
+// Color returns the color played by the host
+func (h *Host) Color() board.Type {
+	return h.local
+}
+
+// State returns the state of the game, along with a channel closed once it next changes
+func (h *Host) State() (State, <-chan struct{}) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return State{Moves: h.board.History(), Opponent: h.remote != nil}, h.notifier.wait()
+}
+
+// Move plays a move for the host's own side
+func (h *Host) Move(_ context.Context, col int) error {
+	return h.play(h.local, col)
+}
+
+// Serve accepts connections from the remote player until the context is done, then closes the listener and any
+// connection, returning the context's error. Only one connection holds the remote seat at a time, being the first to
+// join, or the latest to resume with the seat's token
+func (h *Host) Serve(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		h.listener.Close()
+		h.mutex.Lock()
+		for conn := range h.conns {
+			conn.Close()
+		}
+		h.mutex.Unlock()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := h.listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("cannot accept connection: %w", err)
+		}
+		h.mutex.Lock()
+		h.conns[conn] = struct{}{}
+		h.mutex.Unlock()
+		if ctx.Err() != nil {
+			conn.Close()
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.serve(ctx, conn)
+			h.mutex.Lock()
+			delete(h.conns, conn)
+			h.mutex.Unlock()
+		}()
+	}
+}
+
+// serve handles a single connection, from handshake to disconnection
+func (h *Host) serve(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	r := &remote{conn: conn}
+	lines := bufio.NewScanner(conn)
+
+	conn.SetReadDeadline(time.Now().Add(HandshakeTimeout))
+	if !lines.Scan() {
+		return
+	}
+	if err := h.admit(r, lines.Text()); err != nil {
+		r.send("ERROR %v", err)
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+	defer h.leave(r)
+
+	for lines.Scan() {
+		if ctx.Err() != nil {
+			return
+		}
+		fields := strings.Fields(lines.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "MOVE":
+			var err error
+			if len(fields) != 2 {
+				err = ProtocolError{Line: lines.Text(), Reason: "expected a single column"}
+			} else if mv, parseErr := board.ParseHistory(fields[1]); parseErr != nil || len(mv) != 1 {
+				err = ProtocolError{Line: lines.Text(), Reason: "expected a column in move notation"}
+			} else {
+				err = h.play(opponent(h.local), int(mv[0]))
+			}
+			if err != nil {
+				r.send("REJECTED %v %v", reason(err), err)
+			}
+		case "PING":
+			r.send("PONG")
+		case "BYE":
+			return
+		}
+	}
+}
+
+// admit handles the first line of a connection, giving the connection the remote seat if it may take it
+func (h *Host) admit(r *remote, line string) error {
+	fields := strings.Fields(line)
+	switch {
+	case len(fields) >= 2 && (fields[0] == "HELLO" || fields[0] == "RESUME") && fields[1] != Version:
+		return VersionError(fields[1])
+	case len(fields) == 2 && fields[0] == "HELLO":
+	case len(fields) == 3 && fields[0] == "RESUME":
+	default:
+		return ProtocolError{Line: line, Reason: "expected HELLO or RESUME"}
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if fields[0] == "HELLO" {
+		if h.token != "" {
+			return SeatError{}
+		}
+		token := make([]byte, 16)
+		if _, err := rand.Read(token); err != nil {
+			return fmt.Errorf("cannot generate token: %w", err)
+		}
+		h.token = hex.EncodeToString(token)
+	} else if h.token == "" || subtle.ConstantTimeCompare([]byte(fields[2]), []byte(h.token)) != 1 {
+		return SeatError{}
+	}
+
+	// A resumed seat may still be held by a connection that has yet to notice it dropped
+	if h.remote != nil {
+		h.remote.conn.Close()
+	}
+	h.remote = r
+	r.send("WELCOME %v %v %v", Version, colorName(opponent(h.local)), h.token)
+	r.send("STATE %v", formatMoves(h.board.History()))
+	h.notifier.notify()
+	return nil
+}
+
+// leave releases the remote seat held by a connection, if it still holds it
+func (h *Host) leave(r *remote) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.remote == r {
+		h.remote = nil
+		h.notifier.notify()
+	}
+}
+
+// play plays a move for either side, after checking that it's legal, reporting it to the remote player
+func (h *Host) play(t board.Type, col int) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	p, err := bitboard.FromBoard(h.board)
+	if err != nil {
+		return err
+	}
+	switch {
+	case p.IsOver():
+		return fmt.Errorf("cannot play column %v: %w", col+1, bitboard.GameOverError{})
+	case col < 0 || col >= board.COLS:
+		return fmt.Errorf("cannot play column %v: %w", col+1, bitboard.ColumnRangeError(col))
+	case t == board.RED:
+		_, err = h.board.MoveRed(col)
+	default:
+		_, err = h.board.MoveBlue(col)
+	}
+	if err != nil {
+		return err
+	}
+
+	if h.remote != nil {
+		h.remote.send("MOVED %v %v", colorName(t), board.History{board.Move(col)}.Notation())
+	}
+	h.notifier.notify()
+	return nil
+}
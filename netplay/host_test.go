@@ -0,0 +1,233 @@
+package netplay
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/talglobus/fearsome/board"
+)
+
+// newHost serves a Host playing the given color on a local port until the test ends
+func newHost(t *testing.T, local board.Type) *Host {
+	t.Helper()
+	h, err := Listen("127.0.0.1:0", local)
+	if err != nil {
+		t.Fatalf("cannot host game: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- h.Serve(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; !errors.Is(err, context.Canceled) {
+			t.Errorf("expected Serve to return context.Canceled, observed %v", err)
+		}
+	})
+	return h
+}
+
+// wait waits for the state of a player to satisfy a condition, failing the test if it doesn't within a few seconds
+func wait(t *testing.T, p Player, cond func(State) bool) State {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		s, changed := p.State()
+		if cond(s) {
+			return s
+		}
+		select {
+		case <-changed:
+		case <-timeout:
+			t.Fatalf("timed out waiting for state, observed %+v", s)
+		}
+	}
+}
+
+// peer is a raw connection to a host, speaking the protocol line by line
+type peer struct {
+	t     *testing.T
+	conn  net.Conn
+	lines *bufio.Scanner
+}
+
+func dial(t *testing.T, h *Host) *peer {
+	t.Helper()
+	conn, err := net.Dial("tcp", h.Addr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to host: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	return &peer{t: t, conn: conn, lines: bufio.NewScanner(conn)}
+}
+
+func (p *peer) send(line string) {
+	p.t.Helper()
+	if _, err := fmt.Fprintln(p.conn, line); err != nil {
+		p.t.Fatalf("cannot send %q: %v", line, err)
+	}
+}
+
+// expect reads a line, failing the test unless it begins with the given prefix
+func (p *peer) expect(prefix string) string {
+	p.t.Helper()
+	if !p.lines.Scan() {
+		p.t.Fatalf("expected line beginning %q, observed end of connection: %v", prefix, p.lines.Err())
+	}
+	if !strings.HasPrefix(p.lines.Text(), prefix) {
+		p.t.Fatalf("expected line beginning %q, observed %q", prefix, p.lines.Text())
+	}
+	return p.lines.Text()
+}
+
+// closed fails the test unless the host closes the connection
+func (p *peer) closed() {
+	p.t.Helper()
+	if p.lines.Scan() {
+		p.t.Fatalf("expected connection to close, observed %q", p.lines.Text())
+	}
+}
+
+func TestNewHost(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if _, err := NewHost(l, board.NONE); !errors.As(err, new(board.TurnValidityError)) {
+		t.Errorf("expected TurnValidityError hosting as NONE, observed %v", err)
+	}
+}
+
+func TestHost_handshake(t *testing.T) {
+	table := []struct {
+		name, line, reply string
+	}{
+		{"version", "HELLO fearsome/0", "ERROR " + VersionError("fearsome/0").Error()},
+		{"resume of free seat", "RESUME " + Version + " 0123", "ERROR " + SeatError{}.Error()},
+		{"unknown command", "MOVE 4", "ERROR "},
+		{"missing version", "HELLO", "ERROR "},
+	}
+
+	for _, r := range table {
+		t.Run(r.name, func(t *testing.T) {
+			p := dial(t, newHost(t, board.RED))
+			p.send(r.line)
+			p.expect(r.reply)
+			p.closed()
+		})
+	}
+}
+
+func TestHost_moves(t *testing.T) {
+	h := newHost(t, board.RED)
+	p := dial(t, h)
+	p.send("HELLO " + Version)
+	if fields := strings.Fields(p.expect("WELCOME ")); len(fields) != 4 || fields[1] != Version ||
+		fields[2] != "blue" || len(fields[3]) != 32 {
+		t.Fatalf("unexpected welcome %v", fields)
+	}
+	p.expect("STATE -")
+	wait(t, h, func(s State) bool { return s.Opponent })
+
+	table := []struct {
+		name  string
+		local bool
+		col   int
+		reply string
+	}{
+		{"out of turn", false, 3, "REJECTED turn "},
+		{"local", true, 3, "MOVED red 4"},
+		{"local out of turn", true, 3, ""},
+		{"remote", false, 3, "MOVED blue 4"},
+		{"missing column", false, 7, "REJECTED column "},
+		{"bad notation", false, -1, "REJECTED syntax "},
+	}
+	for _, r := range table {
+		var err error
+		switch {
+		case r.local:
+			err = h.Move(context.Background(), r.col)
+		case r.col < 0:
+			p.send("MOVE !")
+		default:
+			p.send("MOVE " + board.History{board.Move(r.col)}.Notation())
+		}
+		if r.reply == "" {
+			if !errors.As(err, new(board.TurnValidityError)) {
+				t.Errorf("%v: expected TurnValidityError, observed %v", r.name, err)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("%v: cannot move: %v", r.name, err)
+		}
+		p.expect(r.reply)
+	}
+
+	p.send("PING")
+	p.expect("PONG")
+	play := func(moves string) {
+		t.Helper()
+		for _, col := range moves {
+			if s, _ := h.State(); s.Turn() == board.RED {
+				h.Move(context.Background(), int(col-'1'))
+				p.expect("MOVED red " + string(col))
+			} else {
+				p.send("MOVE " + string(col))
+				p.expect("MOVED blue " + string(col))
+			}
+		}
+	}
+	play("44441")
+	p.send("MOVE 4")
+	p.expect("REJECTED full ")
+	play("2123232")
+	p.send("MOVE 1")
+	p.expect("REJECTED over ")
+	if s, _ := h.State(); s.Winner() != board.BLUE || s.Moves.Notation() != "44444412123232" {
+		t.Errorf("expected BLUE to have won, observed %+v", s)
+	}
+}
+
+func TestHost_seat(t *testing.T) {
+	h := newHost(t, board.BLUE)
+	first := dial(t, h)
+	first.send("HELLO " + Version)
+	token := strings.Fields(first.expect("WELCOME " + Version + " red "))[3]
+	first.expect("STATE -")
+
+	second := dial(t, h)
+	second.send("HELLO " + Version)
+	second.expect("ERROR " + SeatError{}.Error())
+	second.closed()
+
+	wrong := dial(t, h)
+	wrong.send("RESUME " + Version + " " + strings.Repeat("0", 32))
+	wrong.expect("ERROR " + SeatError{}.Error())
+
+	first.send("MOVE 4")
+	first.expect("MOVED red 4")
+
+	// Resuming takes the seat from the connection holding it, as when that connection has dropped unnoticed
+	resumed := dial(t, h)
+	resumed.send("RESUME " + Version + " " + token)
+	resumed.expect("WELCOME " + Version + " red " + token)
+	resumed.expect("STATE 4")
+	first.closed()
+
+	if err := h.Move(context.Background(), 4); err != nil {
+		t.Fatalf("cannot move: %v", err)
+	}
+	resumed.expect("MOVED blue 5")
+	resumed.send("BYE")
+	resumed.closed()
+	wait(t, h, func(s State) bool { return !s.Opponent })
+}
@@ -0,0 +1,192 @@
+// Package netplay plays games between two people on different machines, over TCP. One process hosts the game,
+// holding the authoritative board.Board and playing one side, and the other joins it to play the other side. The host
+// checks every move, rejecting those played out of turn, in full or missing columns, or after the game has ended, so
+// that a misbehaving peer can never corrupt the game.
+//
+// The protocol is line-based, each line being a command followed by its arguments, separated by spaces. The joining
+// side sends:
+//
+//	HELLO <version>                 joins the game, taking the remote seat if free
+//	RESUME <version> <token>        rejoins the game after a disconnect, reclaiming the remote seat
+//	MOVE <column>                   plays a move, the column being in move notation, counting from one
+//	PING                            asks for a PONG, to check the connection
+//	BYE                             leaves the game, which may still be resumed
+//
+// The host replies:
+//
+//	WELCOME <version> <color> <token>  accepts HELLO or RESUME, giving the color played and the token to resume with
+//	STATE <moves>                   gives every move of the game so far, or - if none, following WELCOME
+//	MOVED <color> <column>          reports a move played by either side
+//	REJECTED <reason> <message>     rejects the last MOVE, the reason being one of turn, full, column, over, or syntax
+//	PONG                            replies to PING
+//	ERROR <message>                 reports a fatal error, after which the host closes the connection
+//
+// The version is that of the protocol, currently fearsome/1, which both sides must speak. A player whose connection
+// drops keeps the remote seat, and may resume the game with the token given by WELCOME, even from a new connection
+// on another machine. Either side ignores commands it doesn't know, such that later versions may add commands.
+package netplay
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+// Version is the version of the protocol spoken
+const Version = "fearsome/1"
+
+// HandshakeTimeout sets how long either side waits for the other to complete the handshake
+const HandshakeTimeout = 10 * time.Second
+
+// WriteTimeout sets how long either side waits to send a line before giving up on the connection
+const WriteTimeout = 10 * time.Second
+
+// Reasons given by REJECTED for rejecting a move
+const (
+	turnReason   = "turn"
+	fullReason   = "full"
+	columnReason = "column"
+	overReason   = "over"
+	syntaxReason = "syntax"
+)
+
+// State holds the state of a game as seen by one of its players
+type State struct {
+	Moves board.History
+	// Opponent reports whether the other side is connected. For the host, it's whether a player has joined and
+	// remains connected, and for the joining player, whether it remains connected to the host
+	Opponent bool
+}
+
+// position returns the position reached by the state's moves, which the host has already verified
+func (s State) position() bitboard.Position {
+	p, _ := bitboard.FromHistory(bitboard.Standard, s.Moves)
+	return p
+}
+
+// Turn returns the player to move, or NONE if the game is over
+func (s State) Turn() board.Type {
+	if p := s.position(); !p.IsOver() {
+		return p.Turn()
+	}
+	return board.NONE
+}
+
+// Over returns whether the game is over
+func (s State) Over() bool {
+	return s.position().IsOver()
+}
+
+// Winner returns the player who has won, or NONE if the game is drawn or still underway
+func (s State) Winner() board.Type {
+	return s.position().Winner()
+}
+
+// Player is one side of a networked game, being either a Host or a Client
+type Player interface {
+	// Color returns the color the player plays
+	Color() board.Type
+	// State returns the state of the game, along with a channel closed once it next changes
+	State() (State, <-chan struct{})
+	// Move plays a move, in a column counting from zero, returning once the host has accepted or rejected it
+	Move(ctx context.Context, col int) error
+}
+
+// notifier broadcasts changes to any number of waiting goroutines, by closing a channel and replacing it
+type notifier struct {
+	changed chan struct{}
+}
+
+// wait returns a channel closed by the next call to notify. It must be called with the owner's mutex held
+func (n *notifier) wait() <-chan struct{} {
+	if n.changed == nil {
+		n.changed = make(chan struct{})
+	}
+	return n.changed
+}
+
+// notify closes the channel returned by wait. It must be called with the owner's mutex held
+func (n *notifier) notify() {
+	if n.changed != nil {
+		close(n.changed)
+		n.changed = nil
+	}
+}
+
+// reason returns the reason REJECTED gives for rejecting a move with the given error
+func reason(err error) string {
+	switch {
+	case errors.As(err, new(board.TurnValidityError)):
+		return turnReason
+	case errors.As(err, new(board.FullColumnError)):
+		return fullReason
+	case errors.As(err, new(bitboard.ColumnRangeError)):
+		return columnReason
+	case errors.As(err, &bitboard.GameOverError{}):
+		return overReason
+	default:
+		return syntaxReason
+	}
+}
+
+// rejection returns the error represented by the reason REJECTED gives for rejecting a move
+func rejection(reason, message string, color board.Type, col int) error {
+	switch reason {
+	case turnReason:
+		return board.TurnValidityError(color)
+	case fullReason:
+		return board.FullColumnError(col)
+	case columnReason:
+		return bitboard.ColumnRangeError(col)
+	case overReason:
+		return bitboard.GameOverError{}
+	default:
+		return RejectedError(message)
+	}
+}
+
+// formatMoves formats the moves of a game as given by STATE
+func formatMoves(h board.History) string {
+	if len(h) == 0 {
+		return "-"
+	}
+	return h.Notation()
+}
+
+// parseMoves parses the moves of a game as given by STATE
+func parseMoves(s string) (board.History, error) {
+	if s == "-" {
+		return board.History{}, nil
+	}
+	return board.ParseHistory(s)
+}
+
+// opponent returns the color playing against the given color
+func opponent(t board.Type) board.Type {
+	if t == board.RED {
+		return board.BLUE
+	}
+	return board.RED
+}
+
+// colorName names a color in the protocol
+func colorName(t board.Type) string {
+	if t == board.RED {
+		return "red"
+	}
+	return "blue"
+}
+
+// parseColor parses a color named in the protocol
+func parseColor(s string) (board.Type, bool) {
+	switch s {
+	case "red":
+		return board.RED, true
+	case "blue":
+		return board.BLUE, true
+	}
+	return board.NONE, false
+}
@@ -0,0 +1,69 @@
+package netplay
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+func TestState(t *testing.T) {
+	table := []struct {
+		moves  string
+		turn   board.Type
+		over   bool
+		winner board.Type
+	}{
+		{"", board.RED, false, board.NONE},
+		{"4", board.BLUE, false, board.NONE},
+		{"1212121", board.NONE, true, board.RED},
+		{"12323252", board.NONE, true, board.BLUE},
+	}
+
+	for _, r := range table {
+		h, _ := board.ParseHistory(r.moves)
+		s := State{Moves: h}
+		if s.Turn() != r.turn || s.Over() != r.over || s.Winner() != r.winner {
+			t.Errorf("%q: expected turn %v, over %v and winner %v, observed %v, %v and %v", r.moves, r.turn, r.over,
+				r.winner, s.Turn(), s.Over(), s.Winner())
+		}
+	}
+}
+
+func TestRejection(t *testing.T) {
+	table := []struct {
+		err    error
+		reason string
+		target interface{}
+	}{
+		{fmt.Errorf("wrapped: %w", board.TurnValidityError(board.BLUE)), turnReason, new(board.TurnValidityError)},
+		{board.FullColumnError(2), fullReason, new(board.FullColumnError)},
+		{bitboard.ColumnRangeError(9), columnReason, new(bitboard.ColumnRangeError)},
+		{bitboard.GameOverError{}, overReason, &bitboard.GameOverError{}},
+		{ProtocolError{Line: "MOVE", Reason: "expected a column"}, syntaxReason, new(RejectedError)},
+	}
+
+	for _, r := range table {
+		if got := reason(r.err); got != r.reason {
+			t.Errorf("%v: expected reason %q, observed %q", r.err, r.reason, got)
+		}
+		if err := rejection(r.reason, r.err.Error(), board.BLUE, 2); !errors.As(err, r.target) {
+			t.Errorf("%v: expected rejection to return %T, observed %T", r.err, r.target, err)
+		}
+	}
+}
+
+func TestParseMoves(t *testing.T) {
+	for _, moves := range []string{"", "4", "4453"} {
+		h, _ := board.ParseHistory(moves)
+		got, err := parseMoves(formatMoves(h))
+		if err != nil || got.Notation() != moves {
+			t.Errorf("%q: expected moves to round trip, observed %q: %v", moves, got.Notation(), err)
+		}
+	}
+	if _, err := parseMoves("4!"); err == nil {
+		t.Error("expected error parsing bad notation")
+	}
+}
@@ -0,0 +1,8 @@
+package rare
+
+// ConfigError defines an error used when an estimate is configured incorrectly
+type ConfigError string
+
+func (e ConfigError) Error() string {
+	return string(e)
+}
@@ -0,0 +1,80 @@
+package rare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/simulate"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+// errTooLarge signals that enumeration exceeded its bound on positions
+var errTooLarge = errors.New("game tree exceeds enumeration bound")
+
+// enumerator holds the state of an exact enumeration
+type enumerator struct {
+	ctx  context.Context
+	c    Config
+	rng  *rand.Rand
+	memo map[uint64]float64
+}
+
+// enumerate computes the exact probability of the strategy losing from the starting position
+func enumerate(ctx context.Context, c Config, start bitboard.Position) (Result, error) {
+	e := &enumerator{ctx: ctx, c: c, rng: rand.New(rand.NewSource(0)), memo: map[uint64]float64{}}
+	p, err := e.loss(start)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Method: Exact, Probability: p, Low: p, High: p, Positions: len(e.memo)}, nil
+}
+
+// loss returns the probability of the strategy losing from the given position
+func (e *enumerator) loss(p bitboard.Position) (float64, error) {
+	if p.IsOver() {
+		if w := p.Winner(); w != board.NONE && w != e.c.Color {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	if l, ok := e.memo[p.Key()]; ok {
+		return l, nil
+	}
+	if len(e.memo) >= e.c.MaxPositions {
+		return 0, errTooLarge
+	}
+	if err := e.ctx.Err(); err != nil {
+		return 0, fmt.Errorf("cannot enumerate: %w", err)
+	}
+
+	var l float64
+	if p.Turn() == e.c.Color {
+		m, err := e.c.Strategy.Choose(e.ctx, p, e.rng)
+		if err != nil {
+			return 0, fmt.Errorf("cannot enumerate: %w", err)
+		}
+		if !p.CanPlay(int(m)) {
+			return 0, fmt.Errorf("cannot enumerate: %v chose column %v: %w", strategy.Name(e.c.Strategy),
+				int(m)+1, simulate.IllegalMoveError(m))
+		}
+		if l, err = e.loss(p.Play(int(m))); err != nil {
+			return 0, err
+		}
+	} else {
+		moves := p.LegalMoves()
+		for _, m := range moves {
+			child, err := e.loss(p.Play(int(m)))
+			if err != nil {
+				return 0, err
+			}
+			l += child / float64(len(moves))
+		}
+	}
+
+	e.memo[p.Key()] = l
+	return l, nil
+}
@@ -0,0 +1,148 @@
+// Package rare estimates how often a deterministic strategy loses to a uniformly random opponent, including when
+// losses are far too rare to observe by playing games, as in "1 in 1,000,000 games".
+//
+// Where the game tree is small enough, the probability is computed exactly, by enumerating every position reachable
+// under the strategy's moves and every move of the random opponent, memoized across transpositions. Otherwise, the
+// probability is estimated by importance sampling: the random opponent's moves are drawn from a proposal biased
+// towards a guide strategy's moves, which makes the rare losing games common, and each game is weighted by the ratio
+// of its probability under uniform play to its probability under the proposal, which keeps the estimate unbiased.
+package rare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+// Method is an enumerated type indicating how an estimate was computed
+type Method uint8
+
+// Exact estimates come from enumerating the game tree, and carry no sampling error. ImportanceSampling estimates
+// come from weighted games, and carry a confidence interval
+const (
+	Exact Method = iota
+	ImportanceSampling
+)
+
+func (m Method) String() string {
+	if m == Exact {
+		return "exact"
+	}
+	return "importance sampling"
+}
+
+// Config holds the parameters of an estimate
+type Config struct {
+	// Strategy is the deterministic strategy whose losses are counted. A strategy that draws on its source of
+	// randomness would be treated as though it always made the same choice in a given position
+	Strategy strategy.Strategy
+	// Color sets the color played by the strategy, with the random opponent playing the other
+	Color board.Type
+	// Geometry sets the board size, defaulting to bitboard.Standard if zero
+	Geometry bitboard.Geometry
+	// Start holds the moves leading to the starting position, or nil to start from the empty board
+	Start board.History
+
+	// MaxPositions bounds the number of distinct positions exact enumeration may visit before giving up in favor of
+	// importance sampling, defaulting to one million if zero. A negative bound skips enumeration altogether
+	MaxPositions int
+
+	// Samples sets the number of games played for importance sampling, defaulting to 10,000 if zero
+	Samples int
+	// Guide steers the random opponent's proposal distribution, defaulting to strategy.Greedy if nil
+	Guide strategy.Strategy
+	// Bias sets the probability with which the proposal follows the guide rather than moving uniformly at random,
+	// defaulting to 0.5 if zero. It must be less than one, so every move retains some probability
+	Bias float64
+	// Seed seeds the importance sampling games
+	Seed int64
+	// Confidence sets the confidence level of the reported interval, defaulting to 0.95 if zero
+	Confidence float64
+}
+
+// Result holds an estimate of the probability of the strategy losing
+type Result struct {
+	Method      Method
+	Probability float64
+	Low, High   float64 // Confidence interval, equal to the probability for exact results
+	StdErr      float64 // Standard error of the probability, zero for exact results
+	Positions   int     // Number of distinct positions enumerated, for exact results
+	Samples     int     // Number of games played, for importance sampling results
+	Losses      int     // Number of sampled games lost by the strategy, for importance sampling results
+}
+
+// OneIn expresses the probability as odds, such that a probability of 0.000001 is "1 in 1,000,000" games
+func (r Result) OneIn() float64 {
+	return 1 / r.Probability
+}
+
+func (r Result) String() string {
+	if r.Method == Exact {
+		return fmt.Sprintf("%.6g (1 in %.6g), exact over %v positions", r.Probability, r.OneIn(), r.Positions)
+	}
+	return fmt.Sprintf("%.6g (1 in %.6g) [%.6g, %.6g], by importance sampling over %v games with %v losses",
+		r.Probability, r.OneIn(), r.Low, r.High, r.Samples, r.Losses)
+}
+
+// Estimate returns the probability of the strategy losing to a uniformly random opponent, exactly if the game tree
+// fits within the enumeration bound, and by importance sampling otherwise
+func Estimate(ctx context.Context, c Config) (Result, error) {
+	c, start, err := c.normalize()
+	if err != nil {
+		return Result{}, err
+	}
+
+	if c.MaxPositions > 0 {
+		r, err := enumerate(ctx, c, start)
+		if !errors.Is(err, errTooLarge) {
+			return r, err
+		}
+	}
+	return sample(ctx, c, start)
+}
+
+// normalize fills in defaults, validates the configuration, and returns the starting position
+func (c Config) normalize() (Config, bitboard.Position, error) {
+	if c.Geometry == (bitboard.Geometry{}) {
+		c.Geometry = bitboard.Standard
+	}
+	if c.MaxPositions == 0 {
+		c.MaxPositions = 1000000
+	}
+	if c.Samples == 0 {
+		c.Samples = 10000
+	}
+	if c.Guide == nil {
+		c.Guide = strategy.Greedy{}
+	}
+	if c.Bias == 0 {
+		c.Bias = 0.5
+	}
+	if c.Confidence == 0 {
+		c.Confidence = 0.95
+	}
+
+	switch {
+	case c.Strategy == nil:
+		return c, bitboard.Position{}, fmt.Errorf("cannot estimate: %w", ConfigError("strategy must be set"))
+	case c.Color != board.RED && c.Color != board.BLUE:
+		return c, bitboard.Position{}, fmt.Errorf("cannot estimate: %w", ConfigError("color must be RED or BLUE"))
+	case c.Samples < 0:
+		return c, bitboard.Position{}, fmt.Errorf("cannot estimate: %w", ConfigError("samples must not be negative"))
+	case c.Bias < 0 || c.Bias >= 1:
+		return c, bitboard.Position{}, fmt.Errorf("cannot estimate: %w", ConfigError("bias must be in [0, 1)"))
+	case c.Confidence <= 0 || c.Confidence >= 1:
+		return c, bitboard.Position{}, fmt.Errorf("cannot estimate: %w",
+			ConfigError("confidence must be between 0 and 1"))
+	}
+
+	start, err := bitboard.FromHistory(c.Geometry, c.Start)
+	if err != nil {
+		return c, bitboard.Position{}, fmt.Errorf("cannot estimate from starting moves: %w", err)
+	}
+	return c, start, nil
+}
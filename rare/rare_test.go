@@ -0,0 +1,221 @@
+package rare
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/simulate"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+// leftmost plays the leftmost playable column, a deterministic strategy that loses often enough to measure
+type leftmost struct{}
+
+func (leftmost) Choose(_ context.Context, p bitboard.Position, _ *rand.Rand) (board.Move, error) {
+	return p.LegalMoves()[0], nil
+}
+
+// careful wins when it can, blocks when it must, and otherwise plays the leftmost column, losing only rarely
+type careful struct{}
+
+func (careful) Choose(_ context.Context, p bitboard.Position, _ *rand.Rand) (board.Move, error) {
+	moves := p.LegalMoves()
+	for _, m := range moves {
+		if p.IsWinningMove(int(m)) {
+			return m, nil
+		}
+	}
+	nonLosing := p.NonLosingMoves()
+	for _, m := range moves {
+		if nonLosing&p.ColumnMask(int(m)) != 0 {
+			return m, nil
+		}
+	}
+	return moves[0], nil
+}
+
+// illegal always chooses a column off the board
+type illegal struct{}
+
+func (illegal) Choose(context.Context, bitboard.Position, *rand.Rand) (board.Move, error) {
+	return 99, nil
+}
+
+var small = bitboard.Geometry{Width: 4, Height: 4}
+
+// reference computes the probability of the strategy losing by brute force, without memoization
+func reference(t *testing.T, s strategy.Strategy, color board.Type, p bitboard.Position) float64 {
+	if p.IsOver() {
+		if w := p.Winner(); w != board.NONE && w != color {
+			return 1
+		}
+		return 0
+	}
+	if p.Turn() == color {
+		m, err := s.Choose(context.Background(), p, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return reference(t, s, color, p.Play(int(m)))
+	}
+	var l float64
+	moves := p.LegalMoves()
+	for _, m := range moves {
+		l += reference(t, s, color, p.Play(int(m))) / float64(len(moves))
+	}
+	return l
+}
+
+func TestEstimate_exact(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy strategy.Strategy
+		color    board.Type
+		start    board.History
+	}{
+		{"leftmost as red", leftmost{}, board.RED, nil},
+		{"leftmost as blue", leftmost{}, board.BLUE, nil},
+		{"careful as red", careful{}, board.RED, nil},
+		{"careful as blue from a start", careful{}, board.BLUE, board.History{1, 2}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := Estimate(context.Background(), Config{Strategy: test.strategy, Color: test.color,
+				Geometry: small, Start: test.start})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if r.Method != Exact || r.Positions == 0 {
+				t.Fatalf("expected an exact result over some positions, observed %v", r)
+			}
+
+			start, _ := bitboard.FromHistory(small, test.start)
+			expected := reference(t, test.strategy, test.color, start)
+			if math.Abs(r.Probability-expected) > 1e-12 || r.Low != r.Probability || r.High != r.Probability {
+				t.Errorf("expected probability %v, observed %v", expected, r)
+			}
+		})
+	}
+}
+
+// TestEstimate_sampling asserts that importance sampling agrees with exact enumeration, across biases
+func TestEstimate_sampling(t *testing.T) {
+	exact, err := Estimate(context.Background(), Config{Strategy: careful{}, Color: board.RED, Geometry: small})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, bias := range []float64{0.2, 0.5, 0.8} {
+		r, err := Estimate(context.Background(), Config{Strategy: careful{}, Color: board.RED, Geometry: small,
+			MaxPositions: -1, Samples: 4000, Bias: bias, Seed: 3, Confidence: 0.999})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if r.Method != ImportanceSampling || r.Samples != 4000 || r.Positions != 0 {
+			t.Fatalf("expected importance sampling over 4000 games, observed %v", r)
+		}
+		if r.Low > exact.Probability || exact.Probability > r.High {
+			t.Errorf("with bias %v, interval %v should contain the exact probability %v", bias, r, exact.Probability)
+		}
+	}
+}
+
+// TestEstimate_rare asserts that importance sampling observes losses far more often than they occur, while its
+// estimate agrees with plain Monte Carlo simulation
+func TestEstimate_rare(t *testing.T) {
+	c := Config{Strategy: careful{}, Color: board.RED, Geometry: small, MaxPositions: -1, Samples: 2000,
+		Bias: 0.8, Seed: 5}
+	r, err := Estimate(context.Background(), c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	games := 2000
+	mc, err := simulate.Run(context.Background(), simulate.Config{Games: games, Red: careful{},
+		Blue: strategy.Random{}, Geometry: small, Seed: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if float64(r.Losses)/float64(r.Samples) <= float64(mc.Blue)/float64(games) {
+		t.Errorf("importance sampling lost %v of %v games, no more often than plain simulation's %v of %v",
+			r.Losses, r.Samples, mc.Blue, games)
+	}
+	if r.Low > mc.BlueRate.High || mc.BlueRate.Low > r.High {
+		t.Errorf("interval %v should overlap the simulated loss rate %v", r, mc.BlueRate)
+	}
+}
+
+// TestEstimate_fallback asserts that a game tree exceeding the enumeration bound is sampled instead
+func TestEstimate_fallback(t *testing.T) {
+	r, err := Estimate(context.Background(), Config{Strategy: leftmost{}, Color: board.RED, Geometry: small,
+		MaxPositions: 10, Samples: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.Method != ImportanceSampling || r.Samples != 100 {
+		t.Errorf("expected importance sampling over 100 games, observed %v", r)
+	}
+}
+
+// TestEstimate_deterministic asserts that sampling is reproducible from its seed
+func TestEstimate_deterministic(t *testing.T) {
+	c := Config{Strategy: careful{}, Color: board.BLUE, Geometry: small, MaxPositions: -1, Samples: 300, Seed: 9}
+	r1, _ := Estimate(context.Background(), c)
+	r2, _ := Estimate(context.Background(), c)
+	if r1 != r2 {
+		t.Errorf("expected identical results from the same seed, observed %v and %v", r1, r2)
+	}
+}
+
+func TestEstimate_errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		target interface{}
+	}{
+		{"no strategy", Config{Color: board.RED}, new(ConfigError)},
+		{"no color", Config{Strategy: leftmost{}}, new(ConfigError)},
+		{"negative samples", Config{Strategy: leftmost{}, Color: board.RED, Samples: -1}, new(ConfigError)},
+		{"bias of one", Config{Strategy: leftmost{}, Color: board.RED, Bias: 1}, new(ConfigError)},
+		{"confidence of one", Config{Strategy: leftmost{}, Color: board.RED, Confidence: 1}, new(ConfigError)},
+		{"illegal start", Config{Strategy: leftmost{}, Color: board.RED, Geometry: small, Start: board.History{9}},
+			new(bitboard.ColumnRangeError)},
+		{"illegal move when enumerating", Config{Strategy: illegal{}, Color: board.RED, Geometry: small},
+			new(simulate.IllegalMoveError)},
+		{"illegal move when sampling", Config{Strategy: illegal{}, Color: board.RED, Geometry: small,
+			MaxPositions: -1}, new(simulate.IllegalMoveError)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Estimate(context.Background(), test.config)
+			if !errors.As(err, test.target) {
+				t.Errorf("expected error of type %T, observed %v", test.target, err)
+			}
+		})
+	}
+}
+
+func TestEstimate_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, max := range []int{0, -1} {
+		_, err := Estimate(ctx, Config{Strategy: leftmost{}, Color: board.RED, Geometry: small, MaxPositions: max})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected cancellation with bound %v, observed %v", max, err)
+		}
+	}
+}
+
+func TestResult_OneIn(t *testing.T) {
+	r := Result{Probability: 0.000001}
+	if math.Abs(r.OneIn()-1000000) > 1e-6 {
+		t.Errorf("expected 1 in 1000000, observed 1 in %v", r.OneIn())
+	}
+}
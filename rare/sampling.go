@@ -0,0 +1,92 @@
+package rare
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/simulate"
+	"github.com/talglobus/fearsome/stats"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+// sample estimates the probability of the strategy losing from the starting position by importance sampling
+func sample(ctx context.Context, c Config, start bitboard.Position) (Result, error) {
+	r := Result{Method: ImportanceSampling, Samples: c.Samples}
+	var sum, sumSquares float64
+	for i := 0; i < c.Samples; i++ {
+		w, err := game(ctx, c, start, rand.New(rand.NewSource(simulate.GameSeed(c.Seed, i))))
+		if err != nil {
+			return Result{}, fmt.Errorf("cannot sample game %v: %w", i, err)
+		}
+		if w > 0 {
+			r.Losses++
+		}
+		sum += w
+		sumSquares += w * w
+	}
+	if c.Samples == 0 {
+		return r, nil
+	}
+
+	n := float64(c.Samples)
+	r.Probability = sum / n
+	if c.Samples > 1 {
+		variance := math.Max(0, (sumSquares-n*r.Probability*r.Probability)/(n-1))
+		r.StdErr = math.Sqrt(variance / n)
+	}
+	margin := stats.Z(c.Confidence) * r.StdErr
+	r.Low, r.High = math.Max(0, r.Probability-margin), math.Min(1, r.Probability+margin)
+	return r, nil
+}
+
+// game plays a single game with the opponent drawing moves from the proposal, returning the likelihood ratio of the
+// game if the strategy lost, and zero otherwise
+func game(ctx context.Context, c Config, p bitboard.Position, rng *rand.Rand) (float64, error) {
+	weight := 1.0
+	for !p.IsOver() {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		if p.Turn() == c.Color {
+			m, err := c.Strategy.Choose(ctx, p, rng)
+			if err != nil {
+				return 0, err
+			}
+			if !p.CanPlay(int(m)) {
+				return 0, fmt.Errorf("%v chose column %v: %w", strategy.Name(c.Strategy), int(m)+1,
+					simulate.IllegalMoveError(m))
+			}
+			p = p.Play(int(m))
+			continue
+		}
+
+		guide, err := c.Guide.Choose(ctx, p, rng)
+		if err != nil {
+			return 0, err
+		}
+		moves := p.LegalMoves()
+		m := guide
+		if rng.Float64() >= c.Bias {
+			m = moves[rng.Intn(len(moves))]
+		}
+
+		// Uniform play chooses each move with probability 1/k, where the proposal gives the guide's move a boost
+		k := float64(len(moves))
+		q := (1 - c.Bias) / k
+		if m == guide {
+			q += c.Bias
+		}
+		weight *= (1 / k) / q
+		p = p.Play(int(m))
+	}
+
+	if w := p.Winner(); w != board.NONE && w != c.Color {
+		return weight, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,91 @@
+// Package rating fits ratings to the results of games between players: Elo ratings by maximum likelihood under the
+// Bradley-Terry model, with standard errors, and Glicko-2 ratings, which track each player's uncertainty and
+// volatility explicitly. Draws count as half a win throughout, and colors are ignored.
+package rating
+
+import "math"
+
+// Outcome holds the results of the games between two players, each identified by an index, from the perspective of
+// player A
+type Outcome struct {
+	A, B                int
+	Wins, Draws, Losses int
+}
+
+// Games returns the number of games between the players
+func (o Outcome) Games() int {
+	return o.Wins + o.Draws + o.Losses
+}
+
+// Estimate holds an Elo rating along with its standard error
+type Estimate struct {
+	Rating, Error float64
+}
+
+// eloScale converts natural log-strengths to Elo points
+const eloScale = 400 / math.Ln10
+
+// Elo returns the Elo rating of each player, centered on zero, fitted by maximum likelihood to the outcomes under the
+// Bradley-Terry model. Each player is credited with two virtual draws against a player of rating zero, which keep the
+// ratings of players who win or lose every game finite. Standard errors are taken from the diagonal of the Fisher
+// information, and so neglect the correlation between ratings
+func Elo(players int, outcomes []Outcome) []Estimate {
+	// wins holds the points scored by each player, and games the number of games between each pair of players
+	wins := make([]float64, players)
+	games := make([][]float64, players)
+	for i := range games {
+		games[i] = make([]float64, players)
+		wins[i] = 1
+	}
+	for _, o := range outcomes {
+		wins[o.A] += float64(o.Wins) + float64(o.Draws)/2
+		wins[o.B] += float64(o.Losses) + float64(o.Draws)/2
+		games[o.A][o.B] += float64(o.Games())
+		games[o.B][o.A] += float64(o.Games())
+	}
+
+	// Iterate the minorization-maximization update until the strengths settle
+	strength := make([]float64, players)
+	for i := range strength {
+		strength[i] = 1
+	}
+	next := make([]float64, players)
+	for iteration := 0; iteration < 10000; iteration++ {
+		change := 0.0
+		for i := range strength {
+			// The two virtual games are against a player of strength one
+			denominator := 2 / (strength[i] + 1)
+			for j := range strength {
+				if games[i][j] > 0 {
+					denominator += games[i][j] / (strength[i] + strength[j])
+				}
+			}
+			next[i] = wins[i] / denominator
+			change = math.Max(change, math.Abs(math.Log(next[i]/strength[i])))
+		}
+		strength, next = next, strength
+		if change < 1e-10 {
+			break
+		}
+	}
+
+	estimates := make([]Estimate, players)
+	mean := 0.0
+	for i, s := range strength {
+		information := 2 * s / ((s + 1) * (s + 1))
+		for j, t := range strength {
+			information += games[i][j] * s * t / ((s + t) * (s + t))
+		}
+		estimates[i] = Estimate{Rating: eloScale * math.Log(s), Error: eloScale / math.Sqrt(information)}
+		mean += estimates[i].Rating / float64(players)
+	}
+	for i := range estimates {
+		estimates[i].Rating -= mean
+	}
+	return estimates
+}
+
+// Expected returns the expected score of a player rated the given number of Elo points above its opponent
+func Expected(difference float64) float64 {
+	return 1 / (1 + math.Pow(10, -difference/400))
+}
@@ -0,0 +1,55 @@
+package rating
+
+import (
+	"math"
+	"testing"
+)
+
+func TestElo(t *testing.T) {
+	// A player scoring 75% against another should be rated about 191 Elo higher, less the pull of the virtual draws
+	e := Elo(2, []Outcome{{A: 0, B: 1, Wins: 750, Losses: 250}})
+	if d := e[0].Rating - e[1].Rating; d < 180 || d > 191 {
+		t.Errorf("expected a difference of about 191 Elo, observed %v", d)
+	}
+	if math.Abs(e[0].Rating+e[1].Rating) > 1e-9 {
+		t.Errorf("expected ratings centered on zero, observed %v", e)
+	}
+
+	// Quadrupling the games should about halve the standard error
+	e4 := Elo(2, []Outcome{{A: 0, B: 1, Wins: 3000, Losses: 1000}})
+	if ratio := e[0].Error / e4[0].Error; math.Abs(ratio-2) > 0.05 {
+		t.Errorf("expected the error to halve, observed %v and %v", e[0].Error, e4[0].Error)
+	}
+}
+
+func TestElo_transitive(t *testing.T) {
+	// Each player beats the next 75% of the time, so the ratings should be evenly spaced
+	outcomes := []Outcome{
+		{A: 0, B: 1, Wins: 300, Losses: 100},
+		{A: 1, B: 2, Wins: 300, Losses: 100},
+		{A: 0, B: 2, Wins: 360, Losses: 40},
+	}
+	e := Elo(3, outcomes)
+	if e[0].Rating <= e[1].Rating || e[1].Rating <= e[2].Rating {
+		t.Fatalf("expected decreasing ratings, observed %v", e)
+	}
+	if math.Abs((e[0].Rating-e[1].Rating)-(e[1].Rating-e[2].Rating)) > 10 {
+		t.Errorf("expected evenly spaced ratings, observed %v", e)
+	}
+}
+
+func TestElo_sweep(t *testing.T) {
+	// A player winning every game still gets a finite rating
+	e := Elo(2, []Outcome{{A: 0, B: 1, Wins: 10}})
+	if math.IsInf(e[0].Rating, 0) || math.IsNaN(e[0].Rating) || e[0].Rating <= e[1].Rating {
+		t.Errorf("expected finite ratings in favor of the winner, observed %v", e)
+	}
+}
+
+func TestExpected(t *testing.T) {
+	for _, r := range []struct{ difference, want float64 }{{0, 0.5}, {400, 10.0 / 11}, {-400, 1.0 / 11}} {
+		if got := Expected(r.difference); math.Abs(got-r.want) > 1e-12 {
+			t.Errorf("expected score %v at %v, observed %v", r.want, r.difference, got)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package rating
+
+import "math"
+
+// Glicko holds a Glicko-2 rating, on the familiar Glicko scale
+type Glicko struct {
+	Rating     float64 // Rating, comparable to Elo, of 1500 for an unrated player
+	Deviation  float64 // Uncertainty of the rating, as a standard deviation, of 350 for an unrated player
+	Volatility float64 // Expected fluctuation of the rating between rating periods
+}
+
+// Unrated is the Glicko-2 rating of a player with no games
+var Unrated = Glicko{Rating: 1500, Deviation: 350, Volatility: 0.06}
+
+// DefaultTau constrains the change in volatility between rating periods, as recommended for most uses
+const DefaultTau = 0.5
+
+// glickoScale converts between the Glicko scale and the internal Glicko-2 scale
+const glickoScale = 173.7178
+
+// Game holds the result of a single game against an opponent, scored as one for a win, a half for a draw, and zero
+// for a loss
+type Game struct {
+	Opponent Glicko
+	Score    float64
+}
+
+// Update returns the rating after a rating period in which the games were played, by the Glicko-2 algorithm, with
+// tau constraining the change in volatility. A player without games only grows more uncertain
+func (g Glicko) Update(games []Game, tau float64) Glicko {
+	mu, phi, sigma := (g.Rating-1500)/glickoScale, g.Deviation/glickoScale, g.Volatility
+	if len(games) == 0 {
+		return Glicko{Rating: g.Rating, Deviation: glickoScale * math.Sqrt(phi*phi+sigma*sigma), Volatility: sigma}
+	}
+
+	// Estimate the variance of the rating from the games alone, and the improvement the results suggest
+	var vInverse, sum float64
+	for _, game := range games {
+		muJ, phiJ := (game.Opponent.Rating-1500)/glickoScale, game.Opponent.Deviation/glickoScale
+		gPhi := 1 / math.Sqrt(1+3*phiJ*phiJ/(math.Pi*math.Pi))
+		e := 1 / (1 + math.Exp(-gPhi*(mu-muJ)))
+		vInverse += gPhi * gPhi * e * (1 - e)
+		sum += gPhi * (game.Score - e)
+	}
+	v := 1 / vInverse
+	delta := v * sum
+
+	// Find the new volatility by the Illinois algorithm
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-d)/(2*d*d) - (x-a)/(tau*tau)
+	}
+	A, B := a, 0.0
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+		B = a - k*tau
+	}
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > 1e-6 {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+	sigma = math.Exp(A / 2)
+
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phi = 1 / math.Sqrt(1/(phiStar*phiStar)+vInverse)
+	mu += phi * phi * sum
+	return Glicko{Rating: glickoScale*mu + 1500, Deviation: glickoScale * phi, Volatility: sigma}
+}
+
+// Glicko2 returns the Glicko-2 rating of each player after successive rating periods, each comprising some of the
+// outcomes, with every player starting unrated. Within a period, each player is rated against its opponents' ratings
+// from the end of the previous period
+func Glicko2(players int, periods [][]Outcome, tau float64) []Glicko {
+	ratings := make([]Glicko, players)
+	for i := range ratings {
+		ratings[i] = Unrated
+	}
+
+	for _, outcomes := range periods {
+		games := make([][]Game, players)
+		for _, o := range outcomes {
+			for _, r := range []struct {
+				score float64
+				n     int
+			}{{1, o.Wins}, {0.5, o.Draws}, {0, o.Losses}} {
+				for k := 0; k < r.n; k++ {
+					games[o.A] = append(games[o.A], Game{Opponent: ratings[o.B], Score: r.score})
+					games[o.B] = append(games[o.B], Game{Opponent: ratings[o.A], Score: 1 - r.score})
+				}
+			}
+		}
+
+		next := make([]Glicko, players)
+		for i := range ratings {
+			next[i] = ratings[i].Update(games[i], tau)
+		}
+		ratings = next
+	}
+	return ratings
+}
@@ -0,0 +1,46 @@
+package rating
+
+import (
+	"math"
+	"testing"
+)
+
+// TestGlicko_Update reproduces the worked example of Glickman's description of the Glicko-2 system
+func TestGlicko_Update(t *testing.T) {
+	player := Glicko{Rating: 1500, Deviation: 200, Volatility: 0.06}
+	games := []Game{
+		{Opponent: Glicko{Rating: 1400, Deviation: 30}, Score: 1},
+		{Opponent: Glicko{Rating: 1550, Deviation: 100}, Score: 0},
+		{Opponent: Glicko{Rating: 1700, Deviation: 300}, Score: 0},
+	}
+
+	got := player.Update(games, 0.5)
+	if math.Abs(got.Rating-1464.06) > 0.01 || math.Abs(got.Deviation-151.52) > 0.01 ||
+		math.Abs(got.Volatility-0.05999) > 0.00001 {
+		t.Errorf("expected 1464.06, 151.52, 0.05999, observed %+v", got)
+	}
+}
+
+func TestGlicko_Update_idle(t *testing.T) {
+	got := Unrated.Update(nil, DefaultTau)
+	if got.Rating != Unrated.Rating || got.Deviation <= Unrated.Deviation {
+		t.Errorf("expected an idle player to grow more uncertain, observed %+v", got)
+	}
+}
+
+func TestGlicko2(t *testing.T) {
+	periods := make([][]Outcome, 20)
+	for i := range periods {
+		periods[i] = []Outcome{{A: 0, B: 1, Wins: 3, Losses: 1}, {A: 1, B: 2, Wins: 3, Losses: 1},
+			{A: 0, B: 2, Wins: 4}}
+	}
+	g := Glicko2(3, periods, DefaultTau)
+	if g[0].Rating <= g[1].Rating || g[1].Rating <= g[2].Rating {
+		t.Errorf("expected decreasing ratings, observed %+v", g)
+	}
+	for _, r := range g {
+		if r.Deviation >= Unrated.Deviation/2 {
+			t.Errorf("expected deviations to shrink with games, observed %+v", g)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package record
+
+import "fmt"
+
+// ResultError defines an error used when encoding a result that isn't one of the enumerated results
+type ResultError Result
+
+func (e ResultError) Error() string {
+	return fmt.Sprintf("unknown result %v", uint8(e))
+}
+
+// MismatchError defines an error used when the result recorded for a game isn't the result of its moves
+type MismatchError struct {
+	Recorded, Actual Result
+}
+
+func (e MismatchError) Error() string {
+	return fmt.Sprintf("recorded result %v doesn't match result %v of moves", e.Recorded, e.Actual)
+}
+
+// LineError defines an error used when a line of input can't be decoded as a record
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e LineError) Error() string {
+	return fmt.Sprintf("line %v: %v", e.Line, e.Err)
+}
+
+func (e LineError) Unwrap() error {
+	return e.Err
+}
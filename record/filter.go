@@ -0,0 +1,118 @@
+package record
+
+import (
+	"fmt"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+// Filter selects records by their result, their length, their opening, and the positions they pass through. Moves
+// are counted from the empty board, including any starting moves. The zero Filter selects every record
+type Filter struct {
+	Results   []Result      // Results to select, or every result if empty
+	MinLength int           // Least number of moves, or no bound if zero
+	MaxLength int           // Greatest number of moves, or no bound if zero
+	Prefix    board.History // Moves with which every selected game opens
+	Reaches   board.History // Moves leading to a position every selected game passes through, by any move order
+	Mirrored  bool          // Whether Prefix and Reaches also select games matching their mirror images
+}
+
+// Match returns whether the filter selects the record, or an error if the record's moves are illegal
+func (f Filter) Match(r Record) (bool, error) {
+	p, err := r.Position()
+	if err != nil {
+		return false, err
+	}
+	h := r.History()
+
+	if len(f.Results) > 0 && !containsResult(f.Results, r.Result) {
+		return false, nil
+	}
+	if len(h) < f.MinLength || (f.MaxLength > 0 && len(h) > f.MaxLength) {
+		return false, nil
+	}
+
+	g := p.Geometry()
+	if len(f.Prefix) > 0 {
+		if len(h) < len(f.Prefix) {
+			return false, nil
+		}
+		opening := h[:len(f.Prefix)]
+		if !opening.Equals(f.Prefix) && !(f.Mirrored && opening.Equals(mirror(g, f.Prefix))) {
+			return false, nil
+		}
+	}
+
+	if len(f.Reaches) > 0 {
+		if len(h) < len(f.Reaches) {
+			return false, nil
+		}
+		target, err := bitboard.FromHistory(g, f.Reaches)
+		if err != nil {
+			// The position can't arise in games of the record's geometry
+			return false, nil
+		}
+		reached, err := bitboard.FromHistory(g, h[:len(f.Reaches)])
+		if err != nil {
+			return false, fmt.Errorf("cannot replay record: %w", err)
+		}
+		if reached.Key() != target.Key() && !(f.Mirrored && reached.Key() == target.MirrorKey()) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// containsResult returns whether the results include the given result
+func containsResult(results []Result, r Result) bool {
+	for _, result := range results {
+		if result == r {
+			return true
+		}
+	}
+	return false
+}
+
+// mirror returns moves reflected across the central column of the geometry
+func mirror(g bitboard.Geometry, h board.History) board.History {
+	m := make(board.History, len(h))
+	for i, move := range h {
+		m[i] = board.Move(g.Width-1) - move
+	}
+	return m
+}
+
+// Deduplicator recognizes records whose games end in a position already seen
+type Deduplicator struct {
+	symmetric bool
+	seen      map[bitboard.Geometry]map[uint64]bool
+}
+
+// NewDeduplicator constructs a Deduplicator. If symmetric, a position and its mirror image are considered the same
+func NewDeduplicator(symmetric bool) *Deduplicator {
+	return &Deduplicator{symmetric: symmetric, seen: map[bitboard.Geometry]map[uint64]bool{}}
+}
+
+// Add records the final position of the record's game, returning whether it's the first game to end there
+func (d *Deduplicator) Add(r Record) (bool, error) {
+	p, err := r.Position()
+	if err != nil {
+		return false, err
+	}
+
+	key := p.Key()
+	if m := p.MirrorKey(); d.symmetric && m < key {
+		key = m
+	}
+	seen := d.seen[p.Geometry()]
+	if seen == nil {
+		seen = map[uint64]bool{}
+		d.seen[p.Geometry()] = seen
+	}
+	if seen[key] {
+		return false, nil
+	}
+	seen[key] = true
+	return true, nil
+}
@@ -0,0 +1,88 @@
+package record
+
+import (
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+// game constructs the record of a game from moves in notation, panicking if the moves are invalid
+func game(notation string) Record {
+	h, err := board.ParseHistory(notation)
+	if err != nil {
+		panic(err)
+	}
+	r := Record{Geometry: bitboard.Standard, Moves: h}
+	if err := r.fillResult(); err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func TestFilter_Match(t *testing.T) {
+	// RED wins in column 1 after opening 4, 4, while BLUE wins in column 4 after opening 1, 4
+	redWin, blueWin := game("441212121"), game("14747474")
+	table := []struct {
+		name   string
+		filter Filter
+		record Record
+		want   bool
+	}{
+		{"zero filter", Filter{}, redWin, true},
+		{"result", Filter{Results: []Result{RedWin, Draw}}, redWin, true},
+		{"other result", Filter{Results: []Result{BlueWin}}, redWin, false},
+		{"min length", Filter{MinLength: 10}, redWin, false},
+		{"max length", Filter{MaxLength: 9}, redWin, true},
+		{"too long", Filter{MaxLength: 8}, redWin, false},
+		{"prefix", Filter{Prefix: board.History{3, 3}}, redWin, true},
+		{"other prefix", Filter{Prefix: board.History{3, 2}}, redWin, false},
+		{"mirrored prefix", Filter{Prefix: board.History{6, 3}}, blueWin, false},
+		{"mirrored prefix allowed", Filter{Prefix: board.History{6, 3}, Mirrored: true}, blueWin, true},
+		{"long prefix", Filter{Prefix: board.History{3, 3, 0, 1, 0, 1, 0, 1, 0, 1}}, redWin, false},
+		{"reaches by transposition", Filter{Reaches: board.History{0, 1, 3, 3}}, redWin, true},
+		{"doesn't reach", Filter{Reaches: board.History{0, 1, 3, 2}}, redWin, false},
+		{"reaches mirror image", Filter{Reaches: board.History{6, 5, 3, 3}}, redWin, false},
+		{"reaches mirror image allowed", Filter{Reaches: board.History{6, 5, 3, 3}, Mirrored: true}, redWin, true},
+		{"reaches an impossible position", Filter{Reaches: board.History{8}}, redWin, false},
+	}
+
+	for _, r := range table {
+		got, err := r.filter.Match(r.record)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", r.name, err)
+		} else if got != r.want {
+			t.Errorf("%v: expected %v, observed %v", r.name, r.want, got)
+		}
+	}
+
+	if _, err := (Filter{}).Match(Record{Moves: board.History{9}}); err == nil {
+		t.Errorf("expected an error matching a record of illegal moves")
+	}
+}
+
+func TestDeduplicator(t *testing.T) {
+	table := []struct {
+		symmetric bool
+		games     []Record
+		want      []bool
+	}{
+		// Transpositions reach the same final position, whether or not symmetry counts
+		{false, []Record{game("441212121"), game("124412121"), game("441212121")}, []bool{true, false, false}},
+		// Mirror images only count as the same position if symmetric
+		{false, []Record{game("441212121"), game("447676767")}, []bool{true, true}},
+		{true, []Record{game("441212121"), game("447676767")}, []bool{true, false}},
+		// Positions of different geometries are never the same
+		{true, []Record{{Geometry: bitboard.Standard, Moves: board.History{0}},
+			{Geometry: bitboard.Geometry{Width: 6, Height: 6}, Moves: board.History{0}}}, []bool{true, true}},
+	}
+
+	for i, r := range table {
+		d := NewDeduplicator(r.symmetric)
+		for j, g := range r.games {
+			if got, err := d.Add(g); err != nil || got != r.want[j] {
+				t.Errorf("case %v, game %v: expected %v, observed %v with error %v", i, j, r.want[j], got, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package record
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+// csvColumns names the fields of a record, in the order in which they're written as CSV
+var csvColumns = []string{"event", "index", "red", "blue", "geometry", "start", "moves", "result", "seed"}
+
+// CSVWriter writes records as CSV, with a header row, moves in notation, and the geometry as columns x rows
+type CSVWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+// NewCSVWriter returns a writer of records to w as CSV. Writes are buffered until Flush is called
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+// Write writes a single record as a row, preceded by the header row if it's the first
+func (w *CSVWriter) Write(r Record) error {
+	if !w.header {
+		w.header = true
+		if err := w.w.Write(csvColumns); err != nil {
+			return fmt.Errorf("cannot write record: %w", err)
+		}
+	}
+
+	g := r.Geometry
+	if g == (bitboard.Geometry{}) {
+		g = bitboard.Standard
+	}
+	seed := ""
+	if r.Seed != 0 {
+		seed = strconv.FormatInt(r.Seed, 10)
+	}
+	if err := w.w.Write([]string{r.Event, strconv.Itoa(r.Index), r.Red, r.Blue, g.String(), r.Start.Notation(),
+		r.Moves.Notation(), r.Result.String(), seed}); err != nil {
+		return fmt.Errorf("cannot write record: %w", err)
+	}
+	return nil
+}
+
+// Flush writes any buffered rows
+func (w *CSVWriter) Flush() error {
+	w.w.Flush()
+	if err := w.w.Error(); err != nil {
+		return fmt.Errorf("cannot write record: %w", err)
+	}
+	return nil
+}
+
+// CSVReader reads records from CSV written by CSVWriter. Columns may appear in any order, as named by the header row,
+// and only the moves column is required
+type CSVReader struct {
+	r       *csv.Reader
+	columns map[string]int
+	line    int
+}
+
+// NewCSVReader returns a reader of records from CSV read from r
+func NewCSVReader(r io.Reader) *CSVReader {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	return &CSVReader{r: cr}
+}
+
+// Read returns the next record, or io.EOF once every record has been read
+func (r *CSVReader) Read() (Record, error) {
+	if r.columns == nil {
+		header, err := r.r.Read()
+		if err == io.EOF {
+			return Record{}, io.EOF
+		} else if err != nil {
+			return Record{}, fmt.Errorf("cannot read record: %w", err)
+		}
+		r.line++
+		r.columns = map[string]int{}
+		for i, name := range header {
+			r.columns[strings.TrimSpace(name)] = i
+		}
+		if _, ok := r.columns["moves"]; !ok {
+			return Record{}, fmt.Errorf("cannot read record: %w", LineError{Line: r.line,
+				Err: errors.New("header has no moves column")})
+		}
+	}
+
+	row, err := r.r.Read()
+	if err == io.EOF {
+		return Record{}, io.EOF
+	} else if err != nil {
+		return Record{}, fmt.Errorf("cannot read record: %w", err)
+	}
+	r.line++
+	rec, err := r.parse(row)
+	if err != nil {
+		return Record{}, fmt.Errorf("cannot read record: %w", LineError{Line: r.line, Err: err})
+	}
+	return rec, nil
+}
+
+// parse decodes a single row into a record, filling in the result from the moves if the row doesn't record it
+func (r *CSVReader) parse(row []string) (Record, error) {
+	field := func(name string) string {
+		if i, ok := r.columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	rec := Record{Event: field("event"), Red: field("red"), Blue: field("blue"), Geometry: bitboard.Standard}
+	var err error
+	if s := field("index"); s != "" {
+		if rec.Index, err = strconv.Atoi(s); err != nil {
+			return rec, fmt.Errorf("invalid index %q", s)
+		}
+	}
+	if s := field("geometry"); s != "" {
+		if rec.Geometry, err = bitboard.ParseGeometry(s); err != nil {
+			return rec, err
+		}
+	}
+	if rec.Start, err = board.ParseHistory(field("start")); err != nil {
+		return rec, err
+	}
+	if rec.Moves, err = board.ParseHistory(field("moves")); err != nil {
+		return rec, err
+	}
+	if s := field("seed"); s != "" {
+		if rec.Seed, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return rec, fmt.Errorf("invalid seed %q", s)
+		}
+	}
+	if s := field("result"); s != "" {
+		err = rec.Result.UnmarshalText([]byte(s))
+	} else {
+		err = rec.fillResult()
+	}
+	return rec, err
+}
+
+// fillResult sets the result of the record to that of its moves
+func (r *Record) fillResult() error {
+	p, err := r.Position()
+	if err != nil {
+		return err
+	}
+	r.Result = ResultOf(p)
+	return nil
+}
+
+// MovesWriter writes records as lines of move notation from the empty board, the most compact format, but one which
+// keeps nothing but the moves
+type MovesWriter struct {
+	w io.Writer
+}
+
+// NewMovesWriter returns a writer of records to w as move notation
+func NewMovesWriter(w io.Writer) *MovesWriter {
+	return &MovesWriter{w: w}
+}
+
+// Write writes the moves of a single record as a line
+func (w *MovesWriter) Write(r Record) error {
+	if _, err := io.WriteString(w.w, r.History().Notation()+"\n"); err != nil {
+		return fmt.Errorf("cannot write record: %w", err)
+	}
+	return nil
+}
+
+// MovesReader reads records from lines of move notation, skipping blank lines. Each record is indexed by its order
+// in the input, and its result is found by replaying its moves
+type MovesReader struct {
+	scanner  *bufio.Scanner
+	geometry bitboard.Geometry
+	line     int
+	index    int
+}
+
+// NewMovesReader returns a reader of records of games of the given geometry from move notation read from r
+func NewMovesReader(r io.Reader, g bitboard.Geometry) *MovesReader {
+	return &MovesReader{scanner: bufio.NewScanner(r), geometry: g}
+}
+
+// Read returns the next record, or io.EOF once every record has been read
+func (r *MovesReader) Read() (Record, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		rec := Record{Index: r.index, Geometry: r.geometry}
+		var err error
+		if rec.Moves, err = board.ParseHistory(line); err == nil {
+			err = rec.fillResult()
+		}
+		if err != nil {
+			return Record{}, fmt.Errorf("cannot read record: %w", LineError{Line: r.line, Err: err})
+		}
+		r.index++
+		return rec, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return Record{}, fmt.Errorf("cannot read record: %w", err)
+	}
+	return Record{}, io.EOF
+}
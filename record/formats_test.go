@@ -0,0 +1,104 @@
+package record
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+func TestCSV(t *testing.T) {
+	records := []Record{
+		{Event: "test", Index: 0, Red: "greedy", Blue: "random", Geometry: bitboard.Standard,
+			Moves: board.History{0, 1, 0, 1, 0, 1, 0}, Result: RedWin, Seed: 5},
+		{Event: "test, quoted", Index: 1, Geometry: bitboard.Geometry{Width: 5, Height: 4},
+			Start: board.History{2}, Moves: board.History{2}, Result: Unfinished},
+	}
+
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf)
+	for _, r := range records {
+		if err := w.Write(r); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "event,index,red,blue,geometry,start,moves,result,seed\n" +
+		"test,0,greedy,random,7x6,,1212121,red,5\n" +
+		"\"test, quoted\",1,,,5x4,3,3,unfinished,\n"
+	if buf.String() != want {
+		t.Errorf("unexpected CSV. Expected:\n%v\nObserved:\n%v", want, buf.String())
+	}
+
+	r := NewCSVReader(&buf)
+	for i, want := range records {
+		got, err := r.Read()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Event != want.Event || got.Index != want.Index || got.Red != want.Red || got.Geometry != want.Geometry ||
+			!got.Start.Equals(want.Start) || !got.Moves.Equals(want.Moves) || got.Result != want.Result ||
+			got.Seed != want.Seed {
+			t.Errorf("record %v: expected %+v, observed %+v", i, want, got)
+		}
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("expected %v, observed %v", io.EOF, err)
+	}
+}
+
+func TestCSVReader_minimal(t *testing.T) {
+	r := NewCSVReader(strings.NewReader("moves\n1212121\n44\n4!\n"))
+	for _, want := range []Result{RedWin, Unfinished} {
+		got, err := r.Read()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Geometry != bitboard.Standard || got.Result != want {
+			t.Errorf("expected a %v game of the standard geometry, observed %+v", want, got)
+		}
+	}
+	var lineErr LineError
+	if _, err := r.Read(); !errors.As(err, &lineErr) || lineErr.Line != 4 {
+		t.Errorf("expected an error on line 4, observed %v", err)
+	}
+
+	if _, err := NewCSVReader(strings.NewReader("event,index\n")).Read(); err == nil {
+		t.Errorf("expected an error reading CSV without moves")
+	}
+}
+
+func TestMoves(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewMovesWriter(&buf)
+	for _, r := range []Record{game("1212121"), {Start: board.History{3}, Moves: board.History{3}}} {
+		if err := w.Write(r); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if buf.String() != "1212121\n44\n" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+
+	buf.WriteString("\n4!\n")
+	r := NewMovesReader(&buf, bitboard.Standard)
+	for i, want := range []Result{RedWin, Unfinished} {
+		got, err := r.Read()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Index != i || got.Result != want {
+			t.Errorf("expected game %v to be %v, observed %+v", i, want, got)
+		}
+	}
+	var lineErr LineError
+	if _, err := r.Read(); !errors.As(err, &lineErr) || lineErr.Line != 4 {
+		t.Errorf("expected an error on line 4, observed %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package record
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// maxLine bounds the length of a single line of input, comfortably exceeding the longest possible record
+const maxLine = 1 << 20
+
+// Writer writes records as newline-delimited JSON
+type Writer struct {
+	enc *json.Encoder
+}
+
+// NewWriter returns a writer of records to w
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{enc: json.NewEncoder(w)}
+}
+
+// Write writes a single record as a line of JSON
+func (w *Writer) Write(r Record) error {
+	if err := w.enc.Encode(r); err != nil {
+		return fmt.Errorf("cannot write record: %w", err)
+	}
+	return nil
+}
+
+// Reader reads records from newline-delimited JSON, skipping blank lines
+type Reader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewReader returns a reader of records from r
+func NewReader(r io.Reader) *Reader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 4096), maxLine)
+	return &Reader{scanner: s}
+}
+
+// Read returns the next record, or io.EOF once every record has been read
+func (r *Reader) Read() (Record, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := r.scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return Record{}, fmt.Errorf("cannot read record: %w", LineError{Line: r.line, Err: err})
+		}
+		return rec, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return Record{}, fmt.Errorf("cannot read record: %w", err)
+	}
+	return Record{}, io.EOF
+}
+
+// Line returns the line number of the record last read, counting from one
+func (r *Reader) Line() int {
+	return r.line
+}
+
+// ReadAll reads every record from r
+func ReadAll(r io.Reader) ([]Record, error) {
+	var records []Record
+	rr := NewReader(r)
+	for {
+		rec, err := rr.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, rec)
+	}
+}
+
+// WriteAll writes every record to w
+func WriteAll(w io.Writer, records []Record) error {
+	rw := NewWriter(w)
+	for _, r := range records {
+		if err := rw.Write(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
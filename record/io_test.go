@@ -0,0 +1,49 @@
+package record
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/talglobus/fearsome/board"
+)
+
+func TestWriteAll_ReadAll(t *testing.T) {
+	records := []Record{
+		{Index: 0, Red: "a", Blue: "b", Moves: board.History{0, 1, 0, 1, 0, 1, 0}, Result: RedWin},
+		{Index: 1, Red: "b", Blue: "a", Moves: board.History{3}},
+	}
+	var buf bytes.Buffer
+	if err := WriteAll(&buf, records); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 2 {
+		t.Errorf("expected one line per record, observed %v lines", n)
+	}
+
+	read, err := ReadAll(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(read) != 2 || read[0].Result != RedWin || !read[1].Moves.Equals(board.History{3}) {
+		t.Errorf("unexpected records %+v", read)
+	}
+}
+
+func TestReader_Read(t *testing.T) {
+	r := NewReader(strings.NewReader("{\"moves\":\"44\"}\n\n{\"moves\":\"4!\"}\n"))
+	if rec, err := r.Read(); err != nil || !rec.Moves.Equals(board.History{3, 3}) || r.Line() != 1 {
+		t.Fatalf("unexpected record %+v and error %v", rec, err)
+	}
+
+	_, err := r.Read()
+	var lineErr LineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 3 {
+		t.Fatalf("expected an error on line 3, observed %v", err)
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("expected end of input, observed %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+// Package record defines game records, the unit in which tournaments, simulations and pipelines store and exchange
+// finished games, along with reading and writing them as newline-delimited JSON, one record per line.
+package record
+
+import (
+	"fmt"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+// Result is an enumerated type describing how a game ended
+type Result uint8
+
+// Unfinished games haven't ended. RedWin and BlueWin games were won by the respective player, while Draw games
+// filled the board without a winner
+const (
+	Unfinished Result = iota
+	RedWin
+	BlueWin
+	Draw
+)
+
+// results holds the textual form of each result, as used in JSON
+var results = [...]string{"unfinished", "red", "blue", "draw"}
+
+func (r Result) String() string {
+	if int(r) >= len(results) {
+		return fmt.Sprintf("Result(%v)", uint8(r))
+	}
+	return results[r]
+}
+
+// MarshalText encodes the result as text, such that it's encoded as a string in JSON
+func (r Result) MarshalText() ([]byte, error) {
+	if int(r) >= len(results) {
+		return nil, ResultError(r)
+	}
+	return []byte(results[r]), nil
+}
+
+// UnmarshalText decodes a result from text
+func (r *Result) UnmarshalText(text []byte) error {
+	for i, s := range results {
+		if s == string(text) {
+			*r = Result(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown result %q", text)
+}
+
+// ResultOf returns the result of a position
+func ResultOf(p bitboard.Position) Result {
+	switch {
+	case p.Winner() == board.RED:
+		return RedWin
+	case p.Winner() == board.BLUE:
+		return BlueWin
+	case p.IsFull():
+		return Draw
+	default:
+		return Unfinished
+	}
+}
+
+// Finished returns the result of a finished game won by the given player, or drawn if NONE
+func Finished(winner board.Type) Result {
+	switch winner {
+	case board.RED:
+		return RedWin
+	case board.BLUE:
+		return BlueWin
+	default:
+		return Draw
+	}
+}
+
+// Winner returns the winner of a game with the result, or NONE if drawn or unfinished
+func (r Result) Winner() board.Type {
+	switch r {
+	case RedWin:
+		return board.RED
+	case BlueWin:
+		return board.BLUE
+	default:
+		return board.NONE
+	}
+}
+
+// Record holds a single game, along with who played it and where it came from
+type Record struct {
+	Event string `json:"event,omitempty"` // Name of the tournament, simulation or session the game belongs to
+	Index int    `json:"index"`           // Index of the game within its event
+	Red   string `json:"red"`             // Name of the player of RED
+	Blue  string `json:"blue"`            // Name of the player of BLUE
+
+	Geometry bitboard.Geometry `json:"geometry"`
+	Start    board.History     `json:"start,omitempty"` // Moves leading to the starting position, if not empty
+	Moves    board.History     `json:"moves"`           // Moves played from the starting position
+	Result   Result            `json:"result"`
+	Seed     int64             `json:"seed,omitempty"` // Seed from which the game's randomness was drawn, if any
+}
+
+// History returns every move of the game from the empty board, being the starting moves followed by those played
+func (r Record) History() board.History {
+	return append(append(board.History{}, r.Start...), r.Moves...)
+}
+
+// Position returns the final position of the game
+func (r Record) Position() (bitboard.Position, error) {
+	g := r.Geometry
+	if g == (bitboard.Geometry{}) {
+		g = bitboard.Standard
+	}
+	if err := g.Validate(); err != nil {
+		return bitboard.Position{}, fmt.Errorf("cannot replay record: %w", err)
+	}
+	p, err := bitboard.FromHistory(g, r.History())
+	if err != nil {
+		return bitboard.Position{}, fmt.Errorf("cannot replay record: %w", err)
+	}
+	return p, nil
+}
+
+// Validate checks that the moves of the game are legal, and that the recorded result is that of the final position
+func (r Record) Validate() error {
+	p, err := r.Position()
+	if err != nil {
+		return err
+	}
+	if got := ResultOf(p); got != r.Result {
+		return fmt.Errorf("invalid record: %w", MismatchError{Recorded: r.Result, Actual: got})
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package record
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+func TestRecord_Validate(t *testing.T) {
+	small := bitboard.Geometry{Width: 2, Height: 2}
+	table := []struct {
+		name   string
+		record Record
+		err    interface{}
+	}{
+		{"red win", Record{Moves: board.History{0, 1, 0, 1, 0, 1, 0}, Result: RedWin}, nil},
+		{"blue win from start", Record{Start: board.History{6}, Moves: board.History{0, 1, 0, 1, 0, 1, 0},
+			Result: BlueWin}, nil},
+		{"draw", Record{Geometry: small, Moves: board.History{0, 0, 1, 1}, Result: Draw}, nil},
+		{"unfinished", Record{Moves: board.History{3}, Result: Unfinished}, nil},
+		{"wrong result", Record{Moves: board.History{3}, Result: Draw}, new(MismatchError)},
+		{"illegal move", Record{Geometry: small, Moves: board.History{0, 0, 0}}, new(board.FullColumnError)},
+		{"invalid geometry", Record{Geometry: bitboard.Geometry{Width: 9, Height: 9}},
+			new(bitboard.GeometryError)},
+	}
+
+	for _, r := range table {
+		t.Run(r.name, func(t *testing.T) {
+			err := r.record.Validate()
+			if r.err == nil && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if r.err != nil && !errors.As(err, r.err) {
+				t.Errorf("expected error of type %T, observed %v", r.err, err)
+			}
+		})
+	}
+}
+
+func TestRecord_JSON(t *testing.T) {
+	r := Record{Event: "test", Index: 2, Red: "greedy", Blue: "random", Geometry: bitboard.Standard,
+		Moves: board.History{3, 3, 4, 2}, Seed: 7}
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"event":"test","index":2,"red":"greedy","blue":"random","geometry":{"cols":7,"rows":6},` +
+		`"moves":"4453","result":"unfinished","seed":7}`
+	if string(data) != want {
+		t.Errorf("unexpected encoding. Expected:\n%v\nObserved:\n%s", want, data)
+	}
+
+	var decoded Record
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded.Index != 2 || decoded.Geometry != bitboard.Standard || !decoded.Moves.Equals(r.Moves) ||
+		decoded.Start != nil {
+		t.Errorf("unexpected decoding %+v", decoded)
+	}
+
+	if err := json.Unmarshal([]byte(`{"result":"resigned"}`), &decoded); err == nil {
+		t.Errorf("expected an error decoding an unknown result")
+	}
+	if _, err := json.Marshal(Record{Result: 9}); !errors.As(err, new(ResultError)) {
+		t.Errorf("expected a result error, observed %v", err)
+	}
+}
+
+func TestResultOf(t *testing.T) {
+	for _, r := range []Result{Unfinished, RedWin, BlueWin, Draw} {
+		var p bitboard.Position
+		switch r {
+		case Unfinished:
+			p = bitboard.New(bitboard.Standard)
+		case RedWin:
+			p, _ = bitboard.FromHistory(bitboard.Standard, board.History{0, 1, 0, 1, 0, 1, 0})
+		case BlueWin:
+			p, _ = bitboard.FromHistory(bitboard.Standard, board.History{6, 0, 1, 0, 1, 0, 1, 0})
+		case Draw:
+			p, _ = bitboard.FromHistory(bitboard.Geometry{Width: 1, Height: 2}, board.History{0, 0})
+		}
+		if got := ResultOf(p); got != r {
+			t.Errorf("expected %v, observed %v", r, got)
+		}
+		if r != Unfinished && Finished(r.Winner()) != r {
+			t.Errorf("expected %v to be the finished result of winner %v", r, r.Winner())
+		}
+		if (r == RedWin) != (r.Winner() == board.RED) || (r == BlueWin) != (r.Winner() == board.BLUE) {
+			t.Errorf("unexpected winner %v of %v", r.Winner(), r)
+		}
+	}
+}
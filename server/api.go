@@ -0,0 +1,422 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/record"
+	"github.com/talglobus/fearsome/simulate"
+	"github.com/talglobus/fearsome/solver"
+	"github.com/talglobus/fearsome/spectate"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+// Board identifies a position in a request, by the moves reaching it, or by a grid of squares as accepted by
+// board.ParseState, with rows separated by slashes, such as ".../.../RB...". A board given as a grid is only accepted
+// if some sequence of moves reaches it, which is returned in place of the grid in responses. Columns count from one,
+// in moves as everywhere in the API
+type Board struct {
+	Geometry *bitboard.Geometry `json:"geometry,omitempty"` // Board size, defaulting to 7x6
+	Moves    board.History      `json:"moves,omitempty"`    // Moves from the empty board, in notation such as "4453"
+	Grid     string             `json:"grid,omitempty"`     // Squares of the standard geometry, top row first
+}
+
+// position returns the position of a board and the moves reaching it, verifying that it's reachable in play
+func (b Board) position() (bitboard.Position, board.History, error) {
+	g := bitboard.Standard
+	if b.Geometry != nil {
+		g = *b.Geometry
+	}
+	if b.Grid == "" {
+		p, err := bitboard.FromHistory(g, b.Moves)
+		if err != nil {
+			return bitboard.Position{}, nil, RequestError{fmt.Errorf("cannot play moves %q: %w", b.Moves.Notation(), err)}
+		}
+		return p, b.Moves, nil
+	}
+
+	switch {
+	case len(b.Moves) > 0:
+		return bitboard.Position{}, nil, RequestError{errors.New("expected either moves or a grid, not both")}
+	case g != bitboard.Standard:
+		return bitboard.Position{}, nil, RequestError{fmt.Errorf("grids are only accepted for the %v geometry",
+			bitboard.Standard)}
+	}
+	s, err := board.ParseState(b.Grid)
+	if err != nil {
+		return bitboard.Position{}, nil, RequestError{err}
+	}
+	p, err := bitboard.FromState(s)
+	if err != nil {
+		return bitboard.Position{}, nil, RequestError{err}
+	}
+	h, err := bitboard.HistoryOf(p)
+	if err != nil {
+		return bitboard.Position{}, nil, RequestError{err}
+	}
+	return p, h, nil
+}
+
+// Position describes a position in a response
+type Position struct {
+	Geometry bitboard.Geometry `json:"geometry"`
+	Moves    board.History     `json:"moves"`            // Moves from the empty board, in notation
+	Grid     string            `json:"grid"`             // Squares, top row first, as R, B or ., separated by slashes
+	Turn     string            `json:"turn,omitempty"`   // Player to move, "red" or "blue", if the game isn't over
+	Winner   string            `json:"winner,omitempty"` // Player who has won, "red" or "blue", if either
+	Over     bool              `json:"over"`
+}
+
+// describe describes the position reached by a sequence of moves
+func describe(p bitboard.Position, h board.History) Position {
+	d := Position{Geometry: p.Geometry(), Moves: h, Grid: spectate.Grid(p), Over: p.IsOver()}
+	if w := p.Winner(); w != board.NONE {
+		d.Winner = spectate.Player(w)
+	} else if !d.Over {
+		d.Turn = spectate.Player(p.Turn())
+	}
+	return d
+}
+
+// boardRequest is the body of a request about a single board
+type boardRequest struct {
+	Board Board `json:"board"`
+}
+
+func (s *Server) validate(ctx context.Context, decode func(v interface{}) error) (interface{}, error) {
+	var req boardRequest
+	if err := decode(&req); err != nil {
+		return nil, err
+	}
+	p, h, err := req.Board.position()
+	if err != nil {
+		return nil, err
+	}
+	return describe(p, h), nil
+}
+
+// Moves lists the moves of a position, by column
+type Moves struct {
+	Position Position `json:"position"`
+	Legal    []int    `json:"legal"`   // Columns that aren't full
+	Winning  []int    `json:"winning"` // Columns winning the game at once
+	Safe     []int    `json:"safe"`    // Columns not handing the opponent a win at once, if none win at once
+}
+
+func (s *Server) moves(ctx context.Context, decode func(v interface{}) error) (interface{}, error) {
+	var req boardRequest
+	if err := decode(&req); err != nil {
+		return nil, err
+	}
+	p, h, err := req.Board.position()
+	if err != nil {
+		return nil, err
+	}
+	d := describe(p, h)
+
+	m := Moves{Position: d, Legal: []int{}, Winning: []int{}, Safe: []int{}}
+	if p.IsOver() {
+		return m, nil
+	}
+	for _, move := range p.LegalMoves() {
+		m.Legal = append(m.Legal, int(move)+1)
+		if p.IsWinningMove(int(move)) {
+			m.Winning = append(m.Winning, int(move)+1)
+		}
+	}
+	if len(m.Winning) == 0 {
+		for safe := p.NonLosingMoves(); safe != 0; safe &= safe - 1 {
+			m.Safe = append(m.Safe, p.ColumnOf(safe&-safe)+1)
+		}
+	}
+	return m, nil
+}
+
+// playRequest is the body of a request to apply moves to a board
+type playRequest struct {
+	Board Board         `json:"board"`
+	Play  board.History `json:"play"` // Moves to apply, in notation
+}
+
+func (s *Server) play(ctx context.Context, decode func(v interface{}) error) (interface{}, error) {
+	var req playRequest
+	if err := decode(&req); err != nil {
+		return nil, err
+	}
+	p, h, err := req.Board.position()
+	if err != nil {
+		return nil, err
+	}
+	if p, err = p.PlayMoves(req.Play); err != nil {
+		return nil, RequestError{fmt.Errorf("cannot play moves %q: %w", req.Play.Notation(), err)}
+	}
+	return describe(p, append(append(board.History{}, h...), req.Play...)), nil
+}
+
+// solveRequest is the body of a request to solve a board
+type solveRequest struct {
+	Board     Board  `json:"board"`
+	Budget    string `json:"budget,omitempty"`    // Time allowed to solve, such as "5s", capped by the server's timeout
+	Variation int    `json:"variation,omitempty"` // Greatest length of the principal variation, or unlimited if 0
+}
+
+// Solution holds the value of a position under optimal play
+type Solution struct {
+	Position  Position      `json:"position"`
+	Score     solver.Score  `json:"score"`            // Score from the perspective of the player to move
+	Result    string        `json:"result"`           // "win", "loss", or "draw", for the player to move
+	Winner    string        `json:"winner,omitempty"` // Winner under optimal play, if not a draw
+	Distance  int           `json:"distance"`         // Moves remaining under optimal play
+	Optimal   []int         `json:"optimal"`          // Columns of every optimal move
+	Scores    map[int]int   `json:"scores"`           // Score of every playable column
+	Variation board.History `json:"variation"`        // Principal variation, in notation
+	Seconds   float64       `json:"seconds"`
+}
+
+func (s *Server) solve(ctx context.Context, decode func(v interface{}) error) (interface{}, error) {
+	start := time.Now()
+	var req solveRequest
+	if err := decode(&req); err != nil {
+		return nil, err
+	}
+	if req.Variation < 0 {
+		return nil, RequestError{errors.New("variation length must not be negative")}
+	}
+	p, h, err := req.Board.position()
+	if err != nil {
+		return nil, err
+	}
+	d := describe(p, h)
+	ctx, cancel, err := budget(ctx, req.Budget)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	sv, err := s.solver(p.Geometry())
+	if err != nil {
+		return nil, err
+	}
+
+	sol := Solution{Position: d, Optimal: []int{}, Scores: map[int]int{}}
+	if p.IsOver() {
+		if sol.Score, err = sv.Solve(ctx, p); err != nil {
+			return nil, err
+		}
+	} else {
+		scores, ok, err := sv.Analyze(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		var moves []board.Move
+		sol.Score, moves = solver.OptimalMoves(p.Geometry(), scores, ok)
+		for _, m := range moves {
+			sol.Optimal = append(sol.Optimal, int(m)+1)
+		}
+		sort.Ints(sol.Optimal)
+		for col := range scores {
+			if ok[col] {
+				sol.Scores[col+1] = int(scores[col])
+			}
+		}
+	}
+	if sol.Variation, err = sv.Variation(ctx, p, req.Variation); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case sol.Score > 0:
+		sol.Result = "win"
+	case sol.Score < 0:
+		sol.Result = "loss"
+	default:
+		sol.Result = "draw"
+	}
+	if w := sol.Score.Winner(p); w != board.NONE {
+		sol.Winner = spectate.Player(w)
+	}
+	sol.Distance = sol.Score.Plies(p)
+	sol.Seconds = time.Since(start).Seconds()
+	return sol, nil
+}
+
+// simulateRequest is the body of a request to simulate games
+type simulateRequest struct {
+	Red        string             `json:"red"`  // Strategy of RED, by name as accepted by strategy.Parse
+	Blue       string             `json:"blue"` // Strategy of BLUE, by name
+	Games      int                `json:"games"`
+	Seed       int64              `json:"seed,omitempty"`
+	Geometry   *bitboard.Geometry `json:"geometry,omitempty"`   // Board size, defaulting to 7x6
+	Start      board.History      `json:"start,omitempty"`      // Moves reaching the starting position, in notation
+	Confidence float64            `json:"confidence,omitempty"` // Confidence level of intervals, defaulting to 0.95
+	Budget     string             `json:"budget,omitempty"`     // Time allowed to play, capped by the server's timeout
+	// Event names the simulation to spectators, who watch its games by the event name and index, defaulting to
+	// simulation-N for the Nth simulation run by the server
+	Event string `json:"event,omitempty"`
+}
+
+// Rate holds the number of games reaching an outcome, and an estimate of its proportion with a confidence interval
+type Rate struct {
+	Count    int     `json:"count"`
+	Estimate float64 `json:"estimate"`
+	Low      float64 `json:"low"`
+	High     float64 `json:"high"`
+}
+
+// Simulation holds the results of a simulation
+type Simulation struct {
+	Event       string            `json:"event"`
+	Red         string            `json:"red"`
+	Blue        string            `json:"blue"`
+	Geometry    bitboard.Geometry `json:"geometry"`
+	Start       board.History     `json:"start"`
+	Seed        int64             `json:"seed"`
+	Games       int               `json:"games"`
+	Confidence  float64           `json:"confidence"`
+	RedWins     Rate              `json:"red_wins"`
+	BlueWins    Rate              `json:"blue_wins"`
+	Draws       Rate              `json:"draws"`
+	MeanLength  float64           `json:"mean_length"`
+	StdevLength float64           `json:"stdev_length"`
+	Seconds     float64           `json:"seconds"`
+}
+
+func (s *Server) simulate(ctx context.Context, decode func(v interface{}) error) (interface{}, error) {
+	start := time.Now()
+	var req simulateRequest
+	if err := decode(&req); err != nil {
+		return nil, err
+	}
+	if req.Games <= 0 || req.Games > s.config.MaxGames {
+		return nil, RequestError{fmt.Errorf("games must be between 1 and %v", s.config.MaxGames)}
+	}
+	if req.Confidence < 0 || req.Confidence >= 1 {
+		return nil, RequestError{errors.New("confidence must be between 0 and 1")}
+	}
+	red, err := s.strategy(req.Red)
+	if err != nil {
+		return nil, err
+	}
+	blue, err := s.strategy(req.Blue)
+	if err != nil {
+		return nil, err
+	}
+	p, _, err := Board{Geometry: req.Geometry, Moves: req.Start}.position()
+	if err != nil {
+		return nil, err
+	}
+	if p.IsOver() {
+		return nil, RequestError{fmt.Errorf("game is over after starting moves %q", req.Start.Notation())}
+	}
+	ctx, cancel, err := budget(ctx, req.Budget)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	if req.Event == "" {
+		req.Event = fmt.Sprintf("simulation-%v", atomic.AddUint64(&s.simulations, 1))
+	}
+	redName, blueName := strategy.Name(red), strategy.Name(blue)
+	var started []string // Identifiers of the games published, such that any left unfinished can be ended
+	moved := func(g simulate.Game, pos bitboard.Position) {
+		rec := record.Record{Event: req.Event, Index: g.Index, Red: redName, Blue: blueName,
+			Geometry: pos.Geometry(), Start: req.Start, Moves: g.Moves, Result: record.ResultOf(pos), Seed: g.Seed}
+		if len(g.Moves) == 0 {
+			started = append(started, spectate.ID(rec))
+		}
+		s.hub.Publish(rec)
+	}
+
+	// Requests already run concurrently with each other, so each plays its own games one at a time
+	r, err := simulate.Run(ctx, simulate.Config{Games: req.Games, Red: red, Blue: blue, Seed: req.Seed,
+		Geometry: p.Geometry(), Start: req.Start, Confidence: req.Confidence, Workers: 1, Moved: moved})
+	if err != nil {
+		// Games cut short never publish a result, so are ended for their spectators
+		for _, id := range started {
+			s.hub.End(id)
+		}
+		return nil, err
+	}
+
+	rate := func(count int, i simulate.Interval) Rate {
+		return Rate{Count: count, Estimate: i.Estimate, Low: i.Low, High: i.High}
+	}
+	return Simulation{
+		Event:       req.Event,
+		Red:         redName,
+		Blue:        blueName,
+		Geometry:    r.Config.Geometry,
+		Start:       r.Config.Start,
+		Seed:        r.Config.Seed,
+		Games:       len(r.Games),
+		Confidence:  r.Config.Confidence,
+		RedWins:     rate(r.Red, r.RedRate),
+		BlueWins:    rate(r.Blue, r.BlueRate),
+		Draws:       rate(r.Draws, r.DrawRate),
+		MeanLength:  r.MeanLength,
+		StdevLength: r.StdevLength,
+		Seconds:     time.Since(start).Seconds(),
+	}, nil
+}
+
+// moveRequest is the body of a request for a strategy's move
+type moveRequest struct {
+	Board    Board  `json:"board"`
+	Strategy string `json:"strategy"`         // Strategy choosing the move, by name as accepted by strategy.Parse
+	Seed     int64  `json:"seed,omitempty"`   // Seed of the strategy's random choices
+	Budget   string `json:"budget,omitempty"` // Time allowed to choose, capped by the server's timeout
+}
+
+// Choice holds a strategy's move, and the position it leads to
+type Choice struct {
+	Strategy string   `json:"strategy"`
+	Move     int      `json:"move"` // Column chosen, counting from one
+	Position Position `json:"position"`
+	Seconds  float64  `json:"seconds"`
+}
+
+func (s *Server) move(ctx context.Context, decode func(v interface{}) error) (interface{}, error) {
+	start := time.Now()
+	var req moveRequest
+	if err := decode(&req); err != nil {
+		return nil, err
+	}
+	st, err := s.strategy(req.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	p, h, err := req.Board.position()
+	if err != nil {
+		return nil, err
+	}
+	if p.IsOver() {
+		return nil, RequestError{bitboard.GameOverError{}}
+	}
+	ctx, cancel, err := budget(ctx, req.Budget)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	m, err := st.Choose(ctx, p, rand.New(rand.NewSource(req.Seed)))
+	if err != nil {
+		return nil, err
+	}
+	if !p.CanPlay(int(m)) {
+		return nil, fmt.Errorf("%v chose column %v: %w", strategy.Name(st), int(m)+1, bitboard.ColumnRangeError(m))
+	}
+	p = p.Play(int(m))
+	return Choice{
+		Strategy: strategy.Name(st),
+		Move:     int(m) + 1,
+		Position: describe(p, append(append(board.History{}, h...), m)),
+		Seconds:  time.Since(start).Seconds(),
+	}, nil
+}
@@ -0,0 +1,269 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/talglobus/fearsome/record"
+	"github.com/talglobus/fearsome/spectate"
+)
+
+// endgame holds the moves of a position solved quickly, in which RED, to move, wins at once in either of two columns
+const endgame = "444444333333222222"
+
+// empty holds the five top rows of an empty grid of the standard geometry, to be followed by the bottom row
+const empty = "......./......./......./......./......./"
+
+func TestServer_validate(t *testing.T) {
+	s := newServer(t, Config{})
+	table := []struct {
+		name, body string
+		status     int
+		moves      string
+		grid       string
+		turn       string
+		winner     string
+	}{
+		{"empty", `{"board":{}}`, http.StatusOK, "", "......./......./......./......./......./.......", "red", ""},
+		{"moves", `{"board":{"moves":"445"}}`, http.StatusOK, "445", "......./......./......./......./...B.../...RR..",
+			"blue", ""},
+		{"grid", `{"board":{"grid":"` + empty + `RB....."}}`, http.StatusOK, "12", empty + "RB.....", "red", ""},
+		{"won", `{"board":{"moves":"1212121"}}`, http.StatusOK, "1212121",
+			"......./......./R....../RB...../RB...../RB.....", "", "red"},
+		{"small", `{"board":{"geometry":{"cols":4,"rows":4},"moves":"1"}}`, http.StatusOK, "1", "..../..../..../R...",
+			"blue", ""},
+		{"full column", `{"board":{"moves":"1111111"}}`, http.StatusBadRequest, "", "", "", ""},
+		{"past the end", `{"board":{"moves":"12121212"}}`, http.StatusBadRequest, "", "", "", ""},
+		{"bad notation", `{"board":{"moves":"4!"}}`, http.StatusBadRequest, "", "", "", ""},
+		{"bad geometry", `{"board":{"geometry":{"cols":9,"rows":7}}}`, http.StatusBadRequest, "", "", "", ""},
+		{"moves and grid", `{"board":{"moves":"1","grid":"R"}}`, http.StatusBadRequest, "", "", "", ""},
+		{"unbalanced grid", `{"board":{"grid":"` + empty + `RR....."}}`, http.StatusBadRequest, "", "", "", ""},
+		{"unreachable grid", `{"board":{"grid":"` + empty[:32] + `BBBB.../RRRR..."}}`, http.StatusBadRequest, "", "",
+			"", ""},
+		{"grid of other geometry", `{"board":{"geometry":{"cols":4,"rows":4},"grid":"R"}}`, http.StatusBadRequest,
+			"", "", "", ""},
+	}
+
+	for _, r := range table {
+		var got Position
+		if status := post(t, s, "/api/validate", r.body, &got); status != r.status {
+			t.Errorf("%v: expected status %v, observed %v", r.name, r.status, status)
+			continue
+		}
+		if r.status != http.StatusOK {
+			continue
+		}
+		if got.Moves.Notation() != r.moves || got.Grid != r.grid || got.Turn != r.turn || got.Winner != r.winner ||
+			got.Over != (r.turn == "") {
+			t.Errorf("%v: expected moves %q, grid %q, turn %q and winner %q, observed %+v", r.name, r.moves, r.grid,
+				r.turn, r.winner, got)
+		}
+	}
+}
+
+func TestServer_moves(t *testing.T) {
+	s := newServer(t, Config{})
+	table := []struct {
+		name, moves          string
+		legal, winning, safe []int
+	}{
+		{"empty", "", []int{1, 2, 3, 4, 5, 6, 7}, []int{}, []int{1, 2, 3, 4, 5, 6, 7}},
+		{"full column", "111111", []int{2, 3, 4, 5, 6, 7}, []int{}, []int{2, 3, 4, 5, 6, 7}},
+		{"winning", "121212", []int{1, 2, 3, 4, 5, 6, 7}, []int{1}, []int{}},
+		{"forced", "123252", []int{1, 2, 3, 4, 5, 6, 7}, []int{}, []int{2}},
+		{"over", "1212121", []int{}, []int{}, []int{}},
+	}
+
+	for _, r := range table {
+		var got Moves
+		if status := post(t, s, "/api/moves", `{"board":{"moves":"`+r.moves+`"}}`, &got); status != http.StatusOK {
+			t.Errorf("%v: expected status 200, observed %v", r.name, status)
+			continue
+		}
+		if !reflect.DeepEqual(got.Legal, r.legal) || !reflect.DeepEqual(got.Winning, r.winning) ||
+			!reflect.DeepEqual(got.Safe, r.safe) {
+			t.Errorf("%v: expected legal %v, winning %v and safe %v, observed %v, %v and %v", r.name, r.legal,
+				r.winning, r.safe, got.Legal, got.Winning, got.Safe)
+		}
+	}
+}
+
+func TestServer_play(t *testing.T) {
+	s := newServer(t, Config{})
+	table := []struct {
+		name, body string
+		status     int
+		moves      string
+	}{
+		{"moves", `{"board":{"moves":"44"},"play":"53"}`, http.StatusOK, "4453"},
+		{"grid", `{"board":{"grid":"` + empty + `RB....."},"play":"7"}`, http.StatusOK, "127"},
+		{"none", `{"board":{"moves":"44"}}`, http.StatusOK, "44"},
+		{"full column", `{"board":{"moves":"111111"},"play":"1"}`, http.StatusBadRequest, ""},
+		{"past the end", `{"board":{"moves":"121212"},"play":"12"}`, http.StatusBadRequest, ""},
+		{"missing column", `{"board":{},"play":"8"}`, http.StatusBadRequest, ""},
+	}
+
+	for _, r := range table {
+		var got Position
+		if status := post(t, s, "/api/play", r.body, &got); status != r.status {
+			t.Errorf("%v: expected status %v, observed %v", r.name, r.status, status)
+		} else if status == http.StatusOK && got.Moves.Notation() != r.moves {
+			t.Errorf("%v: expected moves %q, observed %q", r.name, r.moves, got.Moves.Notation())
+		}
+	}
+}
+
+func TestServer_solve(t *testing.T) {
+	s := newServer(t, Config{})
+	table := []struct {
+		name, body string
+		result     string
+		winner     string
+		optimal    []int
+		variation  int
+	}{
+		{"endgame", `{"board":{"moves":"` + endgame + `"}}`, "win", "red", []int{1, 5}, 1},
+		{"over", `{"board":{"moves":"1212121"}}`, "loss", "red", []int{}, 0},
+		{"small", `{"board":{"geometry":{"cols":4,"rows":4}},"budget":"20s"}`, "draw", "", []int{1, 2, 3, 4}, 16},
+		{"limited variation", `{"board":{"geometry":{"cols":4,"rows":4}},"variation":3}`, "draw", "",
+			[]int{1, 2, 3, 4}, 3},
+	}
+
+	for _, r := range table {
+		var got Solution
+		if status := post(t, s, "/api/solve", r.body, &got); status != http.StatusOK {
+			t.Errorf("%v: expected status 200, observed %v", r.name, status)
+			continue
+		}
+		if got.Result != r.result || got.Winner != r.winner || !reflect.DeepEqual(got.Optimal, r.optimal) ||
+			len(got.Variation) != r.variation {
+			t.Errorf("%v: expected %v for %q with optimal moves %v and a variation of %v moves, observed %+v",
+				r.name, r.result, r.winner, r.optimal, r.variation, got)
+		}
+	}
+}
+
+func TestServer_simulate(t *testing.T) {
+	s := newServer(t, Config{MaxGames: 100})
+	var got Simulation
+	body := `{"red":"greedy","blue":"random","games":50,"seed":3,"start":"44"}`
+	if status := post(t, s, "/api/simulate", body, &got); status != http.StatusOK {
+		t.Fatalf("expected status 200, observed %v", status)
+	}
+	if got.Games != 50 || got.RedWins.Count+got.BlueWins.Count+got.Draws.Count != 50 || got.Start.Notation() != "44" ||
+		got.Confidence != 0.95 {
+		t.Errorf("unexpected simulation %+v", got)
+	}
+
+	var again Simulation
+	post(t, s, "/api/simulate", body, &again)
+	if again.RedWins != got.RedWins || again.BlueWins != got.BlueWins || again.MeanLength != got.MeanLength {
+		t.Errorf("expected identical results from the same seed, observed %+v and %+v", got, again)
+	}
+
+	for _, body := range []string{
+		`{"red":"greedy","blue":"random","games":101}`,
+		`{"red":"greedy","blue":"random","games":0}`,
+		`{"red":"clairvoyant","blue":"random","games":10}`,
+		`{"red":"greedy","blue":"random","games":10,"start":"1212121"}`,
+		`{"red":"greedy","blue":"random","games":10,"confidence":1.5}`,
+	} {
+		if status := post(t, s, "/api/simulate", body, nil); status != http.StatusBadRequest {
+			t.Errorf("%v: expected status 400, observed %v", body, status)
+		}
+	}
+}
+
+func TestServer_simulate_watched(t *testing.T) {
+	s := newServer(t, Config{})
+	w := s.Hub().Watch("")
+	defer w.Close()
+
+	var got Simulation
+	if status := post(t, s, "/api/simulate", `{"red":"greedy","blue":"random","games":2,"event":"watched"}`,
+		&got); status != http.StatusOK || got.Event != "watched" {
+		t.Fatalf("expected status 200 for event watched, observed %v for %q", status, got.Event)
+	}
+	results := map[string]spectate.Event{}
+	for len(results) < 2 {
+		select {
+		case e := <-w.Events():
+			if e.Type == spectate.Result {
+				results[e.Game] = e
+			}
+		default:
+			t.Fatalf("expected results of both games, observed %v", results)
+		}
+	}
+	if e := results["watched/1"]; e.Record.Red != "greedy" || e.Record.Result == record.Unfinished {
+		t.Errorf("unexpected result %+v", e)
+	}
+
+	post(t, s, "/api/simulate", `{"red":"greedy","blue":"random","games":1}`, &got)
+	if got.Event != "simulation-1" {
+		t.Errorf("expected default event simulation-1, observed %q", got.Event)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/watch?game=watched/0", nil).WithContext(ctx))
+	if ct := rec.Header().Get("Content-Type"); rec.Code != http.StatusOK || ct != "text/event-stream" {
+		t.Errorf("expected an event stream, observed %v with content type %q", rec.Code, ct)
+	}
+}
+
+// TestServer_simulate_cancelled checks that games cut short by the end of a simulation's budget are ended for their
+// spectators, rather than left ongoing
+func TestServer_simulate_cancelled(t *testing.T) {
+	s := newServer(t, Config{})
+	w := s.Hub().Watch("")
+	defer w.Close()
+
+	body := `{"red":"perfect","blue":"perfect","games":2,"event":"cut","budget":"100ms"}`
+	if status := post(t, s, "/api/simulate", body, nil); status != http.StatusGatewayTimeout {
+		t.Fatalf("expected status 504, observed %v", status)
+	}
+	if games := s.Hub().Games(); len(games) != 0 {
+		t.Errorf("expected no ongoing games, observed %+v", games)
+	}
+	var last spectate.Event
+	for done := false; !done; {
+		select {
+		case last = <-w.Events():
+		default:
+			done = true
+		}
+	}
+	if last.Type != spectate.Ended || last.Game != "cut/0" {
+		t.Errorf("expected the game cut short to be ended, observed %+v", last)
+	}
+}
+
+func TestServer_move(t *testing.T) {
+	s := newServer(t, Config{})
+	table := []struct {
+		name, body string
+		status     int
+		move       int
+	}{
+		{"winning", `{"board":{"moves":"121212"},"strategy":"greedy"}`, http.StatusOK, 1},
+		{"blocking", `{"board":{"moves":"123252"},"strategy":"lookahead:2"}`, http.StatusOK, 2},
+		{"perfect", `{"board":{"moves":"` + endgame + `"},"strategy":"perfect:deterministic"}`, http.StatusOK, 5},
+		{"unknown strategy", `{"board":{},"strategy":"clairvoyant"}`, http.StatusBadRequest, 0},
+		{"over", `{"board":{"moves":"1212121"},"strategy":"random"}`, http.StatusBadRequest, 0},
+	}
+
+	for _, r := range table {
+		var got Choice
+		if status := post(t, s, "/api/move", r.body, &got); status != r.status {
+			t.Errorf("%v: expected status %v, observed %v", r.name, r.status, status)
+		} else if status == http.StatusOK && (got.Move != r.move || len(got.Position.Moves) == 0 ||
+			int(got.Position.Moves[len(got.Position.Moves)-1]) != r.move-1) {
+			t.Errorf("%v: expected column %v, observed %+v", r.name, r.move, got)
+		}
+	}
+}
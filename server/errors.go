@@ -0,0 +1,32 @@
+package server
+
+import "fmt"
+
+// RequestError defines an error used when a request is malformed, or asks for something impossible, such as a move in
+// a full column
+type RequestError struct {
+	Err error
+}
+
+func (e RequestError) Error() string {
+	return fmt.Sprintf("invalid request: %v", e.Err)
+}
+
+func (e RequestError) Unwrap() error {
+	return e.Err
+}
+
+// BusyError defines an error used when every slot for solves and simulations remains taken until the request times
+// out
+type BusyError struct{}
+
+func (e BusyError) Error() string {
+	return "server is busy"
+}
+
+// ConfigError defines an error used when a server is configured with invalid parameters
+type ConfigError string
+
+func (e ConfigError) Error() string {
+	return string(e)
+}
@@ -0,0 +1,301 @@
+// Package server exposes the analysis of positions over HTTP, as a JSON API for use from other languages and from
+// web pages. Boards are exchanged as JSON objects holding their moves in notation, or a grid of squares, along with
+// their geometry. The endpoints, all of which accept a POST of a JSON object, are:
+//
+//	/api/validate  checks that a board is reachable in play, and describes its position
+//	/api/moves     lists the legal moves of a board, along with those winning at once and those not losing at once
+//	/api/play      applies moves to a board, describing the resulting position
+//	/api/solve     solves a board within a time budget, reporting its value, optimal moves and principal variation
+//	/api/simulate  plays a small number of games between two strategies, reporting how often each side wins
+//	/api/move      chooses a move for the player to move in a board, by a strategy such as lookahead:4
+//
+// Games of simulations are streamed live to spectators as Server-Sent Events by a GET of /api/watch, or of
+// /api/watch?game=EVENT/INDEX to watch a single game, as served by spectate.Hub.
+//
+// Any other path is served from a self-contained web page, with which to play against the built-in strategies, step
+// through game records, and see the value of every column, using nothing but the API.
+//
+// Every request is bounded by a timeout, and solves and simulations by a limit on how many run at once, such that a
+// single heavy solve can't starve the server. Errors are reported as a JSON object holding a message, with status
+// 400 for invalid requests, 503 if the server remains too busy to start the request before it times out, and 504 if
+// the request's time budget runs out before the analysis completes.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/solver"
+	"github.com/talglobus/fearsome/spectate"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+// DefaultTimeout sets the time allowed to a request when none is configured
+const DefaultTimeout = 30 * time.Second
+
+// DefaultMaxGames sets the greatest number of games a simulation may play when no limit is configured
+const DefaultMaxGames = 10000
+
+// maxBody sets the greatest size of a request body, in bytes
+const maxBody = 1 << 20
+
+// maxStrategies sets the greatest number of strategies kept for reuse between requests
+const maxStrategies = 32
+
+// maxSolvers sets the greatest number of geometries whose solvers are kept for reuse between requests, each with an
+// equal share of the configured memory
+const maxSolvers = 4
+
+// DefaultMemory sets the memory of the transposition tables kept between requests when none is configured, in bytes,
+// giving the solver of each geometry kept the default budget of a solver
+const DefaultMemory = maxSolvers * solver.DefaultBudget
+
+// Config holds the tunable parameters of a Server
+type Config struct {
+	// Timeout sets the time allowed to a request, including any time spent waiting for a slot, defaulting to
+	// DefaultTimeout if zero. Requests may ask for a shorter time budget, but never a longer one
+	Timeout time.Duration
+	// Concurrency sets the number of solves and simulations running at once, defaulting to the number of CPUs if
+	// zero. Further requests wait for a slot until they time out. Requests that don't search, such as validating or
+	// playing moves, are never limited
+	Concurrency int
+	// Memory sets the total size of the transposition tables kept between requests, in bytes, defaulting to
+	// DefaultMemory if zero. The solvers of the four most recently used geometries are kept, each with a quarter of
+	// the memory, and a solver dropped for a new geometry frees its table once the requests searching with it end
+	Memory int
+	// MaxGames sets the greatest number of games a simulation may play, defaulting to DefaultMaxGames if zero
+	MaxGames int
+}
+
+// Server serves the JSON API. Solves of positions of the same geometry share a solver while it's kept, and so its
+// transposition table, such that related positions solve faster
+type Server struct {
+	config Config
+	slots  chan struct{}
+	mux    *http.ServeMux
+
+	hub         *spectate.Hub
+	simulations uint64 // Number of simulations run, accessed atomically
+
+	mutex      sync.Mutex
+	solvers    map[bitboard.Geometry]*solver.Solver
+	recent     []bitboard.Geometry // Geometries of the solvers kept, least recently used first
+	strategies map[string]strategy.Strategy
+}
+
+// New constructs a Server
+func New(c Config) (*Server, error) {
+	switch {
+	case c.Timeout < 0:
+		return nil, fmt.Errorf("cannot construct server: %w", ConfigError("timeout must not be negative"))
+	case c.Concurrency < 0:
+		return nil, fmt.Errorf("cannot construct server: %w", ConfigError("concurrency must not be negative"))
+	case c.Memory < 0:
+		return nil, fmt.Errorf("cannot construct server: %w", ConfigError("memory must not be negative"))
+	case c.MaxGames < 0:
+		return nil, fmt.Errorf("cannot construct server: %w", ConfigError("game limit must not be negative"))
+	}
+	if c.Timeout == 0 {
+		c.Timeout = DefaultTimeout
+	}
+	if c.Concurrency == 0 {
+		c.Concurrency = runtime.NumCPU()
+	}
+	if c.Memory == 0 {
+		c.Memory = DefaultMemory
+	}
+	if c.MaxGames == 0 {
+		c.MaxGames = DefaultMaxGames
+	}
+
+	s := &Server{
+		config:     c,
+		slots:      make(chan struct{}, c.Concurrency),
+		mux:        http.NewServeMux(),
+		hub:        spectate.NewHub(0),
+		solvers:    map[bitboard.Geometry]*solver.Solver{},
+		strategies: map[string]strategy.Strategy{},
+	}
+	s.handle("/api/validate", false, s.validate)
+	s.handle("/api/moves", false, s.moves)
+	s.handle("/api/play", false, s.play)
+	s.handle("/api/solve", true, s.solve)
+	s.handle("/api/simulate", true, s.simulate)
+	s.handle("/api/move", true, s.move)
+	s.mux.Handle("/api/watch", s.hub)
+	s.mux.Handle("/", http.FileServer(http.FS(pages)))
+	return s, nil
+}
+
+// ServeHTTP serves a request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Hub returns the hub to which games are published for spectators, such that games played elsewhere, such as those of
+// a tournament, can be watched alongside simulations
+func (s *Server) Hub() *spectate.Hub {
+	return s.hub
+}
+
+// Handle registers another handler on the server's mux, such as for serving pages that call the API
+func (s *Server) Handle(pattern string, h http.Handler) {
+	s.mux.Handle(pattern, h)
+}
+
+// endpoint handles a request to an endpoint of the API, decoding its body as needed and returning the value to send
+// in response, or an error
+type endpoint func(ctx context.Context, decode func(v interface{}) error) (interface{}, error)
+
+// handle registers an endpoint, which if heavy must take a slot before running
+func (s *Server) handle(pattern string, heavy bool, e endpoint) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			respond(w, http.StatusMethodNotAllowed, errorBody{"method not allowed, expected POST"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), s.config.Timeout)
+		defer cancel()
+		if heavy {
+			select {
+			case s.slots <- struct{}{}:
+				defer func() { <-s.slots }()
+			case <-ctx.Done():
+				fail(w, BusyError{})
+				return
+			}
+		}
+
+		decode := func(v interface{}) error {
+			d := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBody))
+			d.DisallowUnknownFields()
+			if err := d.Decode(v); err != nil {
+				return RequestError{fmt.Errorf("cannot decode body: %w", err)}
+			}
+			return nil
+		}
+		v, err := e(ctx, decode)
+		if err != nil {
+			fail(w, err)
+			return
+		}
+		respond(w, http.StatusOK, v)
+	})
+}
+
+// errorBody is the body of an error response
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+// fail responds with an error, choosing its status by the kind of error
+func fail(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.As(err, &RequestError{}):
+		status = http.StatusBadRequest
+	case errors.As(err, &BusyError{}):
+		status = http.StatusServiceUnavailable
+		w.Header().Set("Retry-After", strconv.Itoa(1))
+	case errors.Is(err, context.DeadlineExceeded):
+		status = http.StatusGatewayTimeout
+		err = errors.New("time budget ran out before the analysis completed")
+	}
+	respond(w, status, errorBody{err.Error()})
+}
+
+// respond writes a value as the JSON body of a response
+func respond(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// budget returns a context bounded by the time budget of a request, given as a duration such as "5s", which can only
+// shorten the request's timeout
+func budget(ctx context.Context, text string) (context.Context, context.CancelFunc, error) {
+	if text == "" {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, nil
+	}
+	d, err := time.ParseDuration(text)
+	if err != nil {
+		return nil, nil, RequestError{fmt.Errorf("cannot parse budget: %w", err)}
+	}
+	if d <= 0 {
+		return nil, nil, RequestError{fmt.Errorf("budget %v is not positive", d)}
+	}
+	ctx, cancel := context.WithTimeout(ctx, d)
+	return ctx, cancel, nil
+}
+
+// solver returns the solver shared by solves of a geometry, constructing it on first use, and dropping the solver of
+// the least recently used geometry if as many as are kept already are
+func (s *Server) solver(g bitboard.Geometry) (*solver.Solver, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i, kept := range s.recent {
+		if kept == g {
+			s.recent = append(append(s.recent[:i], s.recent[i+1:]...), g)
+			return s.solvers[g], nil
+		}
+	}
+
+	if len(s.recent) == maxSolvers {
+		delete(s.solvers, s.recent[0])
+		s.recent = s.recent[1:]
+	}
+	t, err := solver.NewTable(g, s.config.Memory/maxSolvers, solver.TwoTier)
+	if err != nil {
+		return nil, err
+	}
+	sv, err := solver.New(g, solver.Config{Table: t})
+	if err != nil {
+		return nil, err
+	}
+	s.solvers[g] = sv
+	s.recent = append(s.recent, g)
+	return sv, nil
+}
+
+// strategy returns the strategy of the given name, reusing those of recent requests by their canonical name. Perfect
+// play, including within a mix, searches with the server's solvers, such that its tables are bounded by the
+// configured memory however many names requests use
+func (s *Server) strategy(name string) (strategy.Strategy, error) {
+	st, err := strategy.Parse(name)
+	if err != nil {
+		return nil, RequestError{err}
+	}
+	key := strategy.Name(st)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if cached, ok := s.strategies[key]; ok {
+		return cached, nil
+	}
+	for inner := st; inner != nil; {
+		switch t := inner.(type) {
+		case *strategy.Perfect:
+			t.Solvers = s.solver
+			inner = nil
+		case strategy.Mix:
+			inner = t.Strategy
+		default:
+			inner = nil
+		}
+	}
+	if len(s.strategies) < maxStrategies {
+		s.strategies[key] = st
+	}
+	return st, nil
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/solver"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+// post sends a request to the server, returning the response's status and decoding its body into v if not nil
+func post(t *testing.T, s *Server, path, body string, v interface{}) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("%v: expected JSON response, observed content type %q", path, ct)
+	}
+	if v != nil && rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("%v: cannot decode response: %v\n%v", path, err, rec.Body)
+		}
+	}
+	return rec.Code
+}
+
+func newServer(t *testing.T, c Config) *Server {
+	t.Helper()
+	s, err := New(c)
+	if err != nil {
+		t.Fatalf("cannot construct server: %v", err)
+	}
+	return s
+}
+
+func TestNew(t *testing.T) {
+	table := []Config{
+		{Timeout: -time.Second},
+		{Concurrency: -1},
+		{Memory: -1},
+		{MaxGames: -1},
+	}
+	for _, c := range table {
+		if _, err := New(c); !errors.As(err, new(ConfigError)) {
+			t.Errorf("%+v: expected ConfigError, observed %v", c, err)
+		}
+	}
+}
+
+func TestServer_errors(t *testing.T) {
+	s := newServer(t, Config{})
+	table := []struct {
+		name, path, body string
+		status           int
+	}{
+		{"unknown endpoint", "/api/frobnicate", `{}`, http.StatusNotFound},
+		{"malformed body", "/api/validate", `{"board":`, http.StatusBadRequest},
+		{"unknown field", "/api/validate", `{"board":{"moves":"44"},"colour":"red"}`, http.StatusBadRequest},
+		{"bad budget", "/api/solve", `{"board":{"moves":"44"},"budget":"soon"}`, http.StatusBadRequest},
+		{"negative budget", "/api/solve", `{"board":{"moves":"44"},"budget":"-1s"}`, http.StatusBadRequest},
+		{"budget exceeded", "/api/solve", `{"board":{},"budget":"10ms"}`, http.StatusGatewayTimeout},
+	}
+
+	for _, r := range table {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, r.path, strings.NewReader(r.body)))
+		if rec.Code != r.status {
+			t.Errorf("%v: expected status %v, observed %v: %v", r.name, r.status, rec.Code, rec.Body)
+			continue
+		}
+		var body errorBody
+		if r.status != http.StatusNotFound && (json.Unmarshal(rec.Body.Bytes(), &body) != nil || body.Error == "") {
+			t.Errorf("%v: expected an error message, observed %q", r.name, rec.Body)
+		}
+	}
+}
+
+func TestServer_method(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newServer(t, Config{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/validate", nil))
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != http.MethodPost {
+		t.Errorf("expected status 405 allowing POST, observed %v allowing %q", rec.Code, rec.Header().Get("Allow"))
+	}
+}
+
+func TestServer_busy(t *testing.T) {
+	s := newServer(t, Config{Timeout: 50 * time.Millisecond, Concurrency: 1})
+
+	// Take the only slot, as a heavy solve would
+	s.slots <- struct{}{}
+	if status := post(t, s, "/api/solve", `{"board":{"moves":"444444"}}`, nil); status != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503 while busy, observed %v", status)
+	}
+	if status := post(t, s, "/api/validate", `{"board":{"moves":"444444"}}`, nil); status != http.StatusOK {
+		t.Errorf("expected light requests to be served while busy, observed status %v", status)
+	}
+
+	<-s.slots
+	if status := post(t, s, "/api/solve", `{"board":{"moves":"444444333333222222"}}`, nil); status != http.StatusOK {
+		t.Errorf("expected status 200 once a slot is free, observed %v", status)
+	}
+}
+
+func TestServer_strategy(t *testing.T) {
+	s := newServer(t, Config{})
+	a, err := s.strategy("mix:0.1:perfect")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := s.strategy("mix:0.10:perfect"); b != a {
+		t.Errorf("expected strategies of the same canonical name to be shared, observed %v and %v", a, b)
+	}
+	if p, ok := a.(strategy.Mix).Strategy.(*strategy.Perfect); !ok || p.Solvers == nil {
+		t.Fatalf("expected perfect play within the mix to use the server's solvers, observed %+v", a)
+	}
+
+	body := `{"board":{"geometry":{"cols":4,"rows":4},"moves":"1"},"strategy":"perfect:deterministic"}`
+	if status := post(t, s, "/api/move", body, nil); status != http.StatusOK {
+		t.Fatalf("expected status 200, observed %v", status)
+	}
+	if sv := s.solvers[bitboard.Geometry{Width: 4, Height: 4}]; sv == nil || sv.Nodes() == 0 {
+		t.Errorf("expected perfect play to search with the server's solver")
+	}
+}
+
+// TestServer_solver checks that solvers are kept for only the most recently used geometries, bounding their memory
+func TestServer_solver(t *testing.T) {
+	s := newServer(t, Config{Memory: 4 << 20})
+	geometries := []bitboard.Geometry{{Width: 4, Height: 4}, {Width: 5, Height: 4}, {Width: 4, Height: 5},
+		{Width: 5, Height: 5}}
+	kept := map[bitboard.Geometry]*solver.Solver{}
+	for _, g := range geometries {
+		sv, err := s.solver(g)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", g, err)
+		}
+		kept[g] = sv
+	}
+
+	// Using the first geometry again makes the second the least recently used, which a new geometry then drops
+	if sv, _ := s.solver(geometries[0]); sv != kept[geometries[0]] {
+		t.Errorf("expected the %v solver to be reused", geometries[0])
+	}
+	if _, err := s.solver(bitboard.Geometry{Width: 6, Height: 4}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(s.solvers) != maxSolvers {
+		t.Errorf("expected %v solvers kept, observed %v", maxSolvers, len(s.solvers))
+	}
+	if _, ok := s.solvers[geometries[1]]; ok {
+		t.Errorf("expected the least recently used %v solver to be dropped", geometries[1])
+	}
+	if sv := s.solvers[geometries[0]]; sv != kept[geometries[0]] {
+		t.Errorf("expected the recently used %v solver to be kept", geometries[0])
+	}
+}
@@ -0,0 +1,20 @@
+package server
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// files holds the web page served alongside the API, which loads nothing from elsewhere
+//
+//go:embed ui
+var files embed.FS
+
+// pages holds the web page at the root of the file system, such that ui/index.html is served at /
+var pages = func() fs.FS {
+	sub, err := fs.Sub(files, "ui")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}()
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestServer_ui(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newServer(t, Config{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("expected an HTML page, observed status %v and content type %q", rec.Code,
+			rec.Header().Get("Content-Type"))
+	}
+
+	page := rec.Body.String()
+	for _, endpoint := range []string{"/api/validate", "/api/play", "/api/solve", "/api/move"} {
+		if !strings.Contains(page, endpoint) {
+			t.Errorf("expected page to call %v", endpoint)
+		}
+	}
+	// The page must work offline, loading nothing from elsewhere
+	if external := regexp.MustCompile(`(src|href)=["']?(https?:)?//`).FindString(page); external != "" {
+		t.Errorf("expected no external assets, observed %q", external)
+	}
+}
@@ -0,0 +1,125 @@
+package simulate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/internal/atomicfile"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+// DefaultCheckpointInterval sets how often a checkpointed simulation writes its checkpoint, if not configured
+const DefaultCheckpointInterval = time.Minute
+
+// checkpoint is the content of a checkpoint file. As every game draws its randomness from its own source, seeded
+// from the master seed and the game's index, the master seed is the only random state a simulation needs to resume,
+// with the games completed so far. The remainder of the configuration is recorded only to detect resuming a
+// checkpoint with a different simulation
+type checkpoint struct {
+	Seed     int64            `json:"seed"`
+	Games    int              `json:"games"`
+	Red      string           `json:"red"`
+	Blue     string           `json:"blue"`
+	Geometry string           `json:"geometry"`
+	Start    board.History    `json:"start"`
+	Played   []checkpointGame `json:"played"`
+}
+
+// checkpointGame records a completed game. Its winner isn't recorded, but found again by replaying its moves when
+// the checkpoint is loaded, which validates the checkpoint in passing
+type checkpointGame struct {
+	Index int           `json:"index"`
+	Seed  int64         `json:"seed"`
+	Moves board.History `json:"moves"`
+}
+
+// newCheckpoint constructs the checkpoint of a simulation that has completed the given games
+func newCheckpoint(c Config, games []Game) checkpoint {
+	cp := checkpoint{Seed: c.Seed, Games: c.Games, Red: strategy.Name(c.Red), Blue: strategy.Name(c.Blue),
+		Geometry: c.Geometry.String(), Start: c.Start, Played: make([]checkpointGame, len(games))}
+	for i, g := range games {
+		cp.Played[i] = checkpointGame{Index: g.Index, Seed: g.Seed, Moves: g.Moves}
+	}
+	return cp
+}
+
+// saveCheckpoint writes the checkpoint of a simulation that has completed the given games. The checkpoint is written
+// to a temporary file that then replaces the checkpoint file, such that an interrupted write never corrupts an
+// earlier checkpoint
+func saveCheckpoint(c Config, games []Game) error {
+	data, err := json.Marshal(newCheckpoint(c, games))
+	if err != nil {
+		return fmt.Errorf("cannot encode checkpoint: %w", err)
+	}
+
+	err = atomicfile.Write(c.Checkpoint, func(w io.Writer) error {
+		_, err := w.Write(append(data, '\n'))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("cannot write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// loadCheckpoint returns the games completed by the simulation as of its checkpoint, or none if there's no
+// checkpoint file yet
+func loadCheckpoint(c Config, start bitboard.Position) ([]Game, error) {
+	data, err := ioutil.ReadFile(c.Checkpoint)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot read checkpoint: %w", err)
+	}
+
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("cannot read checkpoint %v: %w", c.Checkpoint, err)
+	}
+	want := newCheckpoint(c, nil)
+	switch {
+	case cp.Seed != want.Seed:
+		return nil, fmt.Errorf("cannot resume from checkpoint: %w", CheckpointMismatchError{"seed", cp.Seed, want.Seed})
+	case cp.Games != want.Games:
+		return nil, fmt.Errorf("cannot resume from checkpoint: %w",
+			CheckpointMismatchError{"games", cp.Games, want.Games})
+	case cp.Red != want.Red:
+		return nil, fmt.Errorf("cannot resume from checkpoint: %w", CheckpointMismatchError{"red", cp.Red, want.Red})
+	case cp.Blue != want.Blue:
+		return nil, fmt.Errorf("cannot resume from checkpoint: %w",
+			CheckpointMismatchError{"blue", cp.Blue, want.Blue})
+	case cp.Geometry != want.Geometry:
+		return nil, fmt.Errorf("cannot resume from checkpoint: %w",
+			CheckpointMismatchError{"geometry", cp.Geometry, want.Geometry})
+	case !cp.Start.Equals(want.Start):
+		return nil, fmt.Errorf("cannot resume from checkpoint: %w",
+			CheckpointMismatchError{"start", cp.Start.Notation(), want.Start.Notation()})
+	}
+
+	games := make([]Game, len(cp.Played))
+	for i, g := range cp.Played {
+		if g.Index < 0 || g.Index >= c.Games {
+			return nil, fmt.Errorf("cannot resume from checkpoint: %w", CheckpointIndexError(g.Index))
+		}
+		if g.Seed != GameSeed(c.Seed, g.Index) {
+			return nil, fmt.Errorf("cannot resume from checkpoint: game %v: %w", g.Index,
+				CheckpointMismatchError{"seed", g.Seed, GameSeed(c.Seed, g.Index)})
+		}
+		p, err := start.PlayMoves(g.Moves)
+		if err != nil {
+			return nil, fmt.Errorf("cannot resume from checkpoint: game %v: %w", g.Index, err)
+		}
+		if !p.IsOver() {
+			return nil, fmt.Errorf("cannot resume from checkpoint: %w", UnfinishedError(g.Index))
+		}
+		games[i] = Game{Index: g.Index, Seed: g.Seed, Moves: g.Moves, Winner: p.Winner()}
+	}
+	return games, nil
+}
@@ -0,0 +1,132 @@
+package simulate
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+// TestRun_checkpoint asserts that a simulation interrupted and resumed from its checkpoint reports the same results
+// as one never interrupted
+func TestRun_checkpoint(t *testing.T) {
+	c := Config{Games: 100, Red: strategy.Greedy{}, Blue: strategy.Random{}, Seed: 9, Workers: 4}
+	want, err := Run(context.Background(), c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Interrupt a checkpointed simulation partway through, then resume it
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	resumable := c
+	resumable.Checkpoint = filepath.Join(t.TempDir(), "checkpoint.json")
+	resumable.Blue = &canceller{moves: 300, cancel: cancel}
+	partial, err := Run(ctx, resumable)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected %v, observed %v", context.Canceled, err)
+	}
+	if len(partial.Games) == 0 || len(partial.Games) == c.Games {
+		t.Fatalf("expected a partial simulation, observed %v games", len(partial.Games))
+	}
+	resumable.Blue = strategy.Random{}
+	got, err := Run(context.Background(), resumable)
+	if err != nil {
+		t.Fatalf("unexpected error resuming: %v", err)
+	}
+
+	if got.Red != want.Red || got.Blue != want.Blue || got.Draws != want.Draws || got.MeanLength != want.MeanLength {
+		t.Fatalf("resumed simulation reported different results to an uninterrupted one")
+	}
+	for i := range want.Games {
+		if got.Games[i].Index != i || !got.Games[i].Moves.Equals(want.Games[i].Moves) {
+			t.Fatalf("resumed simulation produced a different game %v", i)
+		}
+	}
+}
+
+func TestRun_checkpointMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	c := Config{Games: 10, Red: strategy.Greedy{}, Blue: strategy.Random{}, Seed: 1, Checkpoint: path}
+	if _, err := Run(context.Background(), c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, modify := range []func(*Config){
+		func(c *Config) { c.Seed++ },
+		func(c *Config) { c.Games++ },
+		func(c *Config) { c.Blue = strategy.Greedy{} },
+	} {
+		d := c
+		modify(&d)
+		if _, err := Run(context.Background(), d); !errors.As(err, new(CheckpointMismatchError)) {
+			t.Errorf("expected a checkpoint mismatch error, observed %v", err)
+		}
+	}
+
+	// Checkpoints recording games the simulation doesn't play are rejected
+	n, start, _ := c.normalize()
+	played, err := loadCheckpoint(n, start)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, r := range []struct {
+		modify func(*Game)
+		err    error
+	}{
+		{func(g *Game) { g.Index = c.Games }, CheckpointIndexError(c.Games)},
+		{func(g *Game) { g.Moves = g.Moves[:1] }, UnfinishedError(played[0].Index)},
+	} {
+		games := append([]Game(nil), played...)
+		r.modify(&games[0])
+		if err := saveCheckpoint(n, games); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Run(context.Background(), c); !errors.Is(err, r.err) {
+			t.Errorf("expected %v, observed %v", r.err, err)
+		}
+	}
+
+	if err := ioutil.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Run(context.Background(), c); err == nil {
+		t.Errorf("expected an error resuming from a corrupted checkpoint")
+	}
+}
+
+// impostor claims to be the greedy strategy, but fails if ever asked to move
+type impostor struct{}
+
+func (impostor) Choose(context.Context, bitboard.Position, *rand.Rand) (board.Move, error) {
+	return 0, errors.New("impostor asked to move")
+}
+
+func (impostor) String() string {
+	return strategy.Greedy{}.String()
+}
+
+// TestRun_checkpointFinished asserts that resuming a finished simulation plays no further games
+func TestRun_checkpointFinished(t *testing.T) {
+	c := Config{Games: 20, Red: strategy.Greedy{}, Blue: strategy.Random{}, Seed: 4,
+		Checkpoint: filepath.Join(t.TempDir(), "checkpoint.json")}
+	want, err := Run(context.Background(), c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c.Red = impostor{}
+	got, err := Run(context.Background(), c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Red != want.Red || len(got.Games) != len(want.Games) {
+		t.Errorf("expected the finished simulation to be reported again")
+	}
+}
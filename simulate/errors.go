@@ -0,0 +1,48 @@
+package simulate
+
+import (
+	"fmt"
+
+	"github.com/talglobus/fearsome/board"
+)
+
+// ConfigError defines an error used when a simulation is configured incorrectly
+type ConfigError string
+
+func (e ConfigError) Error() string {
+	return string(e)
+}
+
+// IllegalMoveError defines an error used when a strategy chooses a column in which no stone can be dropped. The column
+// is shown counting from one, as in move notation
+type IllegalMoveError board.Move
+
+func (e IllegalMoveError) Error() string {
+	return fmt.Sprintf("column %v cannot be played", int(e)+1)
+}
+
+// CheckpointMismatchError defines an error used when resuming a simulation from the checkpoint of a different one
+type CheckpointMismatchError struct {
+	Field               string
+	Checkpoint, Current interface{}
+}
+
+func (e CheckpointMismatchError) Error() string {
+	return fmt.Sprintf("checkpoint %v is %v, but the simulation's is %v", e.Field, e.Checkpoint, e.Current)
+}
+
+// CheckpointIndexError defines an error used when resuming a simulation from a checkpoint recording a game of an index
+// beyond those of the simulation
+type CheckpointIndexError int
+
+func (e CheckpointIndexError) Error() string {
+	return fmt.Sprintf("checkpoint records game %v, which is out of range", int(e))
+}
+
+// UnfinishedError defines an error used when resuming a simulation from a checkpoint recording a game that isn't
+// over as completed
+type UnfinishedError int
+
+func (e UnfinishedError) Error() string {
+	return fmt.Sprintf("checkpoint records game %v as completed, but it's unfinished", int(e))
+}
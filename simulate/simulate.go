@@ -0,0 +1,320 @@
+// Package simulate plays games between two strategies, estimating how often each side wins, and recording every game
+// along with the seed that produced it, such that any estimate can be audited by replaying its games.
+package simulate
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/internal/pool"
+	"github.com/talglobus/fearsome/stats"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+// Config holds the parameters of a simulation
+type Config struct {
+	Games     int               // Number of games to play
+	Red, Blue strategy.Strategy // Strategies of the RED and BLUE players
+	Seed      int64             // Master seed, from which the seed of every game is derived
+
+	// Geometry sets the board size, defaulting to bitboard.Standard, the size of board.Board, if zero
+	Geometry bitboard.Geometry
+	// Start holds the moves leading to the starting position of every game, such as those of a board.Board as
+	// returned by Board.History, or nil to start from the empty board
+	Start board.History
+	// Confidence sets the confidence level of the reported intervals, defaulting to 0.95 if zero
+	Confidence float64
+	// Workers sets the number of games played concurrently, defaulting to the number of CPUs if zero. Results are
+	// identical regardless of the number of workers
+	Workers int
+
+	// Checkpoint, if not empty, names a file to which the games completed so far are written every
+	// CheckpointInterval, and when the simulation ends for any reason. If the file already exists, the simulation
+	// resumes from it, playing only the games it lacks, such that the report is identical to that of an
+	// uninterrupted simulation. The file must have been written by a simulation of the same configuration
+	Checkpoint string
+	// CheckpointInterval sets how often the checkpoint is written, defaulting to DefaultCheckpointInterval if zero
+	CheckpointInterval time.Duration
+
+	// Moved, if not nil, is called as each game starts and after each of its moves, with the game so far and the
+	// position reached, such as for watching games live. It's called from the goroutine playing the game, which
+	// waits for it to return, so it must return quickly. Games resumed from a checkpoint aren't played again, so
+	// aren't reported
+	Moved func(g Game, p bitboard.Position)
+}
+
+// Game holds the record of a single simulated game
+type Game struct {
+	Index  int
+	Seed   int64         // Seed of the random source shared by both strategies, derived from the master seed
+	Moves  board.History // Moves played from the starting position
+	Winner board.Type    // Winner of the game, or NONE for a draw
+}
+
+// Interval holds an estimated proportion along with a confidence interval around it
+type Interval = stats.Interval
+
+// Report holds the results of a simulation
+type Report struct {
+	Config                  Config
+	Games                   []Game
+	Red, Blue, Draws        int      // Number of games won by each player, and drawn
+	RedRate, BlueRate       Interval // Proportion of games won by each player
+	DrawRate                Interval // Proportion of games drawn
+	MeanLength, StdevLength float64  // Mean and standard deviation of the number of moves played per game
+}
+
+// String summarizes the report in a human-readable format
+func (r Report) String() string {
+	return fmt.Sprintf("%v games (%.0f%% confidence)\nRED wins:  %6v  %v\nBLUE wins: %6v  %v\nDraws:     %6v  %v\n"+
+		"Length:    %.2f ± %.2f moves", len(r.Games), r.Config.Confidence*100, r.Red, r.RedRate, r.Blue, r.BlueRate,
+		r.Draws, r.DrawRate, r.MeanLength, r.StdevLength)
+}
+
+// Histories returns the moves of every game from the empty board, including the starting moves, such as for
+// summarizing with stats.Summarize
+func (r Report) Histories() []board.History {
+	histories := make([]board.History, len(r.Games))
+	for i, g := range r.Games {
+		histories[i] = append(append(board.History{}, r.Config.Start...), g.Moves...)
+	}
+	return histories
+}
+
+// Run plays the configured number of games across the configured number of workers, returning a report of the
+// results. Each game draws its randomness from its own source, seeded from the master seed and the game's index, so
+// that results don't depend on which worker plays which game, and any game can be replayed in isolation with Replay.
+//
+// If the context is done, or a strategy fails, before every game is played, Run returns a report of the games
+// completed until then, in order of index, along with the error. If the simulation is checkpointed, those games are
+// then resumed from by the next Run of the same configuration
+func Run(ctx context.Context, c Config) (Report, error) {
+	c, start, err := c.normalize()
+	if err != nil {
+		return Report{}, err
+	}
+
+	games, played := make([]Game, c.Games), make([]bool, c.Games)
+	if c.Checkpoint != "" {
+		resumed, err := loadCheckpoint(c, start)
+		if err != nil {
+			return Report{}, err
+		}
+		for _, g := range resumed {
+			games[g.Index], played[g.Index] = g, true
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Workers record games under the mutex, such that checkpoints can be taken while they play
+	var mutex sync.Mutex
+	completed := func() []Game {
+		mutex.Lock()
+		defer mutex.Unlock()
+		var out []Game
+		for i, g := range games {
+			if played[i] {
+				out = append(out, g)
+			}
+		}
+		return out
+	}
+
+	pending := make([]int, 0, c.Games)
+	for i := range games {
+		if !played[i] {
+			pending = append(pending, i)
+		}
+	}
+
+	// Checkpoints are taken while the workers play, and a checkpoint that can't be written ends the run
+	var checkpointFailure error
+	stopped := make(chan struct{})
+	var wg sync.WaitGroup
+	if c.Checkpoint != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(c.CheckpointInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					if err := saveCheckpoint(c, completed()); err != nil {
+						checkpointFailure = err
+						cancel()
+						return
+					}
+				case <-stopped:
+					return
+				}
+			}
+		}()
+	}
+
+	failure := pool.Run(ctx, c.Workers, pending, func(ctx context.Context, i int) error {
+		g, err := play(ctx, c, start, i, GameSeed(c.Seed, i))
+		if err != nil {
+			return err
+		}
+		mutex.Lock()
+		games[i], played[i] = g, true
+		mutex.Unlock()
+		return nil
+	})
+	close(stopped)
+	wg.Wait()
+	if checkpointFailure != nil {
+		failure = checkpointFailure
+	}
+
+	done := completed()
+	if c.Checkpoint != "" {
+		if err := saveCheckpoint(c, done); err != nil && failure == nil {
+			failure = err
+		}
+	}
+	return summarize(c, done), failure
+}
+
+// Replay plays a single game of a simulation again, from the seed recorded for it
+func Replay(ctx context.Context, c Config, g Game) (Game, error) {
+	c, start, err := c.normalize()
+	if err != nil {
+		return Game{}, err
+	}
+	return play(ctx, c, start, g.Index, g.Seed)
+}
+
+// normalize fills in defaults, validates the configuration, and returns the starting position
+func (c Config) normalize() (Config, bitboard.Position, error) {
+	if c.Geometry == (bitboard.Geometry{}) {
+		c.Geometry = bitboard.Standard
+	}
+	if c.Confidence == 0 {
+		c.Confidence = 0.95
+	}
+	if c.Workers == 0 {
+		c.Workers = runtime.NumCPU()
+	}
+	if c.CheckpointInterval == 0 {
+		c.CheckpointInterval = DefaultCheckpointInterval
+	}
+
+	switch {
+	case c.Red == nil || c.Blue == nil:
+		return c, bitboard.Position{}, fmt.Errorf("cannot simulate: %w", ConfigError("both strategies must be set"))
+	case c.Workers < 0:
+		return c, bitboard.Position{}, fmt.Errorf("cannot simulate: %w", ConfigError("workers must not be negative"))
+	case c.Games < 0:
+		return c, bitboard.Position{}, fmt.Errorf("cannot simulate: %w", ConfigError("games must not be negative"))
+	case c.CheckpointInterval < 0:
+		return c, bitboard.Position{}, fmt.Errorf("cannot simulate: %w",
+			ConfigError("checkpoint interval must not be negative"))
+	case c.Confidence <= 0 || c.Confidence >= 1:
+		return c, bitboard.Position{}, fmt.Errorf("cannot simulate: %w",
+			ConfigError("confidence must be between 0 and 1"))
+	}
+
+	start, err := bitboard.FromHistory(c.Geometry, c.Start)
+	if err != nil {
+		return c, bitboard.Position{}, fmt.Errorf("cannot simulate from starting moves: %w", err)
+	}
+	if start.IsOver() {
+		return c, bitboard.Position{}, fmt.Errorf("cannot simulate from starting moves: %w", bitboard.GameOverError{})
+	}
+	return c, start, nil
+}
+
+// GameSeed derives the seed of a single game from the master seed and the game's index, by way of the SplitMix64
+// finalizer, such that neighboring games receive unrelated seeds
+func GameSeed(master int64, index int) int64 {
+	z := uint64(master) + uint64(index+1)*0x9e3779b97f4a7c15
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return int64(z ^ z>>31)
+}
+
+// play plays a single game from the starting position
+func play(ctx context.Context, c Config, p bitboard.Position, index int, seed int64) (Game, error) {
+	g := Game{Index: index, Seed: seed}
+	rng := rand.New(rand.NewSource(seed))
+	if c.Moved != nil {
+		c.Moved(g, p)
+	}
+
+	for !p.IsOver() {
+		if err := ctx.Err(); err != nil {
+			return g, fmt.Errorf("cannot play game %v: %w", index, err)
+		}
+
+		s := c.Red
+		if p.Turn() == board.BLUE {
+			s = c.Blue
+		}
+
+		m, err := s.Choose(ctx, p, rng)
+		if err != nil {
+			return g, fmt.Errorf("cannot play game %v: %w", index, err)
+		}
+		if !p.CanPlay(int(m)) {
+			return g, fmt.Errorf("cannot play game %v: %v chose column %v: %w", index, strategy.Name(s), int(m)+1,
+				IllegalMoveError(m))
+		}
+
+		p = p.Play(int(m))
+		g.Moves = append(g.Moves, m)
+		if c.Moved != nil {
+			// The moves are copied, as the game continues to append to them
+			c.Moved(Game{Index: index, Seed: seed, Moves: append(board.History{}, g.Moves...), Winner: p.Winner()}, p)
+		}
+	}
+
+	g.Winner = p.Winner()
+	return g, nil
+}
+
+// summarize tallies the results of the games into a report
+func summarize(c Config, games []Game) Report {
+	r := Report{Config: c, Games: games}
+	var sum, sumSquares float64
+	for _, g := range games {
+		switch g.Winner {
+		case board.RED:
+			r.Red++
+		case board.BLUE:
+			r.Blue++
+		default:
+			r.Draws++
+		}
+		n := float64(len(g.Moves))
+		sum += n
+		sumSquares += n * n
+	}
+
+	n := len(games)
+	r.RedRate = Wilson(r.Red, n, c.Confidence)
+	r.BlueRate = Wilson(r.Blue, n, c.Confidence)
+	r.DrawRate = Wilson(r.Draws, n, c.Confidence)
+	if n > 0 {
+		r.MeanLength = sum / float64(n)
+		r.StdevLength = math.Sqrt(math.Max(0, sumSquares/float64(n)-r.MeanLength*r.MeanLength))
+	}
+	return r
+}
+
+// Wilson returns the Wilson score interval of a proportion, which unlike the normal approximation behaves well for
+// proportions near zero or one, as in lopsided matchups. It's kept here for existing callers, and computed by
+// stats.Wilson, alongside the package's other intervals
+func Wilson(successes, trials int, confidence float64) Interval {
+	return stats.Wilson(successes, trials, confidence)
+}
@@ -0,0 +1,290 @@
+package simulate
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+func TestRun(t *testing.T) {
+	c := Config{Games: 200, Red: strategy.Random{}, Blue: strategy.Random{}, Seed: 7}
+	r, err := Run(context.Background(), c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(r.Games) != c.Games || r.Red+r.Blue+r.Draws != c.Games {
+		t.Fatalf("expected %v games, observed %v games totalling %v results", c.Games, len(r.Games),
+			r.Red+r.Blue+r.Draws)
+	}
+	for _, rate := range []Interval{r.RedRate, r.BlueRate, r.DrawRate} {
+		if rate.Low > rate.Estimate || rate.Estimate > rate.High {
+			t.Errorf("interval %v should contain its estimate", rate)
+		}
+	}
+	if r.MeanLength < 7 || r.MeanLength > 42 {
+		t.Errorf("mean game length %v is impossible on a 7x6 board", r.MeanLength)
+	}
+
+	// Every game must be a legal game ending in the recorded result
+	for _, g := range r.Games {
+		p, err := bitboard.FromHistory(bitboard.Standard, g.Moves)
+		if err != nil {
+			t.Fatalf("game %v is not a legal game: %v", g.Index, err)
+		}
+		if !p.IsOver() || p.Winner() != g.Winner {
+			t.Fatalf("game %v should have ended with winner %v\n%v", g.Index, g.Winner, p)
+		}
+	}
+}
+
+// TestRun_deterministic asserts that a simulation is reproducible from its master seed, and that each game is
+// reproducible from its own seed
+func TestRun_deterministic(t *testing.T) {
+	c := Config{Games: 50, Red: strategy.Greedy{}, Blue: strategy.Random{}, Seed: 11}
+	r1, _ := Run(context.Background(), c)
+	r2, _ := Run(context.Background(), c)
+
+	for i := range r1.Games {
+		if !r1.Games[i].Moves.Equals(r2.Games[i].Moves) || r1.Games[i].Seed != r2.Games[i].Seed {
+			t.Fatalf("game %v differs between runs with the same seed", i)
+		}
+
+		g, err := Replay(context.Background(), c, r1.Games[i])
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !g.Moves.Equals(r1.Games[i].Moves) || g.Winner != r1.Games[i].Winner {
+			t.Fatalf("game %v differs when replayed from its seed", i)
+		}
+	}
+
+	c.Seed++
+	r3, _ := Run(context.Background(), c)
+	same := 0
+	for i := range r1.Games {
+		if r1.Games[i].Moves.Equals(r3.Games[i].Moves) {
+			same++
+		}
+	}
+	if same == len(r1.Games) {
+		t.Errorf("games should differ between runs with different seeds")
+	}
+}
+
+func TestRun_start(t *testing.T) {
+	// RED has three stacked in column 0, so a greedy RED wins immediately every game
+	b := board.New()
+	for _, col := range []int{0, 1, 0, 1, 0, 1} {
+		if _, _, err := b.Move(col); err != nil {
+			t.Fatalf("unexpected error making move: %v", err)
+		}
+	}
+
+	r, err := Run(context.Background(), Config{Games: 10, Red: strategy.Greedy{}, Blue: strategy.Random{},
+		Start: b.History()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.Red != 10 || r.MeanLength != 1 {
+		t.Errorf("expected RED to win every game in one move, observed %v wins in %v moves", r.Red, r.MeanLength)
+	}
+}
+
+func TestRun_geometry(t *testing.T) {
+	g := bitboard.Geometry{Width: 4, Height: 4}
+	r, err := Run(context.Background(), Config{Games: 20, Red: &strategy.Perfect{}, Blue: &strategy.Perfect{},
+		Geometry: g})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Perfect play draws on a 4x4 board, filling every square
+	if r.Draws != 20 || r.MeanLength != 16 {
+		t.Errorf("expected every game drawn in 16 moves, observed %v draws in %v moves", r.Draws, r.MeanLength)
+	}
+}
+
+// cheater always plays in the first column, eventually playing into a full column
+type cheater struct{}
+
+func (cheater) Choose(context.Context, bitboard.Position, *rand.Rand) (board.Move, error) {
+	return 0, nil
+}
+
+func TestRun_errors(t *testing.T) {
+	table := []struct {
+		name string
+		c    Config
+		err  error
+	}{
+		{"missing strategy", Config{Red: strategy.Random{}}, ConfigError("")},
+		{"negative games", Config{Red: strategy.Random{}, Blue: strategy.Random{}, Games: -1}, ConfigError("")},
+		{"bad confidence", Config{Red: strategy.Random{}, Blue: strategy.Random{}, Confidence: 1}, ConfigError("")},
+		{"illegal move", Config{Red: cheater{}, Blue: strategy.Random{}, Games: 1, Start: board.History{1, 0, 1, 0,
+			1, 0}}, IllegalMoveError(0)},
+		{"finished start", Config{Red: strategy.Random{}, Blue: strategy.Random{}, Start: board.History{0, 1, 0, 1,
+			0, 1, 0}}, bitboard.GameOverError{}},
+	}
+
+	for _, r := range table {
+		_, err := Run(context.Background(), r.c)
+		if _, ok := r.err.(ConfigError); ok {
+			if !errors.As(err, new(ConfigError)) {
+				t.Errorf("%v: expected %T, observed %v", r.name, r.err, err)
+			}
+		} else if !errors.Is(err, r.err) {
+			t.Errorf("%v: expected %v, observed %v", r.name, r.err, err)
+		}
+	}
+}
+
+func TestWilson(t *testing.T) {
+	table := []struct {
+		successes, trials int
+		low, high         float64
+	}{
+		{0, 10, 0, 0.2775},
+		{5, 10, 0.2366, 0.7634},
+		{81, 263, 0.2553, 0.3662},
+		{10, 10, 0.7225, 1},
+	}
+
+	for _, r := range table {
+		got := Wilson(r.successes, r.trials, 0.95)
+		if math.Abs(got.Low-r.low) > 1e-4 || math.Abs(got.High-r.high) > 1e-4 {
+			t.Errorf("Wilson interval of %v/%v incorrect. Expected [%v, %v], observed %v",
+				r.successes, r.trials, r.low, r.high, got)
+		}
+	}
+}
+
+func TestReport_Histories(t *testing.T) {
+	c := Config{Games: 5, Red: strategy.Random{}, Blue: strategy.Random{}, Start: board.History{3, 3}}
+	r, err := Run(context.Background(), c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i, h := range r.Histories() {
+		if !h[:2].Equals(c.Start) || !h[2:].Equals(r.Games[i].Moves) {
+			t.Errorf("game %v: expected starting moves followed by %v, observed %v", i, r.Games[i].Moves, h)
+		}
+	}
+}
+
+func TestReport_String(t *testing.T) {
+	r, _ := Run(context.Background(), Config{Games: 3, Red: strategy.Random{}, Blue: strategy.Random{}})
+	if s := r.String(); !strings.Contains(s, "3 games (95% confidence)") {
+		t.Errorf("unexpected summary:\n%v", s)
+	}
+}
+
+// TestRun_workers asserts that results are identical regardless of the number of workers
+func TestRun_workers(t *testing.T) {
+	c := Config{Games: 300, Red: strategy.Greedy{}, Blue: strategy.Random{}, Seed: 3, Workers: 1}
+	want, err := Run(context.Background(), c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, workers := range []int{2, 7, 32} {
+		c.Workers = workers
+		got, err := Run(context.Background(), c)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Red != want.Red || got.Blue != want.Blue || got.MeanLength != want.MeanLength {
+			t.Fatalf("%v workers produced different results to one worker", workers)
+		}
+		for i := range want.Games {
+			if got.Games[i].Index != i || !got.Games[i].Moves.Equals(want.Games[i].Moves) {
+				t.Fatalf("%v workers produced a different game %v to one worker", workers, i)
+			}
+		}
+	}
+}
+
+// canceller cancels a context after a given number of moves, to interrupt a simulation partway through
+type canceller struct {
+	strategy.Random
+	mutex  sync.Mutex
+	moves  int
+	cancel context.CancelFunc
+}
+
+func (c *canceller) Choose(ctx context.Context, p bitboard.Position, rng *rand.Rand) (board.Move, error) {
+	c.mutex.Lock()
+	if c.moves--; c.moves == 0 {
+		c.cancel()
+	}
+	c.mutex.Unlock()
+	return c.Random.Choose(ctx, p, rng)
+}
+
+func TestRun_cancelled(t *testing.T) {
+	full, _ := Run(context.Background(), Config{Games: 100, Red: strategy.Random{}, Blue: strategy.Random{},
+		Seed: 5})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	red := &canceller{moves: 400, cancel: cancel}
+	partial, err := Run(ctx, Config{Games: 100, Red: red, Blue: strategy.Random{}, Seed: 5, Workers: 4})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected %v, observed %v", context.Canceled, err)
+	}
+	if len(partial.Games) == 0 || len(partial.Games) >= 100 {
+		t.Fatalf("expected a partial report, observed %v games", len(partial.Games))
+	}
+	if partial.Red+partial.Blue+partial.Draws != len(partial.Games) {
+		t.Errorf("partial report should tally only completed games")
+	}
+
+	// Completed games must match those of an uninterrupted run
+	for _, g := range partial.Games {
+		if !g.Moves.Equals(full.Games[g.Index].Moves) {
+			t.Fatalf("game %v differs from the uninterrupted run", g.Index)
+		}
+	}
+}
+
+func TestRun_moved(t *testing.T) {
+	var mutex sync.Mutex
+	seen := map[int]board.History{}
+	c := Config{Games: 20, Red: strategy.Random{}, Blue: strategy.Random{}, Seed: 9, Start: board.History{3},
+		Moved: func(g Game, p bitboard.Position) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			prev, started := seen[g.Index]
+			want := 0
+			if started {
+				want = len(prev) + 1
+			}
+			if len(g.Moves) != want || !g.Moves[:len(prev)].Equals(prev) {
+				t.Errorf("game %v: expected a move to follow %v, observed %v", g.Index, prev, g.Moves)
+			}
+			if p.Moves() != len(g.Moves)+1 || g.Winner != p.Winner() {
+				t.Errorf("game %v: position doesn't match moves %v", g.Index, g.Moves)
+			}
+			seen[g.Index] = g.Moves
+		}}
+	r, err := Run(context.Background(), c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, g := range r.Games {
+		if !seen[g.Index].Equals(g.Moves) {
+			t.Errorf("game %v: expected every move to be reported, observed %v of %v", g.Index, seen[g.Index],
+				g.Moves)
+		}
+	}
+}
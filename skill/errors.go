@@ -0,0 +1,8 @@
+package skill
+
+// ConfigError defines an error used when an analysis is configured incorrectly
+type ConfigError string
+
+func (e ConfigError) Error() string {
+	return string(e)
+}
@@ -0,0 +1,22 @@
+package skill
+
+import "github.com/talglobus/fearsome/strategy"
+
+// Lookaheads returns a ladder of depth-limited searchers, one per depth
+func Lookaheads(depths ...int) []strategy.Strategy {
+	ladder := make([]strategy.Strategy, len(depths))
+	for i, d := range depths {
+		ladder[i] = strategy.Lookahead{Depth: d}
+	}
+	return ladder
+}
+
+// Mixtures returns a ladder of mixtures of a strategy with random play, one per probability of playing randomly,
+// such that a probability of one is random play, and a probability of zero is the strategy itself
+func Mixtures(s strategy.Strategy, epsilons ...float64) []strategy.Strategy {
+	ladder := make([]strategy.Strategy, len(epsilons))
+	for i, e := range epsilons {
+		ladder[i] = strategy.Mix{Epsilon: e, Strategy: s}
+	}
+	return ladder
+}
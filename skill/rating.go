@@ -0,0 +1,17 @@
+package skill
+
+import "github.com/talglobus/fearsome/rating"
+
+// ratings returns the Elo rating of each player, centered on zero, fitted to the results of the pairings by
+// rating.Elo, which counts draws as half a win and ignores color
+func ratings(players int, pairings []Pairing) []float64 {
+	outcomes := make([]rating.Outcome, len(pairings))
+	for i, p := range pairings {
+		outcomes[i] = rating.Outcome{A: p.Red, B: p.Blue, Wins: p.RedWins, Draws: p.Draws, Losses: p.BlueWins}
+	}
+	elo := make([]float64, players)
+	for i, e := range rating.Elo(players, outcomes) {
+		elo[i] = e.Rating
+	}
+	return elo
+}
@@ -0,0 +1,69 @@
+package skill
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// String summarizes the report in a human-readable format
+func (r Report) String() string {
+	return fmt.Sprintf("%v players, %v games per pairing on %v (seed %v, %.0f%% confidence)\n"+
+		"Variance explained by strategy: %.1f%% (%.4f of %.4f)\n"+
+		"Rating spread:                  %.0f Elo\n"+
+		"Stronger player wins:           %v of decisive games\n"+
+		"First player scores:            %v", len(r.Names), r.Config.Games, r.Config.Geometry, r.Config.Seed,
+		r.Config.Confidence*100, r.Variance.Share()*100, r.Variance.Strategy, r.Variance.Total, r.Spread,
+		r.StrongerWins, r.FirstMove)
+}
+
+// WriteMarkdown writes the report as Markdown, with the summary followed by a table of the players, strongest first,
+// and a cross table of the score of each player against each other player, across both colors
+func WriteMarkdown(w io.Writer, r Report) error {
+	order := make([]int, len(r.Names))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return r.Ratings[order[a]] > r.Ratings[order[b]] })
+
+	rows := []string{"```", r.String(), "```", "", "| player | rating | score |", "| --- | --- | --- |"}
+	for _, i := range order {
+		rows = append(rows, fmt.Sprintf("| %v | %+.0f | %.3f |", r.Names[i], r.Ratings[i], r.Scores[i]))
+	}
+
+	// points[i][j] holds the points scored by player i against player j, and games[i][j] the games they played
+	points, games := make([][]float64, len(r.Names)), make([][]int, len(r.Names))
+	for i := range points {
+		points[i], games[i] = make([]float64, len(r.Names)), make([]int, len(r.Names))
+	}
+	for _, p := range r.Pairings {
+		points[p.Red][p.Blue] += float64(p.RedWins) + float64(p.Draws)/2
+		points[p.Blue][p.Red] += float64(p.BlueWins) + float64(p.Draws)/2
+		games[p.Red][p.Blue] += p.Games()
+		games[p.Blue][p.Red] += p.Games()
+	}
+
+	header, rule := "| |", "| --- |"
+	for _, j := range order {
+		header, rule = header+" "+r.Names[j]+" |", rule+" --- |"
+	}
+	rows = append(rows, "", header, rule)
+	for _, i := range order {
+		row := "| " + r.Names[i] + " |"
+		for _, j := range order {
+			cell := ""
+			if games[i][j] > 0 {
+				cell = strconv.FormatFloat(points[i][j]/float64(games[i][j]), 'f', 3, 64)
+			}
+			row += " " + cell + " |"
+		}
+		rows = append(rows, row)
+	}
+
+	if _, err := io.WriteString(w, strings.Join(rows, "\n")+"\n"); err != nil {
+		return fmt.Errorf("cannot write report: %w", err)
+	}
+	return nil
+}
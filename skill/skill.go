@@ -0,0 +1,236 @@
+// Package skill measures how much of the outcome of a game is determined by strategy as opposed to chance, by playing
+// a ladder of players of varying skill against each other, and reporting how much of the variance in outcomes the
+// matchup explains, how far apart the players' ratings spread, and how often the stronger player wins.
+//
+// If outcomes were entirely down to chance, the matchup would explain none of their variance, ratings would bunch
+// together, and the stronger player would win no more than half the decisive games. If outcomes were entirely down to
+// strategy, the matchup would explain all of it, ratings would spread without bound, and the stronger player would
+// always win.
+package skill
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/simulate"
+	"github.com/talglobus/fearsome/stats"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+// Config holds the parameters of an analysis
+type Config struct {
+	// Players holds the ladder of players, usually from weakest to strongest, though their strength is measured
+	// rather than assumed
+	Players []strategy.Strategy
+	// Games sets the number of games played by each ordered pairing of players, such that each pair of players plays
+	// twice this many games, half with each color
+	Games int
+	// Seed is the master seed, from which the seed of every pairing is derived
+	Seed int64
+
+	// Geometry sets the board size, defaulting to bitboard.Standard if zero
+	Geometry bitboard.Geometry
+	// Confidence sets the confidence level of the reported intervals, defaulting to 0.95 if zero
+	Confidence float64
+	// Workers sets the number of games played concurrently, defaulting to the number of CPUs if zero
+	Workers int
+
+	// Progress, if not nil, is called with each pairing as soon as its games are played
+	Progress func(Pairing)
+}
+
+// Pairing holds the results of the games played between two players, each identified by its index in the ladder
+type Pairing struct {
+	Red, Blue                int
+	RedWins, BlueWins, Draws int
+}
+
+// Games returns the number of games played by the pairing
+func (p Pairing) Games() int {
+	return p.RedWins + p.BlueWins + p.Draws
+}
+
+// Variance decomposes the variance of game outcomes, scored from RED's perspective as one for a win, a half for a
+// draw, and zero for a loss
+type Variance struct {
+	Total float64 // Variance of the outcomes of all games
+	// Strategy is the part of the total explained by the matchup, being which player takes which color, corrected
+	// for the part of the variance between matchups expected from chance alone
+	Strategy float64
+	// Chance is the remainder, left unexplained by the matchup
+	Chance float64
+}
+
+// Share returns the fraction of the total variance explained by strategy
+func (v Variance) Share() float64 {
+	if v.Total == 0 {
+		return 0
+	}
+	return v.Strategy / v.Total
+}
+
+// Report holds the results of an analysis
+type Report struct {
+	Config   Config
+	Names    []string  // Name of each player
+	Pairings []Pairing // Results of every ordered pairing of distinct players
+	Scores   []float64 // Mean score of each player across all of its games, counting draws as half a win
+	Ratings  []float64 // Elo rating of each player, centered on zero
+
+	Variance     Variance
+	Spread       float64        // Difference between the highest and lowest ratings
+	FirstMove    stats.Interval // Mean score of RED across all games, counting draws as half a win
+	StrongerWins stats.Interval // Proportion of decisive games between differently rated players won by the stronger
+}
+
+// Run plays every ordered pairing of distinct players, and analyzes the results. Games are reproducible from the
+// master seed, regardless of the number of workers
+func Run(ctx context.Context, c Config) (Report, error) {
+	if c.Geometry == (bitboard.Geometry{}) {
+		c.Geometry = bitboard.Standard
+	}
+	if c.Confidence == 0 {
+		c.Confidence = 0.95
+	}
+	switch {
+	case len(c.Players) < 2:
+		return Report{}, fmt.Errorf("cannot analyze: %w", ConfigError("at least two players are required"))
+	case c.Games <= 0:
+		return Report{}, fmt.Errorf("cannot analyze: %w", ConfigError("games must be positive"))
+	}
+
+	var pairings []Pairing
+	for i := range c.Players {
+		for j := range c.Players {
+			if i == j {
+				continue
+			}
+			r, err := simulate.Run(ctx, simulate.Config{Games: c.Games, Red: c.Players[i], Blue: c.Players[j],
+				Seed: simulate.GameSeed(c.Seed, len(pairings)), Geometry: c.Geometry, Confidence: c.Confidence,
+				Workers: c.Workers})
+			if err != nil {
+				return Report{}, fmt.Errorf("cannot analyze %v against %v: %w", strategy.Name(c.Players[i]),
+					strategy.Name(c.Players[j]), err)
+			}
+
+			p := Pairing{Red: i, Blue: j, RedWins: r.Red, BlueWins: r.Blue, Draws: r.Draws}
+			pairings = append(pairings, p)
+			if c.Progress != nil {
+				c.Progress(p)
+			}
+		}
+	}
+	return Analyze(c, pairings), nil
+}
+
+// Analyze analyzes the results of pairings between the configured players, however they were played
+func Analyze(c Config, pairings []Pairing) Report {
+	if c.Confidence == 0 {
+		c.Confidence = 0.95
+	}
+	r := Report{Config: c, Pairings: pairings, Names: make([]string, len(c.Players))}
+	for i, s := range c.Players {
+		r.Names[i] = strategy.Name(s)
+	}
+
+	r.Scores = scores(len(c.Players), pairings)
+	r.Ratings = ratings(len(c.Players), pairings)
+	for _, rating := range r.Ratings {
+		r.Spread = math.Max(r.Spread, rating-minimum(r.Ratings))
+	}
+	r.Variance = decompose(pairings)
+
+	var games, redWins, redDraws, stronger, decisive int
+	for _, p := range pairings {
+		games += p.Games()
+		redWins += p.RedWins
+		redDraws += p.Draws
+		switch {
+		case r.Ratings[p.Red] > r.Ratings[p.Blue]:
+			stronger += p.RedWins
+			decisive += p.RedWins + p.BlueWins
+		case r.Ratings[p.Blue] > r.Ratings[p.Red]:
+			stronger += p.BlueWins
+			decisive += p.RedWins + p.BlueWins
+		}
+	}
+
+	// Draws count as half a win, which the Wilson interval can't represent directly, so the interval is computed
+	// over half-games
+	r.FirstMove = stats.Wilson(2*redWins+redDraws, 2*games, c.Confidence)
+	r.StrongerWins = stats.Wilson(stronger, decisive, c.Confidence)
+	return r
+}
+
+// outcome returns the score of RED in a game won by the given player
+func outcome(winner board.Type) float64 {
+	switch winner {
+	case board.RED:
+		return 1
+	case board.BLUE:
+		return 0
+	default:
+		return 0.5
+	}
+}
+
+// scores returns the mean score of each player across all of its games
+func scores(players int, pairings []Pairing) []float64 {
+	points, games := make([]float64, players), make([]int, players)
+	for _, p := range pairings {
+		points[p.Red] += float64(p.RedWins) + float64(p.Draws)/2
+		points[p.Blue] += float64(p.BlueWins) + float64(p.Draws)/2
+		games[p.Red] += p.Games()
+		games[p.Blue] += p.Games()
+	}
+	for i := range points {
+		if games[i] > 0 {
+			points[i] /= float64(games[i])
+		}
+	}
+	return points
+}
+
+// decompose splits the variance of outcomes into the variance between pairings and the variance within them. As
+// even outcomes decided by chance alone would differ between pairings with a finite number of games, the variance
+// between pairings is corrected by the amount expected from the variance within them
+func decompose(pairings []Pairing) Variance {
+	var n, sum, sumSquares, within, noise float64
+	for _, p := range pairings {
+		g := float64(p.Games())
+		if g == 0 {
+			continue
+		}
+		s := float64(p.RedWins)*outcome(board.RED) + float64(p.Draws)*outcome(board.NONE)
+		ss := float64(p.RedWins)*outcome(board.RED)*outcome(board.RED) +
+			float64(p.Draws)*outcome(board.NONE)*outcome(board.NONE)
+		n, sum, sumSquares = n+g, sum+s, sumSquares+ss
+
+		// Sum of squared deviations from the pairing's own mean, and the sample variance of the pairing's mean
+		deviations := ss - s*s/g
+		within += deviations
+		if g > 1 {
+			noise += deviations / (g - 1)
+		}
+	}
+	if n == 0 {
+		return Variance{}
+	}
+
+	v := Variance{Total: math.Max(0, sumSquares/n-(sum/n)*(sum/n))}
+	between := math.Max(0, v.Total-within/n)
+	v.Strategy = math.Max(0, between-noise/n)
+	v.Chance = v.Total - v.Strategy
+	return v
+}
+
+func minimum(values []float64) float64 {
+	m := math.Inf(1)
+	for _, v := range values {
+		m = math.Min(m, v)
+	}
+	return m
+}
@@ -0,0 +1,140 @@
+package skill
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+var small = bitboard.Geometry{Width: 5, Height: 4}
+
+func TestRun(t *testing.T) {
+	var progress int
+	c := Config{Players: Lookaheads(0, 1, 2, 4), Games: 60, Seed: 3, Geometry: small,
+		Progress: func(Pairing) { progress++ }}
+	r, err := Run(context.Background(), c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(r.Pairings) != 12 || progress != 12 {
+		t.Fatalf("expected 12 ordered pairings, observed %v with %v progress reports", len(r.Pairings), progress)
+	}
+	for _, p := range r.Pairings {
+		if p.Games() != c.Games {
+			t.Errorf("pairing %v played %v games, expected %v", p, p.Games(), c.Games)
+		}
+	}
+
+	// Deeper search should rate higher, at least at the extremes of the ladder
+	if r.Ratings[3] <= r.Ratings[0] || r.Scores[3] <= r.Scores[0] {
+		t.Errorf("expected the deepest searcher to outrate random play, observed ratings %v and scores %v",
+			r.Ratings, r.Scores)
+	}
+	if r.StrongerWins.Estimate <= 0.5 {
+		t.Errorf("expected the stronger player to win most decisive games, observed %v", r.StrongerWins)
+	}
+	if s := r.Variance.Share(); s <= 0 || s >= 1 {
+		t.Errorf("expected strategy to explain some but not all variance, observed %v", r.Variance)
+	}
+	if math.Abs(r.Variance.Strategy+r.Variance.Chance-r.Variance.Total) > 1e-12 {
+		t.Errorf("expected components to sum to the total, observed %v", r.Variance)
+	}
+
+	// The analysis is reproducible from the seed
+	r2, _ := Run(context.Background(), Config{Players: Lookaheads(0, 1, 2, 4), Games: 60, Seed: 3, Geometry: small,
+		Workers: 1})
+	if r.String() != r2.String() {
+		t.Errorf("expected identical reports from the same seed, observed\n%v\nand\n%v", r, r2)
+	}
+}
+
+// TestAnalyze_chance asserts that when the matchup determines nothing, strategy explains none of the variance
+func TestAnalyze_chance(t *testing.T) {
+	c := Config{Players: Mixtures(strategy.Greedy{}, 1, 1, 1)}
+	var pairings []Pairing
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			if i != j {
+				pairings = append(pairings, Pairing{Red: i, Blue: j, RedWins: 50, BlueWins: 50})
+			}
+		}
+	}
+
+	r := Analyze(c, pairings)
+	if r.Variance.Strategy != 0 || r.Variance.Total != 0.25 {
+		t.Errorf("expected no variance explained by strategy out of 0.25, observed %v", r.Variance)
+	}
+	if r.Spread > 1e-6 || r.StrongerWins.Estimate != 0 {
+		t.Errorf("expected equal ratings, observed %v", r.Ratings)
+	}
+	if r.FirstMove.Estimate != 0.5 {
+		t.Errorf("expected no first move advantage, observed %v", r.FirstMove)
+	}
+}
+
+// TestAnalyze_strategy asserts that when the stronger player always wins, strategy explains nearly all the variance
+func TestAnalyze_strategy(t *testing.T) {
+	c := Config{Players: Lookaheads(0, 1)}
+	pairings := []Pairing{{Red: 0, Blue: 1, BlueWins: 100}, {Red: 1, Blue: 0, RedWins: 100}}
+
+	r := Analyze(c, pairings)
+	if r.Variance.Share() != 1 {
+		t.Errorf("expected all variance explained by strategy, observed %v", r.Variance)
+	}
+	if r.Ratings[1] <= r.Ratings[0] || r.Spread < 400 || r.StrongerWins.Estimate != 1 {
+		t.Errorf("expected a wide spread in favor of the second player, observed %v", r.Ratings)
+	}
+	if math.Abs(r.Ratings[0]+r.Ratings[1]) > 1e-9 {
+		t.Errorf("expected ratings centered on zero, observed %v", r.Ratings)
+	}
+}
+
+func TestRatings(t *testing.T) {
+	// A player scoring 75% against another should be rated about 191 Elo higher, less the pull of the virtual draws
+	pairings := []Pairing{{Red: 0, Blue: 1, RedWins: 750, BlueWins: 250}}
+	r := ratings(2, pairings)
+	if d := r[0] - r[1]; d < 180 || d > 191 {
+		t.Errorf("expected a difference of about 191 Elo, observed %v", d)
+	}
+}
+
+func TestRun_errors(t *testing.T) {
+	table := []Config{
+		{Players: Lookaheads(1), Games: 10},
+		{Players: Lookaheads(1, 2), Games: 0},
+	}
+	for _, c := range table {
+		if _, err := Run(context.Background(), c); !errors.As(err, new(ConfigError)) {
+			t.Errorf("expected a configuration error, observed %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Run(ctx, Config{Players: Lookaheads(1, 2), Games: 10}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected cancellation, observed %v", err)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	c := Config{Players: Mixtures(strategy.Greedy{}, 1, 0), Games: 1, Geometry: small}
+	r := Analyze(c, []Pairing{{Red: 0, Blue: 1, BlueWins: 1}, {Red: 1, Blue: 0, Draws: 1}})
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"| mix:0:greedy | +", "| | mix:0:greedy | mix:1:greedy |",
+		"| mix:1:greedy | 0.250 |  |", "2 players, 1 games per pairing on 5x4"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected report to contain %q, observed\n%v", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package solver
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/internal/atomicfile"
+)
+
+// DefaultCheckpointInterval sets how often a checkpointed search writes its checkpoint, if not configured
+const DefaultCheckpointInterval = time.Minute
+
+// Checkpoint configures where and how often a long search records its progress, such that it can resume after being
+// interrupted
+type Checkpoint struct {
+	Path     string        // File holding the checkpoint, which is resumed from if it exists
+	Interval time.Duration // How often to write the checkpoint, or DefaultCheckpointInterval if zero
+}
+
+// checkpointMagic identifies a file as a search checkpoint, and the version of its format
+var checkpointMagic = [4]byte{'F', 'C', 'P', 1}
+
+// checkpointHeader precedes the root move scores of a checkpoint, which precede the dumped table
+type checkpointHeader struct {
+	Magic  [4]byte
+	Width  uint8
+	Height uint8
+	_      uint16
+	Key    uint64
+}
+
+// progress holds the scores of the root moves solved so far. progress IS thread safe
+type progress struct {
+	mutex  sync.Mutex
+	scores []Score
+	ok     []bool
+}
+
+func (p *progress) set(col int, score Score) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.scores[col], p.ok[col] = score, true
+}
+
+// AnalyzeCheckpointed returns the same scores as Analyze, but records each root move as it's solved, along with the
+// transposition table, in a checkpoint written every interval and when the analysis ends for any reason. If the
+// checkpoint already exists, it must be of the same position, in which case the analysis resumes from it, skipping
+// the moves already solved and warm-starting the table with its entries. As scores are exact, the result is the
+// same as that of an uninterrupted analysis
+func (s *Solver) AnalyzeCheckpointed(ctx context.Context, p bitboard.Position, c Checkpoint) ([]Score, []bool,
+	error) {
+	if p.Geometry() != s.geometry {
+		return nil, nil, fmt.Errorf("cannot analyze position: %w", bitboard.GeometryMismatchError{Want: s.geometry,
+			Got: p.Geometry()})
+	}
+	if c.Interval == 0 {
+		c.Interval = DefaultCheckpointInterval
+	}
+
+	pr := &progress{scores: make([]Score, s.geometry.Width), ok: make([]bool, s.geometry.Width)}
+	if err := s.loadCheckpoint(c.Path, p, pr); err != nil {
+		return nil, nil, err
+	}
+
+	stopped := make(chan struct{})
+	failed := make(chan error, 1)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(c.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.saveCheckpoint(c.Path, p, pr); err != nil {
+					failed <- err
+					return
+				}
+			case <-stopped:
+				return
+			}
+		}
+	}()
+
+	var err error
+	if !p.IsOver() {
+		for col := 0; col < s.geometry.Width && err == nil; col++ {
+			if !p.CanPlay(col) || pr.ok[col] {
+				continue
+			}
+			var score Score
+			if score, err = s.Solve(ctx, p.Play(col)); err == nil {
+				pr.set(col, -score)
+			}
+			select {
+			case err = <-failed:
+			default:
+			}
+		}
+	}
+	close(stopped)
+	wg.Wait()
+
+	if saveErr := s.saveCheckpoint(c.Path, p, pr); err == nil {
+		err = saveErr
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return pr.scores, pr.ok, nil
+}
+
+// SolveCheckpointed returns the same score as Solve, found as the best of the scores of the root moves with
+// AnalyzeCheckpointed, such that the solve can resume after being interrupted. Solving each root move in full makes
+// it slower than Solve, so it's best reserved for searches expected to take hours
+func (s *Solver) SolveCheckpointed(ctx context.Context, p bitboard.Position, c Checkpoint) (Score, error) {
+	if p.IsOver() {
+		return s.Solve(ctx, p)
+	}
+	scores, ok, err := s.AnalyzeCheckpointed(ctx, p, c)
+	if err != nil {
+		return 0, err
+	}
+	best, found := Score(0), false
+	for col, score := range scores {
+		if ok[col] && (!found || score > best) {
+			best, found = score, true
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("cannot solve position: %w", AnalysisError{})
+	}
+	return best, nil
+}
+
+// saveCheckpoint writes the checkpoint to a temporary file that then replaces the checkpoint file, such that an
+// interrupted write never corrupts an earlier checkpoint
+func (s *Solver) saveCheckpoint(path string, p bitboard.Position, pr *progress) error {
+	err := atomicfile.Write(path, func(w io.Writer) error {
+		return s.writeCheckpoint(w, p, pr)
+	})
+	if err != nil {
+		return fmt.Errorf("cannot write checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (s *Solver) writeCheckpoint(w io.Writer, p bitboard.Position, pr *progress) error {
+	bw := bufio.NewWriter(w)
+	h := checkpointHeader{Magic: checkpointMagic, Width: uint8(s.geometry.Width), Height: uint8(s.geometry.Height),
+		Key: p.Key()}
+	if err := binary.Write(bw, binary.LittleEndian, h); err != nil {
+		return err
+	}
+
+	pr.mutex.Lock()
+	moves := make([]int8, 2*len(pr.scores))
+	for col, score := range pr.scores {
+		if pr.ok[col] {
+			moves[2*col], moves[2*col+1] = int8(score), 1
+		}
+	}
+	pr.mutex.Unlock()
+	if err := binary.Write(bw, binary.LittleEndian, moves); err != nil {
+		return err
+	}
+
+	if _, err := s.table.WriteTo(bw); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// loadCheckpoint restores the progress and table entries of a checkpoint, if one exists
+func (s *Solver) loadCheckpoint(path string, p bitboard.Position, pr *progress) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot read checkpoint: %w", err)
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	var h checkpointHeader
+	if err := binary.Read(br, binary.LittleEndian, &h); err != nil {
+		return fmt.Errorf("cannot read checkpoint %v: %w", path, err)
+	}
+	if h.Magic != checkpointMagic {
+		return fmt.Errorf("cannot read checkpoint %v: %w", path, CheckpointFormatError(h.Magic))
+	}
+	g := bitboard.Geometry{Width: int(h.Width), Height: int(h.Height)}
+	if g != s.geometry || h.Key != p.Key() {
+		return fmt.Errorf("cannot resume from checkpoint %v: %w", path, CheckpointMismatchError{})
+	}
+
+	moves := make([]int8, 2*g.Width)
+	if err := binary.Read(br, binary.LittleEndian, moves); err != nil {
+		return fmt.Errorf("cannot read checkpoint %v: %w", path, err)
+	}
+	for col := range pr.scores {
+		if moves[2*col+1] != 0 {
+			pr.scores[col], pr.ok[col] = Score(moves[2*col]), true
+		}
+	}
+
+	t, err := ReadTable(br)
+	if err != nil {
+		return fmt.Errorf("cannot read checkpoint %v: %w", path, err)
+	}
+	s.table.merge(t)
+	return nil
+}
@@ -0,0 +1,112 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+)
+
+func TestSolver_AnalyzeCheckpointed(t *testing.T) {
+	g := bitboard.Geometry{Width: 5, Height: 4}
+	p := bitboard.New(g).Play(2)
+	s, _ := New(g, Config{})
+	want, wantOK, err := s.Analyze(context.Background(), p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Record a checkpoint of an analysis interrupted after solving the first two columns, with an empty table
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	interrupted, _ := New(g, Config{})
+	pr := &progress{scores: make([]Score, g.Width), ok: make([]bool, g.Width)}
+	pr.set(0, want[0])
+	pr.set(1, want[1])
+	if err := interrupted.saveCheckpoint(path, p, pr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resumed, _ := New(g, Config{})
+	got, gotOK, err := resumed.AnalyzeCheckpointed(context.Background(), p, Checkpoint{Path: path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for col := range want {
+		if got[col] != want[col] || gotOK[col] != wantOK[col] {
+			t.Errorf("column %v: expected %v (%v), observed %v (%v)", col, want[col], wantOK[col], got[col],
+				gotOK[col])
+		}
+	}
+
+	// The checkpoint now records every column, so resuming again searches nothing, and needs no time to do so
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	again, _ := New(g, Config{})
+	if _, _, err := again.AnalyzeCheckpointed(ctx, p, Checkpoint{Path: path}); err != nil || again.Nodes() != 0 {
+		t.Errorf("expected a finished analysis to resume without searching, observed %v nodes and error %v",
+			again.Nodes(), err)
+	}
+}
+
+// TestSolver_AnalyzeCheckpointed_table asserts that a resumed search warm-starts from the checkpointed table
+func TestSolver_AnalyzeCheckpointed_table(t *testing.T) {
+	g := bitboard.Geometry{Width: 5, Height: 4}
+	p := bitboard.New(g)
+	path := filepath.Join(t.TempDir(), "checkpoint")
+
+	// A cancelled analysis checkpoints the table of a completed solve, but none of the root moves
+	s, _ := New(g, Config{})
+	want, err := s.Solve(context.Background(), p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := s.AnalyzeCheckpointed(ctx, p, Checkpoint{Path: path}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected %v, observed %v", context.Canceled, err)
+	}
+
+	cold, _ := New(g, Config{})
+	if _, err := cold.SolveCheckpointed(context.Background(), p, Checkpoint{Path: filepath.Join(t.TempDir(),
+		"cold")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	warm, _ := New(g, Config{})
+	got, err := warm.SolveCheckpointed(context.Background(), p, Checkpoint{Path: path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != want {
+		t.Errorf("expected score %v, observed %v", want, got)
+	}
+	if warm.Nodes() >= cold.Nodes() {
+		t.Errorf("expected a warm start to search fewer than %v nodes, observed %v", cold.Nodes(), warm.Nodes())
+	}
+}
+
+func TestSolver_AnalyzeCheckpointed_errors(t *testing.T) {
+	g := bitboard.Geometry{Width: 4, Height: 4}
+	s, _ := New(g, Config{})
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "checkpoint")
+	if _, _, err := s.AnalyzeCheckpointed(context.Background(), bitboard.New(g), Checkpoint{Path: path}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, _, err := s.AnalyzeCheckpointed(context.Background(), bitboard.New(g).Play(0), Checkpoint{Path: path})
+	if !errors.As(err, new(CheckpointMismatchError)) {
+		t.Errorf("expected a checkpoint mismatch error, observed %v", err)
+	}
+
+	garbage := filepath.Join(dir, "garbage")
+	if err := ioutil.WriteFile(garbage, []byte("not a checkpoint file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = s.AnalyzeCheckpointed(context.Background(), bitboard.New(g), Checkpoint{Path: garbage})
+	if !errors.As(err, new(CheckpointFormatError)) {
+		t.Errorf("expected a checkpoint format error, observed %v", err)
+	}
+}
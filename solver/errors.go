@@ -0,0 +1,60 @@
+package solver
+
+import "fmt"
+
+// WorkerCountError defines an error used when a solver is configured with an invalid number of workers
+type WorkerCountError int
+
+func (e WorkerCountError) Error() string {
+	return fmt.Sprintf("worker count must not be negative, got %v", int(e))
+}
+
+// ReplacementError defines an error used when a table is configured with an unknown replacement strategy
+type ReplacementError Replacement
+
+func (e ReplacementError) Error() string {
+	return fmt.Sprintf("unknown replacement strategy %v", Replacement(e))
+}
+
+// BudgetError defines an error used when a table's memory budget is too small to hold a single bucket of entries
+type BudgetError int
+
+func (e BudgetError) Error() string {
+	return fmt.Sprintf("memory budget of %v bytes is too small for a table", int(e))
+}
+
+// TableFormatError defines an error used when reading a table from data that isn't a dumped table
+type TableFormatError [4]byte
+
+func (e TableFormatError) Error() string {
+	return fmt.Sprintf("unrecognized table format %q", e[:])
+}
+
+// TableSizeError defines an error used when reading a table whose header claims a number of entries that no table
+// holds, or that the data doesn't hold
+type TableSizeError uint64
+
+func (e TableSizeError) Error() string {
+	return fmt.Sprintf("table header claims %v entries, which the data does not hold", uint64(e))
+}
+
+// CheckpointFormatError defines an error used when reading a checkpoint from data that isn't a search checkpoint
+type CheckpointFormatError [4]byte
+
+func (e CheckpointFormatError) Error() string {
+	return fmt.Sprintf("unrecognized checkpoint format %q", e[:])
+}
+
+// AnalysisError defines an error used when an analysis of an unfinished position scores none of its moves
+type AnalysisError struct{}
+
+func (e AnalysisError) Error() string {
+	return "analysis scored no move of an unfinished position"
+}
+
+// CheckpointMismatchError defines an error used when resuming a search from the checkpoint of a different position
+type CheckpointMismatchError struct{}
+
+func (e CheckpointMismatchError) Error() string {
+	return "checkpoint is of a different position"
+}
@@ -0,0 +1,51 @@
+package solver
+
+import (
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+// Score is the exact game-theoretic value of a position from the perspective of the player to move. A positive score
+// is a win, a negative score a loss, and zero a draw. The magnitude of a non-zero score grows with the speed of the
+// win, being the number of stones the winner has yet to place when making the winning move, plus one
+type Score int
+
+// scoreOfWin returns the score of a position in which the player to move wins with their move on ply n, counted in
+// stones on the board before the winning move
+func scoreOfWin(g bitboard.Geometry, n int) Score {
+	return Score((g.Cells() + 1 - n) / 2)
+}
+
+// Winner returns the type of the player who wins the given position under optimal play, or NONE for a draw
+func (s Score) Winner(p bitboard.Position) board.Type {
+	switch {
+	case s > 0:
+		return p.Turn()
+	case s < 0 && p.Turn() == board.RED:
+		return board.BLUE
+	case s < 0:
+		return board.RED
+	default:
+		return board.NONE
+	}
+}
+
+// Plies returns the number of moves remaining in the given position under optimal play, including the winning move.
+// A drawn game lasts until the board is full
+func (s Score) Plies(p bitboard.Position) int {
+	if s == 0 {
+		return p.Geometry().Cells() - p.Moves()
+	}
+
+	a, parity := int(s), p.Moves()%2
+	if s < 0 {
+		a, parity = -a, 1-parity
+	}
+
+	// The winning move is made with n stones on the board, where n shares the parity of the winner's turns
+	n := p.Geometry().Cells() + 1 - 2*a
+	if n%2 != parity {
+		n--
+	}
+	return n - p.Moves() + 1
+}
@@ -0,0 +1,43 @@
+package solver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+func TestScore_Plies(t *testing.T) {
+	table := []struct {
+		name   string
+		g      bitboard.Geometry
+		moves  board.History
+		plies  int
+		winner board.Type
+	}{
+		{"red wins immediately", bitboard.Geometry{Width: 4, Height: 4}, board.History{0, 1, 0, 1, 0, 1}, 1, board.RED},
+		{"red wins in three", bitboard.Standard, board.History{3, 3, 4, 4}, 3, board.RED},
+		{"blue wins immediately", bitboard.Geometry{Width: 4, Height: 4}, board.History{0, 1, 0, 1, 0, 1, 3}, 1, board.BLUE},
+		{"red has won", bitboard.Geometry{Width: 4, Height: 4}, board.History{0, 1, 0, 1, 0, 1, 0}, 0, board.RED},
+	}
+
+	for _, r := range table {
+		p, err := bitboard.FromHistory(r.g, r.moves)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", r.name, err)
+		}
+		solver, _ := New(r.g, Config{})
+		s, err := solver.Solve(context.Background(), p)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", r.name, err)
+		}
+
+		if got := s.Plies(p); got != r.plies {
+			t.Errorf("%v: unexpected plies for score %v. Expected %v, observed %v", r.name, s, r.plies, got)
+		}
+		if got := s.Winner(p); got != r.winner {
+			t.Errorf("%v: unexpected winner for score %v. Expected %v, observed %v", r.name, s, r.winner, got)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package solver
+
+import (
+	"math/rand"
+	"sync/atomic"
+
+	"github.com/talglobus/fearsome/bitboard"
+)
+
+// pollInterval sets how many nodes a worker searches between checks of the shared stop flag, as a power of two
+const pollInterval = 1 << 10
+
+// worker holds the state of a single search goroutine
+type worker struct {
+	table   *Table
+	cells   int
+	order   []int // Column order used to break ties between equally promising moves
+	stop    *int32
+	aborted bool
+	nodes   uint64
+}
+
+// newWorker constructs a worker for the solver. The first worker searches the center-out column order, while the
+// remainder each shuffle it differently, so that Lazy SMP helpers explore the tree in a different order to the main
+// worker and fill the shared table with results it hasn't reached yet
+func newWorker(s *Solver, id int, stop *int32) *worker {
+	order := s.geometry.Order()
+	if id > 0 {
+		rng := rand.New(rand.NewSource(int64(id)))
+		rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	}
+	return &worker{
+		table: s.table,
+		cells: s.geometry.Cells(),
+		order: order,
+		stop:  stop,
+	}
+}
+
+// solve finds the exact score of a position in which the player to move can't win immediately, by repeatedly
+// narrowing the range of possible scores with null-window searches. Returns false if the search was stopped
+func (w *worker) solve(p bitboard.Position) (int, bool) {
+	min, max := -(w.cells-p.Moves())/2, (w.cells+1-p.Moves())/2
+	for min < max {
+		// Probe the middle of the range, biased towards zero, as scores near zero are cheapest to prove
+		med := min + (max-min)/2
+		if med <= 0 && min/2 < med {
+			med = min / 2
+		} else if med >= 0 && max/2 > med {
+			med = max / 2
+		}
+
+		r := w.negamax(p, med, med+1)
+		if w.aborted {
+			return 0, false
+		}
+		if r <= med {
+			max = r
+		} else {
+			min = r
+		}
+	}
+	return min, true
+}
+
+// negamax returns the score of a position in which the player to move can't win immediately, or a bound on it if the
+// score falls outside of the window between alpha and beta
+func (w *worker) negamax(p bitboard.Position, alpha, beta int) int {
+	w.nodes++
+	if w.nodes%pollInterval == 0 && atomic.LoadInt32(w.stop) != 0 {
+		w.aborted = true
+	}
+	if w.aborted {
+		return 0
+	}
+
+	next := p.NonLosingMoves()
+	if next == 0 {
+		// Every move loses, with the opponent winning on their next move
+		return -(w.cells - p.Moves()) / 2
+	}
+	if p.Moves() >= w.cells-2 {
+		// Neither player can win with so few squares remaining
+		return 0
+	}
+
+	// The opponent can't win on their next move, so the score is bounded below
+	if min := -(w.cells - 2 - p.Moves()) / 2; alpha < min {
+		alpha = min
+		if alpha >= beta {
+			return alpha
+		}
+	}
+
+	// Nor can the player to move win immediately, so the score is bounded above
+	if max := (w.cells - 1 - p.Moves()) / 2; beta > max {
+		beta = max
+		if alpha >= beta {
+			return beta
+		}
+	}
+
+	// A previous search of the position may have found the score, or tighter bounds on it
+	key := p.Key()
+	if e, ok := w.table.Probe(key); ok {
+		switch score := int(e.Score); e.Bound {
+		case Exact:
+			return score
+		case Lower:
+			if score > alpha {
+				alpha = score
+				if alpha >= beta {
+					return alpha
+				}
+			}
+		case Upper:
+			if score < beta {
+				beta = score
+				if alpha >= beta {
+					return beta
+				}
+			}
+		}
+	}
+
+	depth, bound := w.cells-p.Moves(), Upper
+	var buf [64]int
+	for _, col := range w.sort(p, next, buf[:0]) {
+		score := -w.negamax(p.Play(col), -beta, -alpha)
+		if w.aborted {
+			return 0
+		}
+		if score >= beta {
+			w.table.Store(key, Entry{Score: Score(score), Bound: Lower, Depth: depth})
+			return score
+		}
+		if score > alpha {
+			alpha, bound = score, Exact
+		}
+	}
+
+	// Had no move improved on alpha, the score could be anything up to alpha, making it only an upper bound
+	w.table.Store(key, Entry{Score: Score(alpha), Bound: bound, Depth: depth})
+	return alpha
+}
+
+// sort appends the candidate moves to buf, most promising first, by the number of winning squares each creates,
+// breaking ties with the worker's column order
+func (w *worker) sort(p bitboard.Position, candidates uint64, buf []int) []int {
+	var scores [64]int
+	for _, col := range w.order {
+		move := candidates & p.ColumnMask(col)
+		if move == 0 {
+			continue
+		}
+
+		// Insertion sort, placing the move after every move scoring at least as well
+		score := p.MoveScore(move)
+		buf = append(buf, col)
+		j := len(buf) - 1
+		for ; j > 0 && scores[j-1] < score; j-- {
+			buf[j], scores[j] = buf[j-1], scores[j-1]
+		}
+		buf[j], scores[j] = col, score
+	}
+	return buf
+}
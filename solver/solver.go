@@ -0,0 +1,228 @@
+// Package solver computes the exact game-theoretic value of Connect Four positions of any geometry, using a
+// negamax search with alpha-beta pruning, a transposition table, and null-window probing of the score range.
+//
+// Searches may run in parallel using Lazy SMP, in which every worker searches the same root position, sharing what
+// they learn through a common lock-free transposition table. Workers diversify their move ordering so as to explore
+// different parts of the tree, with the first worker to finish providing the result. As every entry in the table is
+// a sound bound, regardless of which worker wrote it, the parallel search always returns the same value as a
+// single-threaded one.
+package solver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+// Config holds the tunable parameters of a Solver
+type Config struct {
+	// Workers sets the number of goroutines searching in parallel, with zero taken to mean a single worker
+	Workers int
+
+	// Table sets the transposition table, which must be of the solver's geometry. If nil, a two-tier table of
+	// DefaultBudget bytes is constructed. Passing a table restored from disk warm-starts the solver with its entries
+	Table *Table
+}
+
+// Solver solves positions of a single geometry, retaining its transposition table between solves such that
+// related positions solve faster. Solver IS thread safe, although concurrent solves compete for the same table
+type Solver struct {
+	geometry bitboard.Geometry
+	workers  int
+	table    *Table
+	nodes    uint64 // Accessed atomically
+}
+
+// New constructs a Solver for positions of the given geometry
+func New(g bitboard.Geometry, c Config) (*Solver, error) {
+	if err := g.Validate(); err != nil {
+		return nil, fmt.Errorf("cannot construct solver: %w", err)
+	}
+	if c.Workers < 0 {
+		return nil, fmt.Errorf("cannot construct solver with %v workers: %w", c.Workers, WorkerCountError(c.Workers))
+	}
+	if c.Workers == 0 {
+		c.Workers = 1
+	}
+
+	if c.Table == nil {
+		t, err := NewTable(g, DefaultBudget, TwoTier)
+		if err != nil {
+			return nil, fmt.Errorf("cannot construct solver: %w", err)
+		}
+		c.Table = t
+	} else if c.Table.Geometry() != g {
+		return nil, fmt.Errorf("cannot construct solver with table: %w",
+			bitboard.GeometryMismatchError{Want: g, Got: c.Table.Geometry()})
+	}
+
+	return &Solver{
+		geometry: g,
+		workers:  c.Workers,
+		table:    c.Table,
+	}, nil
+}
+
+// Geometry returns the geometry of the positions the solver can solve
+func (s *Solver) Geometry() bitboard.Geometry {
+	return s.geometry
+}
+
+// Table returns the solver's transposition table, such that it can be saved to warm-start a later solve
+func (s *Solver) Table() *Table {
+	return s.table
+}
+
+// Nodes returns the total number of positions searched by the solver across all solves and workers
+func (s *Solver) Nodes() uint64 {
+	return atomic.LoadUint64(&s.nodes)
+}
+
+// Solve returns the exact score of the position. Positions in which the game has already ended are scored as well,
+// as a loss for the player to move if the opponent has won, or a draw if the board is full. Solve returns the
+// context's error if the context is done before the solve completes
+func (s *Solver) Solve(ctx context.Context, p bitboard.Position) (Score, error) {
+	if p.Geometry() != s.geometry {
+		return 0, fmt.Errorf("cannot solve position: %w", bitboard.GeometryMismatchError{Want: s.geometry,
+			Got: p.Geometry()})
+	}
+
+	// Handle finished games and immediate wins up front, as the search assumes neither is the case
+	switch {
+	case p.Winner() != board.NONE:
+		return -scoreOfWin(s.geometry, p.Moves()-1), nil
+	case p.IsFull():
+		return 0, nil
+	case p.CanWinNext():
+		return scoreOfWin(s.geometry, p.Moves()), nil
+	}
+
+	var stop int32
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			atomic.StoreInt32(&stop, 1)
+		case <-done:
+		}
+	}()
+
+	results := make(chan int, s.workers)
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			w := newWorker(s, id, &stop)
+			score, ok := w.solve(p)
+			atomic.AddUint64(&s.nodes, w.nodes)
+			if ok {
+				results <- score
+			}
+		}(i)
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+
+	// The first worker to finish has the exact score, so every other worker can then be stopped
+	select {
+	case score := <-results:
+		atomic.StoreInt32(&stop, 1)
+		<-finished
+		return Score(score), nil
+	case <-finished:
+		select {
+		case score := <-results:
+			return Score(score), nil
+		default:
+			return 0, ctx.Err()
+		}
+	}
+}
+
+// SolveBoard returns the exact score of a board of the standard geometry, from the perspective of the player to move
+func (s *Solver) SolveBoard(ctx context.Context, b board.Board) (Score, error) {
+	p, err := bitboard.FromBoard(b)
+	if err != nil {
+		return 0, fmt.Errorf("cannot solve board: %w", err)
+	}
+	return s.Solve(ctx, p)
+}
+
+// Analyze returns the exact score of each column of the position, from the perspective of the player to move.
+// Unplayable columns are reported as not ok
+func (s *Solver) Analyze(ctx context.Context, p bitboard.Position) (scores []Score, ok []bool, err error) {
+	scores, ok = make([]Score, s.geometry.Width), make([]bool, s.geometry.Width)
+	if p.IsOver() {
+		return scores, ok, nil
+	}
+
+	for col := range scores {
+		if !p.CanPlay(col) {
+			continue
+		}
+		score, err := s.Solve(ctx, p.Play(col))
+		if err != nil {
+			return nil, nil, err
+		}
+		scores[col], ok[col] = -score, true
+	}
+	return scores, ok, nil
+}
+
+// Optimal returns the exact score of the position along with every move achieving it, ordered from the center
+// outwards. Positions in which the game has already ended have no optimal moves
+func (s *Solver) Optimal(ctx context.Context, p bitboard.Position) (Score, []board.Move, error) {
+	if p.IsOver() {
+		score, err := s.Solve(ctx, p)
+		return score, nil, err
+	}
+
+	scores, ok, err := s.Analyze(ctx, p)
+	if err != nil {
+		return 0, nil, err
+	}
+	best, moves := OptimalMoves(p.Geometry(), scores, ok)
+	return best, moves, nil
+}
+
+// OptimalMoves returns the best of the scores of a position's columns, as reported by Analyze, along with every
+// column achieving it, ordered from the center outwards. If no column is ok, there are no optimal moves
+func OptimalMoves(g bitboard.Geometry, scores []Score, ok []bool) (Score, []board.Move) {
+	best, moves := Score(0), []board.Move(nil)
+	for _, col := range g.Order() {
+		switch {
+		case !ok[col]:
+		case len(moves) == 0 || scores[col] > best:
+			best, moves = scores[col], []board.Move{board.Move(col)}
+		case scores[col] == best:
+			moves = append(moves, board.Move(col))
+		}
+	}
+	return best, moves
+}
+
+// Variation returns a principal variation of the position: a line of optimal play continuing until the game ends,
+// or until the given number of moves has been played if positive, choosing the most central optimal move at every
+// turn
+func (s *Solver) Variation(ctx context.Context, p bitboard.Position, limit int) (board.History, error) {
+	var line board.History
+	for !p.IsOver() && (limit <= 0 || len(line) < limit) {
+		_, moves, err := s.Optimal(ctx, p)
+		if err != nil {
+			return line, err
+		}
+		line = append(line, moves[0])
+		p = p.Play(int(moves[0]))
+	}
+	return line, nil
+}
@@ -0,0 +1,240 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+// reference solves a position by exhaustive minimax, memoized by key, as an obviously-correct baseline
+func reference(p bitboard.Position, memo map[uint64]Score) Score {
+	if s, ok := memo[p.Key()]; ok {
+		return s
+	}
+
+	g := p.Geometry()
+	var best Score
+	switch {
+	case p.Winner() != board.NONE:
+		best = -scoreOfWin(g, p.Moves()-1)
+	case p.IsFull():
+		best = 0
+	default:
+		best = -Score(g.Cells())
+		for _, m := range p.LegalMoves() {
+			if s := -reference(p.Play(int(m)), memo); s > best {
+				best = s
+			}
+		}
+	}
+
+	memo[p.Key()] = best
+	return best
+}
+
+// randomPosition plays the given number of random moves, avoiding those that end the game
+func randomPosition(rng *rand.Rand, g bitboard.Geometry, moves int) bitboard.Position {
+	for {
+		p := bitboard.New(g)
+		for p.Moves() < moves && !p.IsOver() {
+			legal := p.LegalMoves()
+			p = p.Play(int(legal[rng.Intn(len(legal))]))
+		}
+		if !p.IsOver() {
+			return p
+		}
+	}
+}
+
+func TestSolver_Solve(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	geometries := []bitboard.Geometry{
+		{Width: 4, Height: 4}, {Width: 5, Height: 4}, {Width: 4, Height: 5}, {Width: 3, Height: 6}, {Width: 1, Height: 6},
+	}
+
+	for _, g := range geometries {
+		s, err := New(g, Config{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		memo := map[uint64]Score{}
+
+		for i := 0; i < 100; i++ {
+			p := randomPosition(rng, g, rng.Intn(g.Cells()))
+			got, err := s.Solve(context.Background(), p)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := reference(p, memo); got != want {
+				t.Fatalf("%v position solved incorrectly. Expected %v, observed %v\n%v", g, want, got, p)
+			}
+		}
+	}
+}
+
+// TestSolver_parallel asserts that the parallel search agrees with the single-threaded one, including on the classic
+// board, where the search is deep enough for helpers to feed the main worker through the shared table
+func TestSolver_parallel(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	table := []struct {
+		g     bitboard.Geometry
+		moves int
+	}{
+		{bitboard.Geometry{Width: 5, Height: 4}, 0},
+		{bitboard.Geometry{Width: 6, Height: 4}, 2},
+		{bitboard.Standard, 26},
+		{bitboard.Standard, 22},
+	}
+
+	for _, r := range table {
+		for i := 0; i < 5; i++ {
+			p := randomPosition(rng, r.g, r.moves)
+
+			single, _ := New(r.g, Config{Workers: 1})
+			want, err := single.Solve(context.Background(), p)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			parallel, _ := New(r.g, Config{Workers: 4})
+			got, err := parallel.Solve(context.Background(), p)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != want {
+				t.Fatalf("%v position solved differently in parallel. Expected %v, observed %v\n%v",
+					r.g, want, got, p)
+			}
+		}
+	}
+}
+
+func TestSolver_Solve_cancelled(t *testing.T) {
+	s, _ := New(bitboard.Standard, Config{Workers: 2})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := s.Solve(ctx, bitboard.New(bitboard.Standard)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v solving with cancelled context, observed %v", context.Canceled, err)
+	}
+}
+
+func TestSolver_SolveBoard(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	b, p := board.New(), bitboard.New(bitboard.Standard)
+	for p.Moves() < 24 {
+		col := rng.Intn(bitboard.Standard.Width)
+		if !p.CanPlay(col) || p.Play(col).IsOver() {
+			continue
+		}
+		if _, _, err := b.Move(col); err != nil {
+			t.Fatalf("unexpected error making move: %v", err)
+		}
+		p = p.Play(col)
+	}
+
+	s, _ := New(bitboard.Standard, Config{Workers: 2})
+	got, err := s.SolveBoard(context.Background(), b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	single, _ := New(bitboard.Standard, Config{})
+	if want, _ := single.Solve(context.Background(), p); got != want {
+		t.Errorf("board solved incorrectly. Expected %v, observed %v\n%v", want, got, b)
+	}
+}
+
+func TestSolver_Analyze(t *testing.T) {
+	g := bitboard.Geometry{Width: 5, Height: 4}
+	s, _ := New(g, Config{})
+	p, _ := bitboard.FromHistory(g, board.History{2, 2, 1})
+
+	scores, ok, err := s.Analyze(context.Background(), p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	memo := map[uint64]Score{}
+	for col := range scores {
+		if !ok[col] {
+			t.Fatalf("column %v should be playable", col)
+		}
+		if want := -reference(p.Play(col), memo); scores[col] != want {
+			t.Errorf("column %v scored incorrectly. Expected %v, observed %v", col, want, scores[col])
+		}
+	}
+}
+
+func TestSolver_Optimal(t *testing.T) {
+	g := bitboard.Geometry{Width: 5, Height: 4}
+	s, _ := New(g, Config{})
+	rng := rand.New(rand.NewSource(2))
+	memo := map[uint64]Score{}
+
+	for i := 0; i < 20; i++ {
+		p := randomPosition(rng, g, rng.Intn(10))
+		score, moves, err := s.Optimal(context.Background(), p)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := reference(p, memo); score != want {
+			t.Errorf("expected score %v, observed %v\n%v", want, score, p)
+		}
+		reported := map[board.Move]bool{}
+		for _, m := range moves {
+			reported[m] = true
+		}
+		for _, m := range p.LegalMoves() {
+			optimal := -reference(p.Play(int(m)), memo) == score
+			if optimal != reported[m] {
+				t.Errorf("column %v reported as optimal %v, but is %v\n%v", m, !optimal, optimal, p)
+			}
+		}
+	}
+}
+
+func TestSolver_Variation(t *testing.T) {
+	g := bitboard.Geometry{Width: 5, Height: 4}
+	s, _ := New(g, Config{})
+	p, _ := bitboard.FromHistory(g, board.History{2, 2, 1})
+	score, _ := s.Solve(context.Background(), p)
+
+	line, err := s.Variation(context.Background(), p, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	end, err := p.PlayMoves(line)
+	if err != nil || !end.IsOver() {
+		t.Fatalf("variation %v should be a legal line ending the game: %v", line, err)
+	}
+	if len(line) != score.Plies(p) || score.Winner(p) != end.Winner() {
+		t.Errorf("variation %v should end in %v moves with winner %v, as scored", line, score.Plies(p),
+			score.Winner(p))
+	}
+
+	if short, _ := s.Variation(context.Background(), p, 2); !short.Equals(line[:2]) {
+		t.Errorf("expected a limited variation to be a prefix of the full one, observed %v", short)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(bitboard.Geometry{Width: 9, Height: 7}, Config{}); !errors.As(err, new(bitboard.GeometryError)) {
+		t.Errorf("expected %T, observed %v", bitboard.GeometryError{}, err)
+	}
+	if _, err := New(bitboard.Standard, Config{Workers: -1}); !errors.Is(err, WorkerCountError(-1)) {
+		t.Errorf("expected %v, observed %v", WorkerCountError(-1), err)
+	}
+}
+
+func TestNew_tableGeometry(t *testing.T) {
+	tt, _ := NewTable(bitboard.Geometry{Width: 5, Height: 4}, 1<<10, TwoTier)
+	if _, err := New(bitboard.Standard, Config{Table: tt}); !errors.As(err, new(bitboard.GeometryMismatchError)) {
+		t.Errorf("expected %T, observed %v", bitboard.GeometryMismatchError{}, err)
+	}
+}
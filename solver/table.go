@@ -0,0 +1,332 @@
+package solver
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/internal/chunked"
+)
+
+// Bound is an enumerated type indicating how a score stored in a Table relates to the true score of its position
+type Bound uint8
+
+// Exact, Lower, and Upper indicate a stored score is the true score, at most the true score, or at least the true
+// score, respectively. Bounds arise when a search window cuts the search of a position short
+const (
+	Exact Bound = iota + 1
+	Lower
+	Upper
+)
+
+func (b Bound) String() string {
+	switch b {
+	case Exact:
+		return "exact"
+	case Lower:
+		return "lower"
+	case Upper:
+		return "upper"
+	default:
+		return "none"
+	}
+}
+
+// Replacement is an enumerated type selecting which entry a Table evicts when two positions compete for a slot
+type Replacement uint8
+
+// DepthPreferred keeps whichever entry has the greater depth, as deeper entries save more work when hit.
+// AlwaysReplace keeps the most recent entry, which best suits a search that never revisits old parts of the tree.
+// TwoTier pairs a depth-preferred slot with an always-replace slot, storing new entries in the former if they're
+// at least as deep, and in the latter otherwise, getting the benefits of both for twice the memory per bucket
+const (
+	DepthPreferred Replacement = iota
+	AlwaysReplace
+	TwoTier
+)
+
+func (r Replacement) String() string {
+	switch r {
+	case DepthPreferred:
+		return "depth-preferred"
+	case AlwaysReplace:
+		return "always-replace"
+	case TwoTier:
+		return "two-tier"
+	default:
+		return fmt.Sprintf("Replacement(%d)", uint8(r))
+	}
+}
+
+// Entry holds what a Table knows about a position: a score, how it bounds the true score, and the depth of the search
+// that produced it, measured in empty squares remaining
+type Entry struct {
+	Score Score
+	Bound Bound
+	Depth int
+}
+
+func (e Entry) pack() uint64 {
+	return uint64(uint8(int8(e.Score))) | uint64(e.Bound)<<8 | uint64(uint8(e.Depth))<<16
+}
+
+func unpack(data uint64) Entry {
+	return Entry{
+		Score: Score(int8(uint8(data))),
+		Bound: Bound(data >> 8 & 0xff),
+		Depth: int(data >> 16 & 0xff),
+	}
+}
+
+// slot is a single entry of the table. To remain lock-free while shared between workers, the slot stores the key
+// XORed with the data, such that a torn write — one word from one writer, the other from another — fails the key
+// check on read rather than returning data belonging to a different position
+type slot struct {
+	check uint64 // key ^ data
+	data  uint64
+}
+
+// slotSize is the memory footprint of a slot in bytes
+const slotSize = 16
+
+// DefaultBudget sets the memory budget of the table constructed for a Solver when none is configured, in bytes
+const DefaultBudget = 64 << 20
+
+// Table is a fixed-size, lock-free transposition table, caching bounds on the scores of previously searched positions
+// of a single geometry. Table IS thread safe, and may be shared between solvers of the same geometry
+type Table struct {
+	geometry    bitboard.Geometry
+	replacement Replacement
+	slots       []slot
+}
+
+// NewTable constructs a Table for positions of the given geometry, holding as many entries as fit in the budget,
+// given in bytes
+func NewTable(g bitboard.Geometry, budget int, r Replacement) (*Table, error) {
+	if err := g.Validate(); err != nil {
+		return nil, fmt.Errorf("cannot construct table: %w", err)
+	}
+	if r > TwoTier {
+		return nil, fmt.Errorf("cannot construct table: %w", ReplacementError(r))
+	}
+
+	// Keep two-tier tables to whole buckets
+	n := budget / slotSize
+	if r == TwoTier {
+		n -= n % 2
+	}
+	if n < 1 || (r == TwoTier && n < 2) {
+		return nil, fmt.Errorf("cannot construct table: %w", BudgetError(budget))
+	}
+
+	return &Table{geometry: g, replacement: r, slots: make([]slot, n)}, nil
+}
+
+// Geometry returns the geometry of the positions whose entries the table holds
+func (t *Table) Geometry() bitboard.Geometry {
+	return t.geometry
+}
+
+// Replacement returns the replacement strategy of the table
+func (t *Table) Replacement() Replacement {
+	return t.replacement
+}
+
+// Size returns the number of entries the table can hold
+func (t *Table) Size() int {
+	return len(t.slots)
+}
+
+// Bytes returns the memory occupied by the table's entries
+func (t *Table) Bytes() int {
+	return len(t.slots) * slotSize
+}
+
+// bucket returns the slots in which the key may be stored, either one or two depending on the replacement strategy
+func (t *Table) bucket(key uint64) []slot {
+	if t.replacement == TwoTier {
+		i := key % uint64(len(t.slots)/2) * 2
+		return t.slots[i : i+2]
+	}
+	i := key % uint64(len(t.slots))
+	return t.slots[i : i+1]
+}
+
+func load(s *slot) (key uint64, data uint64) {
+	check, data := atomic.LoadUint64(&s.check), atomic.LoadUint64(&s.data)
+	return check ^ data, data
+}
+
+func store(s *slot, key uint64, data uint64) {
+	atomic.StoreUint64(&s.data, data)
+	atomic.StoreUint64(&s.check, key^data)
+}
+
+// Probe returns the entry stored for the key, if any. Keys are never zero, so empty slots never match
+func (t *Table) Probe(key uint64) (Entry, bool) {
+	bucket := t.bucket(key)
+	for i := range bucket {
+		if k, data := load(&bucket[i]); k == key {
+			return unpack(data), true
+		}
+	}
+	return Entry{}, false
+}
+
+// Store records an entry for the key, subject to the table's replacement strategy
+func (t *Table) Store(key uint64, e Entry) {
+	bucket, data := t.bucket(key), e.pack()
+
+	switch t.replacement {
+	case AlwaysReplace:
+		store(&bucket[0], key, data)
+	case DepthPreferred:
+		// Replace shallower entries, and always overwrite stale entries for the same key
+		if k, old := load(&bucket[0]); k == key || unpack(old).Depth <= e.Depth {
+			store(&bucket[0], key, data)
+		}
+	case TwoTier:
+		if k, old := load(&bucket[0]); k == key || unpack(old).Depth <= e.Depth {
+			store(&bucket[0], key, data)
+		} else {
+			store(&bucket[1], key, data)
+		}
+	}
+}
+
+// Clear empties the table
+func (t *Table) Clear() {
+	for i := range t.slots {
+		store(&t.slots[i], 0, 0)
+	}
+}
+
+// merge stores every entry of another table of the same geometry, subject to the table's replacement strategy
+func (t *Table) merge(o *Table) {
+	for i := range o.slots {
+		if key, data := load(&o.slots[i]); key != 0 {
+			t.Store(key, unpack(data))
+		}
+	}
+}
+
+// tableMagic identifies a file as a dumped Table, and the version of its format
+var tableMagic = [4]byte{'F', 'T', 'T', 1}
+
+// tableHeader precedes the slots of a dumped Table
+type tableHeader struct {
+	Magic       [4]byte
+	Width       uint8
+	Height      uint8
+	Replacement Replacement
+	_           uint8
+	Slots       uint64
+}
+
+// WriteTo dumps the table to the writer, such that it can be restored with ReadTable. Writes made to the table while
+// it's being dumped may or may not be included
+func (t *Table) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	h := tableHeader{
+		Magic:       tableMagic,
+		Width:       uint8(t.geometry.Width),
+		Height:      uint8(t.geometry.Height),
+		Replacement: t.replacement,
+		Slots:       uint64(len(t.slots)),
+	}
+	if err := binary.Write(bw, binary.LittleEndian, h); err != nil {
+		return 0, fmt.Errorf("cannot write table header: %w", err)
+	}
+	n := int64(binary.Size(h))
+
+	var buf [slotSize]byte
+	for i := range t.slots {
+		key, data := load(&t.slots[i])
+		binary.LittleEndian.PutUint64(buf[:8], key^data)
+		binary.LittleEndian.PutUint64(buf[8:], data)
+		if _, err := bw.Write(buf[:]); err != nil {
+			return n, fmt.Errorf("cannot write table entries: %w", err)
+		}
+		n += slotSize
+	}
+
+	if err := bw.Flush(); err != nil {
+		return n, fmt.Errorf("cannot write table entries: %w", err)
+	}
+	return n, nil
+}
+
+// ReadTable restores a table dumped with WriteTo
+func ReadTable(r io.Reader) (*Table, error) {
+	br := bufio.NewReader(r)
+	var h tableHeader
+	if err := binary.Read(br, binary.LittleEndian, &h); err != nil {
+		return nil, fmt.Errorf("cannot read table header: %w", err)
+	}
+	if h.Magic != tableMagic {
+		return nil, fmt.Errorf("cannot read table: %w", TableFormatError(h.Magic))
+	}
+
+	g := bitboard.Geometry{Width: int(h.Width), Height: int(h.Height)}
+	if err := g.Validate(); err != nil {
+		return nil, fmt.Errorf("cannot read table: %w", err)
+	}
+	if h.Replacement > TwoTier {
+		return nil, fmt.Errorf("cannot read table: %w", ReplacementError(h.Replacement))
+	}
+	if h.Slots == 0 || h.Replacement == TwoTier && h.Slots%2 != 0 {
+		return nil, fmt.Errorf("cannot read table: %w", TableSizeError(h.Slots))
+	}
+
+	t := &Table{geometry: g, replacement: h.Replacement}
+	err := chunked.Read(h.Slots, func(count int) error {
+		buf := make([]byte, count*slotSize)
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return err
+		}
+		for i := 0; i < len(buf); i += slotSize {
+			t.slots = append(t.slots, slot{check: binary.LittleEndian.Uint64(buf[i:]),
+				data: binary.LittleEndian.Uint64(buf[i+8:])})
+		}
+		return nil
+	})
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("cannot read table entries: %w", TableSizeError(h.Slots))
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot read table entries: %w", err)
+	}
+	return t, nil
+}
+
+// Save dumps the table to a file at the given path, replacing any existing file
+func (t *Table) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("cannot save table: %w", err)
+	}
+	if _, err := t.WriteTo(f); err != nil {
+		f.Close()
+		return fmt.Errorf("cannot save table to %v: %w", path, err)
+	}
+	return f.Close()
+}
+
+// LoadTable restores a table saved to a file with Save
+func LoadTable(path string) (*Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load table: %w", err)
+	}
+	defer f.Close()
+
+	t, err := ReadTable(f)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load table from %v: %w", path, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,180 @@
+package solver
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+)
+
+func TestNewTable(t *testing.T) {
+	g := bitboard.Geometry{Width: 5, Height: 4}
+	table := []struct {
+		budget int
+		r      Replacement
+		size   int
+		err    error
+	}{
+		{1 << 10, DepthPreferred, 64, nil},
+		{1<<10 + 15, AlwaysReplace, 64, nil},
+		{48, TwoTier, 2, nil},
+		{16, DepthPreferred, 1, nil},
+		{16, TwoTier, 0, BudgetError(16)},
+		{0, AlwaysReplace, 0, BudgetError(0)},
+		{1 << 10, TwoTier + 1, 0, ReplacementError(TwoTier + 1)},
+	}
+
+	for _, r := range table {
+		tt, err := NewTable(g, r.budget, r.r)
+		if !errors.Is(err, r.err) {
+			t.Errorf("%v table of %v bytes produced unexpected error. Expected %v, observed %v",
+				r.r, r.budget, r.err, err)
+			continue
+		}
+		if err == nil && tt.Size() != r.size {
+			t.Errorf("%v table of %v bytes has unexpected size. Expected %v, observed %v",
+				r.r, r.budget, r.size, tt.Size())
+		}
+	}
+}
+
+func TestTable_Store(t *testing.T) {
+	g := bitboard.Geometry{Width: 5, Height: 4}
+	shallow := Entry{Score: -2, Bound: Upper, Depth: 3}
+	deep := Entry{Score: 4, Bound: Exact, Depth: 12}
+
+	// Keys 1 and 2 collide in a single-slot table, and 1 and 3 collide in a single-bucket two-tier table
+	table := []struct {
+		r          Replacement
+		budget     int
+		first, sec uint64
+		keepFirst  bool
+		keepSecond bool
+	}{
+		{DepthPreferred, slotSize, 1, 2, true, false},
+		{AlwaysReplace, slotSize, 1, 2, false, true},
+		{TwoTier, 2 * slotSize, 1, 3, true, true},
+	}
+
+	for _, r := range table {
+		tt, _ := NewTable(g, r.budget, r.r)
+		tt.Store(r.first, deep)
+		tt.Store(r.sec, shallow)
+
+		e, ok := tt.Probe(r.first)
+		if ok != r.keepFirst || (ok && e != deep) {
+			t.Errorf("%v table kept deep entry: %v (%v), expected %v", r.r, ok, e, r.keepFirst)
+		}
+		e, ok = tt.Probe(r.sec)
+		if ok != r.keepSecond || (ok && e != shallow) {
+			t.Errorf("%v table kept shallow entry: %v (%v), expected %v", r.r, ok, e, r.keepSecond)
+		}
+	}
+}
+
+func TestTable_Store_sameKey(t *testing.T) {
+	// A stale entry for the same key is always replaced, even by a shallower one
+	tt, _ := NewTable(bitboard.Geometry{Width: 5, Height: 4}, slotSize, DepthPreferred)
+	tt.Store(7, Entry{Score: 1, Bound: Lower, Depth: 10})
+	tt.Store(7, Entry{Score: 3, Bound: Exact, Depth: 2})
+
+	if e, _ := tt.Probe(7); e.Bound != Exact || e.Score != 3 {
+		t.Errorf("expected updated entry for key, observed %v", e)
+	}
+}
+
+// TestTable_persistence asserts that a dumped table restores to the same entries, and warm-starts a later solve
+func TestTable_persistence(t *testing.T) {
+	g := bitboard.Geometry{Width: 5, Height: 4}
+	for _, r := range []Replacement{DepthPreferred, AlwaysReplace, TwoTier} {
+		tt, _ := NewTable(g, 1<<20, r)
+		s, _ := New(g, Config{Table: tt})
+		p := bitboard.New(g)
+		want, err := s.Solve(context.Background(), p)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		cold := s.Nodes()
+
+		path := filepath.Join(t.TempDir(), "table")
+		if err := tt.Save(path); err != nil {
+			t.Fatalf("unexpected error saving table: %v", err)
+		}
+		restored, err := LoadTable(path)
+		if err != nil {
+			t.Fatalf("unexpected error loading table: %v", err)
+		}
+		if restored.Replacement() != r || restored.Geometry() != g || restored.Size() != tt.Size() {
+			t.Fatalf("restored %v table has unexpected configuration", r)
+		}
+
+		warm, _ := New(g, Config{Table: restored})
+		got, err := warm.Solve(context.Background(), p)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != want {
+			t.Errorf("warm-started %v solve produced unexpected score. Expected %v, observed %v", r, want, got)
+		}
+		if warm.Nodes() >= cold {
+			t.Errorf("warm-started %v solve searched %v nodes, expected fewer than the cold %v", r, warm.Nodes(), cold)
+		}
+	}
+}
+
+// TestTable_smallBudget asserts that a table too small to hold much of the tree still produces exact results
+func TestTable_smallBudget(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	g := bitboard.Geometry{Width: 4, Height: 4}
+	memo := map[uint64]Score{}
+
+	for _, r := range []Replacement{DepthPreferred, AlwaysReplace, TwoTier} {
+		tt, _ := NewTable(g, 64*slotSize, r)
+		s, _ := New(g, Config{Table: tt, Workers: 2})
+		for i := 0; i < 30; i++ {
+			p := randomPosition(rng, g, rng.Intn(8))
+			got, err := s.Solve(context.Background(), p)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := reference(p, memo); got != want {
+				t.Fatalf("%v table solved incorrectly. Expected %v, observed %v\n%v", r, want, got, p)
+			}
+		}
+	}
+}
+
+func TestReadTable(t *testing.T) {
+	if _, err := ReadTable(bytes.NewReader([]byte("not a table at all"))); !errors.As(err, new(TableFormatError)) {
+		t.Errorf("expected %T, observed %v", TableFormatError{}, err)
+	}
+
+	tt, _ := NewTable(bitboard.Geometry{Width: 5, Height: 4}, 1<<10, TwoTier)
+	var buf bytes.Buffer
+	if _, err := tt.WriteTo(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := ReadTable(bytes.NewReader(buf.Bytes()[:buf.Len()-1])); err == nil {
+		t.Errorf("expected error reading truncated table, observed nil")
+	}
+}
+
+func TestReadTable_size(t *testing.T) {
+	for _, h := range []tableHeader{
+		{Magic: tableMagic, Width: 7, Height: 6, Replacement: TwoTier, Slots: 1 << 60},
+		{Magic: tableMagic, Width: 7, Height: 6, Replacement: TwoTier, Slots: 3},
+		{Magic: tableMagic, Width: 7, Height: 6, Replacement: AlwaysReplace, Slots: 0},
+	} {
+		var buf bytes.Buffer
+		binary.Write(&buf, binary.LittleEndian, h)
+		buf.Write(make([]byte, 4*slotSize))
+		if _, err := ReadTable(&buf); !errors.As(err, new(TableSizeError)) {
+			t.Errorf("%v slots: expected %T, observed %v", h.Slots, TableSizeError(0), err)
+		}
+	}
+}
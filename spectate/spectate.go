@@ -0,0 +1,297 @@
+// Package spectate broadcasts games to live spectators as they're played, such as those of a long tournament or
+// simulation, and serves them over HTTP as Server-Sent Events.
+//
+// Games are published to a Hub as records after every move, from which the Hub derives events: a start event as a
+// game begins, a move event after each move, and a result event once it ends, or an ended event if it's given up
+// without a result, such as when the simulation playing it is cancelled. Every event carries a snapshot of its
+// game as a whole, being its record and a grid of the board, so a spectator needs no earlier event to show a game.
+// Spectators watch either a single game or every game, receiving a snapshot event for each ongoing game they watch
+// as they start watching.
+//
+// Publishing never waits on spectators. Each watcher has a buffer of events, and once it's full, the buffer keeps only
+// the latest event of each game, for that watcher alone, which learns how many events of a game it missed from the
+// next event of that game it receives. As events are snapshots, a watcher that falls behind only misses intermediate
+// positions, and receives the latest event of every game it watches, including its result, so long as its buffer
+// holds an event of every game. Watching more games at once than the buffer holds drops the oldest events of ongoing
+// games in favor of results.
+package spectate
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/record"
+)
+
+// DefaultBuffer is the number of events buffered for each watcher if not otherwise set
+const DefaultBuffer = 64
+
+// Types of event
+const (
+	Start    = "start"    // A game has begun, without any move having been played
+	Moved    = "move"     // A move has been played, without ending the game
+	Result   = "result"   // A game has ended, with its last move
+	Ended    = "ended"    // A game has been given up without a result, such as when its simulation was cancelled
+	Snapshot = "snapshot" // A game was underway as the watcher started watching
+)
+
+// Event holds a single event of a game, along with a snapshot of the game as a whole
+type Event struct {
+	Type   string        `json:"type"`
+	Game   string        `json:"game"` // Identifier of the game, as returned by ID
+	Record record.Record `json:"record"`
+	Move   int           `json:"move,omitempty"` // Column of the last move, counting from one, if any
+	// Grid holds the squares of the board, as R, B or ., with the rows from top to bottom separated by slashes
+	Grid   string `json:"grid"`
+	Turn   string `json:"turn,omitempty"`   // Player to move, either red or blue, if the game is underway
+	Winner string `json:"winner,omitempty"` // Player who won, if any
+	// Dropped counts the events of this game the watcher missed for being too far behind, since the last it received
+	Dropped int `json:"dropped,omitempty"`
+}
+
+// final returns whether the event is the last of its game
+func (e Event) final() bool {
+	return e.Type == Result || e.Type == Ended
+}
+
+// ID returns the identifier of the game with the given record, being its event's name and index, separated by a slash
+func ID(r record.Record) string {
+	return fmt.Sprintf("%v/%v", r.Event, r.Index)
+}
+
+// Hub broadcasts events of any number of games to any number of watchers. Hub IS thread safe
+type Hub struct {
+	buffer int
+
+	mutex    sync.Mutex
+	games    map[string]Event // Latest event of each ongoing game
+	watchers map[*Watcher]struct{}
+	closed   bool
+}
+
+// NewHub constructs a Hub, buffering the given number of events for each watcher, or DefaultBuffer if zero
+func NewHub(buffer int) *Hub {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	return &Hub{buffer: buffer, games: map[string]Event{}, watchers: map[*Watcher]struct{}{}}
+}
+
+// Publish publishes the record of a game so far, as reported after each of its moves, delivering an event to every
+// watcher of the game without waiting on any of them. A record without moves starts the game, and a record with a
+// result ends it
+func (h *Hub) Publish(r record.Record) error {
+	if r.Geometry == (bitboard.Geometry{}) {
+		r.Geometry = bitboard.Standard
+	}
+	p, err := r.Position()
+	if err != nil {
+		return fmt.Errorf("cannot publish game: %w", err)
+	}
+
+	e := Event{Game: ID(r), Record: r, Grid: Grid(p)}
+	switch {
+	case p.IsOver():
+		e.Type = Result
+	case len(r.Moves) == 0:
+		e.Type = Start
+	default:
+		e.Type = Moved
+	}
+	if len(r.Moves) > 0 {
+		e.Move = int(r.Moves[len(r.Moves)-1]) + 1
+	}
+	if w := p.Winner(); w != board.NONE {
+		e.Winner = Player(w)
+	} else if !p.IsOver() {
+		e.Turn = Player(p.Turn())
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if e.Type == Result {
+		delete(h.games, e.Game)
+	} else {
+		h.games[e.Game] = e
+	}
+	for w := range h.watchers {
+		if w.game == "" || w.game == e.Game {
+			w.send(e)
+		}
+	}
+	return nil
+}
+
+// End ends the game with the given identifier without a result, such as when the simulation playing it is cancelled
+// before the game ends, delivering an ended event, with the game as last published, to every watcher of the game.
+// Games that aren't ongoing are left alone, such that every game published can be ended once its player stops,
+// whether or not it finished
+func (h *Hub) End(game string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	e, ok := h.games[game]
+	if !ok {
+		return
+	}
+	delete(h.games, game)
+	e.Type, e.Turn = Ended, ""
+	for w := range h.watchers {
+		if w.game == "" || w.game == e.Game {
+			w.send(e)
+		}
+	}
+}
+
+// Games returns the latest event of every ongoing game, in no particular order
+func (h *Hub) Games() []Event {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	games := make([]Event, 0, len(h.games))
+	for _, e := range h.games {
+		games = append(games, e)
+	}
+	return games
+}
+
+// Watch starts watching the game with the given identifier, or every game if empty, beginning with a snapshot of
+// each ongoing game watched. The watcher must be closed once done with
+func (h *Hub) Watch(game string) *Watcher {
+	w := &Watcher{hub: h, game: game, events: make(chan Event, h.buffer), dropped: map[string]int{}}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.closed {
+		w.closed = true
+		close(w.events)
+		return w
+	}
+	for id, e := range h.games {
+		if game == "" || game == id {
+			e.Type = Snapshot
+			w.send(e)
+		}
+	}
+	h.watchers[w] = struct{}{}
+	return w
+}
+
+// Close closes every watcher, ending their streams, such as when shutting down. Games may still be published, but
+// watchers started after closing receive no events
+func (h *Hub) Close() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.closed = true
+	for w := range h.watchers {
+		w.closed = true
+		close(w.events)
+	}
+	h.watchers = map[*Watcher]struct{}{}
+}
+
+// Watcher receives the events of the games it watches, until closed
+type Watcher struct {
+	hub     *Hub
+	game    string
+	events  chan Event
+	dropped map[string]int // Events of each game dropped since the last event counting them, guarded by the hub's mutex
+	closed  bool
+}
+
+// Events returns the channel on which the watcher receives events, which is closed once the watcher is closed
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Close stops watching, closing the channel of events if not already closed
+func (w *Watcher) Close() {
+	w.hub.mutex.Lock()
+	defer w.hub.mutex.Unlock()
+	if !w.closed {
+		w.closed = true
+		delete(w.hub.watchers, w)
+		close(w.events)
+	}
+}
+
+// send delivers an event, first coalescing the buffer to the latest event of each game if it's full. It must be called
+// with the hub's mutex held, such that nothing else sends meanwhile
+func (w *Watcher) send(e Event) {
+	e.Dropped = w.dropped[e.Game]
+	delete(w.dropped, e.Game)
+	select {
+	case w.events <- e:
+		return
+	default:
+	}
+
+	queued := []Event{}
+drain:
+	for {
+		select {
+		case q := <-w.events:
+			queued = append(queued, q)
+		default:
+			break drain
+		}
+	}
+	queued = append(queued, e)
+
+	latest := map[string]int{}
+	for i, q := range queued {
+		latest[q.Game] = i
+	}
+	kept, carried := []Event{}, map[string]int{}
+	for i, q := range queued {
+		if latest[q.Game] != i {
+			carried[q.Game] += q.Dropped + 1
+			continue
+		}
+		q.Dropped += carried[q.Game]
+		kept = append(kept, q)
+	}
+
+	// With more games than room, the oldest events of ongoing games go first, such that final events are kept
+	for len(kept) > cap(w.events) {
+		i := 0
+		for i < len(kept)-1 && kept[i].final() {
+			i++
+		}
+		w.dropped[kept[i].Game] += kept[i].Dropped + 1
+		kept = append(kept[:i], kept[i+1:]...)
+	}
+	for _, q := range kept {
+		w.events <- q
+	}
+}
+
+// Grid returns the squares of a position, as R, B or ., with the rows from top to bottom separated by slashes, as
+// given by Event.Grid
+func Grid(p bitboard.Position) string {
+	g := p.Geometry()
+	rows := make([]string, g.Height)
+	for row := range rows {
+		var sb strings.Builder
+		for col := 0; col < g.Width; col++ {
+			switch p.At(col, g.Height-1-row) {
+			case board.RED:
+				sb.WriteByte('R')
+			case board.BLUE:
+				sb.WriteByte('B')
+			default:
+				sb.WriteByte('.')
+			}
+		}
+		rows[row] = sb.String()
+	}
+	return strings.Join(rows, "/")
+}
+
+// Player names a player as in events, either red or blue, in lower case as in game records
+func Player(t board.Type) string {
+	if t == board.RED {
+		return "red"
+	}
+	return "blue"
+}
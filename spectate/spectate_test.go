@@ -0,0 +1,243 @@
+package spectate
+
+import (
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/record"
+)
+
+// game returns the record of a game of the standard geometry after the given moves
+func game(event string, index int, moves string) record.Record {
+	h, _ := board.ParseHistory(moves)
+	r := record.Record{Event: event, Index: index, Red: "greedy", Blue: "random", Geometry: bitboard.Standard,
+		Moves: h}
+	if p, err := r.Position(); err == nil {
+		r.Result = record.ResultOf(p)
+	}
+	return r
+}
+
+// receive returns the events buffered for a watcher
+func receive(w *Watcher) []Event {
+	var events []Event
+	for {
+		select {
+		case e := <-w.Events():
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+func TestHub_Publish(t *testing.T) {
+	h := NewHub(0)
+	w := h.Watch("")
+	defer w.Close()
+
+	table := []struct {
+		moves, typ, grid string
+		move             int
+		turn, winner     string
+	}{
+		{"", Start, "......./......./......./......./......./.......", 0, "red", ""},
+		{"4", Moved, "......./......./......./......./......./...R...", 4, "blue", ""},
+		{"1212121", Result, "......./......./R....../RB...../RB...../RB.....", 1, "", "red"},
+	}
+	for _, r := range table {
+		if err := h.Publish(game("test", 0, r.moves)); err != nil {
+			t.Fatalf("%q: cannot publish: %v", r.moves, err)
+		}
+		events := receive(w)
+		if len(events) != 1 {
+			t.Fatalf("%q: expected a single event, observed %v", r.moves, events)
+		}
+		e := events[0]
+		if e.Type != r.typ || e.Game != "test/0" || e.Grid != r.grid || e.Move != r.move || e.Turn != r.turn ||
+			e.Winner != r.winner || e.Record.Moves.Notation() != r.moves || e.Dropped != 0 {
+			t.Errorf("%q: unexpected event %+v", r.moves, e)
+		}
+	}
+
+	if err := h.Publish(game("test", 1, "1111111")); err == nil {
+		t.Error("expected error publishing an illegal game")
+	}
+}
+
+func TestHub_Watch(t *testing.T) {
+	h := NewHub(0)
+	h.Publish(game("a", 0, "44"))
+	h.Publish(game("a", 1, "4"))
+	h.Publish(game("a", 2, "1212121"))
+
+	all, one := h.Watch(""), h.Watch("a/1")
+	defer all.Close()
+	defer one.Close()
+	if events := receive(all); len(events) != 2 || events[0].Type != Snapshot || events[1].Type != Snapshot {
+		t.Errorf("expected snapshots of both ongoing games, observed %+v", events)
+	}
+	if events := receive(one); len(events) != 1 || events[0].Game != "a/1" || events[0].Record.Moves.Notation() != "4" {
+		t.Errorf("expected a snapshot of the watched game, observed %+v", events)
+	}
+	if games := h.Games(); len(games) != 2 {
+		t.Errorf("expected two ongoing games, observed %+v", games)
+	}
+
+	h.Publish(game("a", 0, "445"))
+	h.Publish(game("a", 1, "45"))
+	if events := receive(all); len(events) != 2 {
+		t.Errorf("expected an event of each game, observed %+v", events)
+	}
+	if events := receive(one); len(events) != 1 || events[0].Game != "a/1" {
+		t.Errorf("expected an event of the watched game alone, observed %+v", events)
+	}
+
+	one.Close()
+	one.Close()
+	if _, ok := <-one.Events(); ok {
+		t.Error("expected events to be closed")
+	}
+	h.Publish(game("a", 1, "454"))
+}
+
+func TestHub_End(t *testing.T) {
+	h := NewHub(0)
+	h.Publish(game("a", 0, "44"))
+	h.Publish(game("a", 1, "1212121"))
+	all, one := h.Watch(""), h.Watch("a/0")
+	defer all.Close()
+	defer one.Close()
+	receive(all)
+	receive(one)
+
+	h.End("a/0")
+	h.End("a/1") // Already over
+	h.End("a/2") // Never published
+	for _, w := range []*Watcher{all, one} {
+		events := receive(w)
+		if len(events) != 1 || events[0].Type != Ended || events[0].Game != "a/0" || events[0].Turn != "" ||
+			events[0].Record.Moves.Notation() != "44" {
+			t.Errorf("expected an ended event of the ongoing game alone, observed %+v", events)
+		}
+	}
+	if games := h.Games(); len(games) != 0 {
+		t.Errorf("expected no ongoing games, observed %+v", games)
+	}
+}
+
+// publish publishes every prefix of each game's moves, interleaving the games, returning how many events each
+// produced
+func publish(h *Hub, games map[string]string) map[string]int {
+	published := map[string]int{}
+	for i := 0; ; i++ {
+		done := true
+		for id, moves := range games {
+			if i <= len(moves) {
+				h.Publish(game(id, 0, moves[:i]))
+				published[id+"/0"]++
+				done = false
+			}
+		}
+		if done {
+			return published
+		}
+	}
+}
+
+func TestHub_backpressure(t *testing.T) {
+	h := NewHub(3)
+	w := h.Watch("")
+	defer w.Close()
+
+	games := map[string]string{"fast": "1212121", "slow": "12323252", "long": "4444443333332222221"}
+	published := publish(h, games)
+	events := receive(w)
+	if len(events) != 3 {
+		t.Fatalf("expected the latest event of each game, observed %+v", events)
+	}
+	for _, e := range events {
+		id := e.Game[:len(e.Game)-2]
+		if e.Type != Result || e.Record.Moves.Notation() != games[id] || e.Dropped != published[e.Game]-1 {
+			t.Errorf("expected the result of game %v with %v events dropped, observed %+v", id,
+				published[e.Game]-1, e)
+		}
+	}
+
+	h.Publish(game("fast", 1, ""))
+	if events := receive(w); len(events) != 1 || events[0].Dropped != 0 {
+		t.Errorf("expected no more events to be dropped, observed %+v", events)
+	}
+}
+
+func TestHub_backpressure_interleaved(t *testing.T) {
+	h := NewHub(4)
+	w := h.Watch("")
+	defer w.Close()
+
+	// The short game ends while the buffer is full of events of the long one, which goes on
+	publish(h, map[string]string{"short": "1212121", "long": "44444433333322222"})
+	received := map[string][]Event{}
+	for _, e := range receive(w) {
+		received[e.Game] = append(received[e.Game], e)
+	}
+	for id, moves := range map[string]string{"short/0": "1212121", "long/0": "44444433333322222"} {
+		events := received[id]
+		if len(events) == 0 {
+			t.Fatalf("expected events of game %v", id)
+		}
+		last := events[len(events)-1]
+		if last.Record.Moves.Notation() != moves || (last.Type == Result) != (id == "short/0") {
+			t.Errorf("expected the latest event of game %v, observed %+v", id, last)
+		}
+		total := 0
+		for _, e := range events {
+			total += e.Dropped + 1
+		}
+		if total != len(moves)+1 {
+			t.Errorf("expected events received and dropped to total %v for game %v, observed %v", len(moves)+1, id,
+				total)
+		}
+	}
+}
+
+func TestHub_backpressure_overflow(t *testing.T) {
+	h := NewHub(2)
+	w := h.Watch("")
+	defer w.Close()
+
+	h.Publish(game("a", 0, "1212121"))
+	h.Publish(game("b", 0, "1212121"))
+	h.Publish(game("c", 0, "4"))
+	events := receive(w)
+	if len(events) != 2 || events[0].Type != Result || events[1].Type != Result {
+		t.Fatalf("expected results to be kept over ongoing games, observed %+v", events)
+	}
+
+	h.Publish(game("c", 0, "44"))
+	if events := receive(w); len(events) != 1 || events[0].Dropped != 1 {
+		t.Errorf("expected the next event of game c to count the one dropped, observed %+v", events)
+	}
+}
+
+func TestHub_Close(t *testing.T) {
+	h := NewHub(0)
+	h.Publish(game("closing", 0, "4"))
+	w := h.Watch("")
+	h.Close()
+	if e, ok := <-w.Events(); !ok || e.Type != Snapshot {
+		t.Errorf("expected events sent before closing to be received, observed %+v", e)
+	}
+	if _, ok := <-w.Events(); ok {
+		t.Error("expected events to be closed")
+	}
+	w.Close()
+
+	if err := h.Publish(game("closing", 0, "44")); err != nil {
+		t.Errorf("cannot publish after closing: %v", err)
+	}
+	if _, ok := <-h.Watch("").Events(); ok {
+		t.Error("expected watchers started after closing to receive no events")
+	}
+}
@@ -0,0 +1,67 @@
+package spectate
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Heartbeat sets how often a comment is sent to a spectator while no event is, such that proxies keep the stream open
+const Heartbeat = 15 * time.Second
+
+// ServeHTTP streams events to a spectator as Server-Sent Events, each having the event's type as its name and the
+// event as JSON as its data. The query parameter game selects a single game to watch, by its identifier, in which
+// case the stream ends with the game's result or ended event, and otherwise every game is watched until the
+// spectator leaves
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed, expected GET", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	game := r.URL.Query().Get("game")
+	watcher := h.Watch(game)
+	defer watcher.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(Heartbeat)
+	defer heartbeat.Stop()
+	for id := 1; ; id++ {
+		select {
+		case e, ok := <-watcher.Events():
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "id: %v\nevent: %v\ndata: %s\n\n", id, e.Type, data); err != nil {
+				return
+			}
+			flusher.Flush()
+			if game != "" && e.final() {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
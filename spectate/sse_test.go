@@ -0,0 +1,90 @@
+package spectate
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stream reads Server-Sent Events, returning the name and data of each
+func stream(t *testing.T, resp *http.Response, events chan<- [2]string) {
+	defer close(events)
+	lines := bufio.NewScanner(resp.Body)
+	var name, data string
+	for lines.Scan() {
+		switch line := lines.Text(); {
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		case line == "" && name != "":
+			events <- [2]string{name, data}
+			name, data = "", ""
+		}
+	}
+}
+
+func TestHub_ServeHTTP(t *testing.T) {
+	h := NewHub(0)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+	h.Publish(game("live", 0, ""))
+
+	resp, err := http.Get(ts.URL + "?game=live/0")
+	if err != nil {
+		t.Fatalf("cannot watch: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected response %v with content type %q", resp.Status, resp.Header.Get("Content-Type"))
+	}
+	events := make(chan [2]string)
+	go stream(t, resp, events)
+
+	if e := <-events; e[0] != Snapshot {
+		t.Fatalf("expected a snapshot, observed %v", e)
+	}
+	moves := "1212121"
+	for i := 1; i <= len(moves); i++ {
+		h.Publish(game("live", 0, moves[:i]))
+		h.Publish(game("other", 0, moves[:i]))
+	}
+
+	var last Event
+	for i := 1; i <= len(moves); i++ {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				t.Fatalf("expected %v events, observed %v", len(moves), i-1)
+			}
+			if err := json.Unmarshal([]byte(e[1]), &last); err != nil || last.Type != e[0] || last.Game != "live/0" {
+				t.Fatalf("unexpected event %v: %v", e, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for event")
+		}
+	}
+	if last.Type != Result || last.Winner != "red" {
+		t.Errorf("expected the game to end in a win for red, observed %+v", last)
+	}
+	if _, ok := <-events; ok {
+		t.Error("expected the stream to end with the result")
+	}
+}
+
+func TestHub_ServeHTTP_method(t *testing.T) {
+	ts := httptest.NewServer(NewHub(0))
+	defer ts.Close()
+	resp, err := http.Post(ts.URL, "text/plain", strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed || resp.Header.Get("Allow") != http.MethodGet {
+		t.Errorf("expected status 405 allowing GET, observed %v", resp.Status)
+	}
+}
@@ -0,0 +1,8 @@
+package sprt
+
+// ConfigError defines an error used when a test is configured incorrectly
+type ConfigError string
+
+func (e ConfigError) Error() string {
+	return string(e)
+}
@@ -0,0 +1,258 @@
+// Package sprt compares two strategies by a sequential probability ratio test, playing games with alternating colors
+// until the evidence confirms or rejects an improvement, rather than for a number of games fixed in advance.
+//
+// The hypotheses are expressed as Elo differences of the candidate over the baseline: the null hypothesis that the
+// difference is Elo0, and the alternative that it's Elo1. After each game, the log-likelihood ratio of the
+// alternative to the null is computed from the candidate's wins, draws and losses by the generalized SPRT's normal
+// approximation, and compared against bounds derived from the acceptable rates of false positives and false
+// negatives.
+package sprt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/simulate"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+// Decision is an enumerated type describing the conclusion of a test
+type Decision uint8
+
+// Undecided tests stopped before either bound was crossed. Accepted tests crossed the upper bound, confirming the
+// improvement, while Rejected tests crossed the lower bound, rejecting it
+const (
+	Undecided Decision = iota
+	Accepted
+	Rejected
+)
+
+func (d Decision) String() string {
+	switch d {
+	case Accepted:
+		return "accepted"
+	case Rejected:
+		return "rejected"
+	default:
+		return "undecided"
+	}
+}
+
+// Config holds the parameters of a test
+type Config struct {
+	Candidate, Baseline strategy.Strategy // Strategies compared, with the candidate presumed to be the improvement
+	Seed                int64             // Master seed, from which the seed of every game is derived
+
+	// Elo0 and Elo1 set the Elo difference of the candidate over the baseline under the null and alternative
+	// hypotheses, defaulting to 0 and 20 if both zero
+	Elo0, Elo1 float64
+	// Alpha sets the probability of accepting an improvement that isn't there, and Beta of rejecting one that is,
+	// each defaulting to 0.05 if zero
+	Alpha, Beta float64
+	// MaxGames bounds the number of games played, leaving the test undecided if neither bound has been crossed by
+	// then, or is unbounded if zero
+	MaxGames int
+
+	// Geometry sets the board size, defaulting to bitboard.Standard if zero
+	Geometry bitboard.Geometry
+	// Start holds the moves leading to the starting position of every game, or nil to start from the empty board
+	Start board.History
+	// Workers sets the number of games played concurrently, defaulting to the number of CPUs if zero. Results are
+	// identical regardless of the number of workers, as games are judged in order of index
+	Workers int
+}
+
+// Report holds the results of a test, counting wins, draws and losses from the candidate's perspective
+type Report struct {
+	Config              Config
+	Games               []simulate.Game // Every game judged, in which the candidate plays RED in even-numbered games
+	Wins, Draws, Losses int
+	LLR                 float64   // Log-likelihood ratio after the last game
+	Lower, Upper        float64   // Bounds on the log-likelihood ratio at which the test stops
+	Trajectory          []float64 // Log-likelihood ratio after each game
+	Decision            Decision
+	Score, Elo          float64 // Mean score of the candidate, counting draws as half a win, and its Elo equivalent
+	EloLow, EloHigh     float64 // 95% confidence interval of the Elo difference
+}
+
+// String summarizes the report in a human-readable format
+func (r Report) String() string {
+	return fmt.Sprintf("%v against %v: %v after %v games (+%v =%v -%v)\nLLR %.3f in [%.3f, %.3f] testing "+
+		"elo0 %g, elo1 %g\nElo %+.1f [%+.1f, %+.1f]", strategy.Name(r.Config.Candidate),
+		strategy.Name(r.Config.Baseline), r.Decision, len(r.Games), r.Wins, r.Draws, r.Losses, r.LLR, r.Lower,
+		r.Upper, r.Config.Elo0, r.Config.Elo1, r.Elo, r.EloLow, r.EloHigh)
+}
+
+// WriteTrajectory writes the log-likelihood ratio after each game as CSV, with a header row
+func (r Report) WriteTrajectory(w io.Writer) error {
+	if _, err := io.WriteString(w, "game,llr\n"); err != nil {
+		return fmt.Errorf("cannot write trajectory: %w", err)
+	}
+	for i, llr := range r.Trajectory {
+		if _, err := fmt.Fprintf(w, "%v,%v\n", i+1, strconv.FormatFloat(llr, 'f', 6, 64)); err != nil {
+			return fmt.Errorf("cannot write trajectory: %w", err)
+		}
+	}
+	return nil
+}
+
+// Run plays games until the log-likelihood ratio crosses a bound, or the maximum number of games is reached. If the
+// context is done first, Run returns a report of the games judged until then, along with the context's error
+func Run(ctx context.Context, c Config) (Report, error) {
+	c, err := c.normalize()
+	if err != nil {
+		return Report{}, err
+	}
+	r := Report{Config: c, Lower: math.Log(c.Beta / (1 - c.Alpha)), Upper: math.Log((1 - c.Beta) / c.Alpha)}
+
+	// Play games in batches across the workers, then judge them in order, discarding any played past a decision
+	for next := 0; r.Decision == Undecided && (c.MaxGames == 0 || next < c.MaxGames); {
+		batch := c.Workers
+		if c.MaxGames > 0 && next+batch > c.MaxGames {
+			batch = c.MaxGames - next
+		}
+		games, err := play(ctx, c, next, batch)
+		if err != nil {
+			return r, err
+		}
+		for _, g := range games {
+			r.judge(g)
+			if r.Decision != Undecided {
+				break
+			}
+		}
+		next += batch
+	}
+	return r, nil
+}
+
+// normalize fills in defaults and validates the configuration
+func (c Config) normalize() (Config, error) {
+	if c.Elo0 == 0 && c.Elo1 == 0 {
+		c.Elo1 = 20
+	}
+	if c.Alpha == 0 {
+		c.Alpha = 0.05
+	}
+	if c.Beta == 0 {
+		c.Beta = 0.05
+	}
+	if c.Geometry == (bitboard.Geometry{}) {
+		c.Geometry = bitboard.Standard
+	}
+	if c.Workers == 0 {
+		c.Workers = runtime.NumCPU()
+	}
+
+	switch {
+	case c.Candidate == nil || c.Baseline == nil:
+		return c, fmt.Errorf("cannot test: %w", ConfigError("both strategies must be set"))
+	case c.Elo1 <= c.Elo0:
+		return c, fmt.Errorf("cannot test: %w", ConfigError("elo1 must exceed elo0"))
+	case c.Alpha <= 0 || c.Alpha >= 1 || c.Beta <= 0 || c.Beta >= 1:
+		return c, fmt.Errorf("cannot test: %w", ConfigError("alpha and beta must be between 0 and 1"))
+	case c.MaxGames < 0:
+		return c, fmt.Errorf("cannot test: %w", ConfigError("maximum games must not be negative"))
+	case c.Workers < 0:
+		return c, fmt.Errorf("cannot test: %w", ConfigError("workers must not be negative"))
+	}
+	return c, nil
+}
+
+// play plays a batch of consecutive games concurrently, returning them in order of index
+func play(ctx context.Context, c Config, first, n int) ([]simulate.Game, error) {
+	games, errs := make([]simulate.Game, n), make([]error, n)
+	var wg sync.WaitGroup
+	for i := range games {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			index := first + i
+			sc := simulate.Config{Red: c.Candidate, Blue: c.Baseline, Geometry: c.Geometry, Start: c.Start}
+			if index%2 == 1 {
+				sc.Red, sc.Blue = c.Baseline, c.Candidate
+			}
+			games[i], errs[i] = simulate.Replay(ctx, sc, simulate.Game{Index: index,
+				Seed: simulate.GameSeed(c.Seed, index)})
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return games, nil
+}
+
+// judge records a game, updating the log-likelihood ratio and the decision
+func (r *Report) judge(g simulate.Game) {
+	candidate := board.RED
+	if g.Index%2 == 1 {
+		candidate = board.BLUE
+	}
+	switch g.Winner {
+	case candidate:
+		r.Wins++
+	case board.NONE:
+		r.Draws++
+	default:
+		r.Losses++
+	}
+	r.Games = append(r.Games, g)
+
+	r.LLR = llr(r.Wins, r.Draws, r.Losses, r.Config.Elo0, r.Config.Elo1)
+	r.Trajectory = append(r.Trajectory, r.LLR)
+	switch {
+	case r.LLR >= r.Upper:
+		r.Decision = Accepted
+	case r.LLR <= r.Lower:
+		r.Decision = Rejected
+	}
+
+	n := float64(len(r.Games))
+	r.Score = (float64(r.Wins) + float64(r.Draws)/2) / n
+	margin := 1.959964 * math.Sqrt(variance(float64(r.Wins), float64(r.Draws), float64(r.Losses))/n)
+	r.Elo, r.EloLow, r.EloHigh = elo(r.Score), elo(r.Score-margin), elo(r.Score+margin)
+}
+
+// expected returns the expected score of a player rated the given number of Elo points above its opponent
+func expected(elo float64) float64 {
+	return 1 / (1 + math.Pow(10, -elo/400))
+}
+
+// elo returns the Elo difference corresponding to an expected score, clamped to a finite range
+func elo(score float64) float64 {
+	score = math.Min(math.Max(score, 1e-6), 1-1e-6)
+	return -400 * math.Log10(1/score-1)
+}
+
+// variance returns the per-game variance of the score, counting draws as half a win
+func variance(wins, draws, losses float64) float64 {
+	n := wins + draws + losses
+	mean := (wins + draws/2) / n
+	return (wins*(1-mean)*(1-mean) + draws*(0.5-mean)*(0.5-mean) + losses*mean*mean) / n
+}
+
+// llr returns the log-likelihood ratio of the hypotheses given the results so far, by the normal approximation of the
+// generalized SPRT, in which the score of each game is normally distributed about the expected score of each
+// hypothesis, with the variance observed. As the observed variance is zero until results differ, as when one strategy
+// wins every game, the variance is estimated with half a game of each result added
+func llr(wins, draws, losses int, elo0, elo1 float64) float64 {
+	n := float64(wins + draws + losses)
+	if n == 0 {
+		return 0
+	}
+	mean := (float64(wins) + float64(draws)/2) / n
+	s0, s1 := expected(elo0), expected(elo1)
+	return n * (s1 - s0) * (2*mean - s0 - s1) /
+		(2 * variance(float64(wins)+0.5, float64(draws)+0.5, float64(losses)+0.5))
+}
@@ -0,0 +1,175 @@
+package sprt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+var small = bitboard.Geometry{Width: 5, Height: 4}
+
+func TestRun(t *testing.T) {
+	table := []struct {
+		name                string
+		candidate, baseline strategy.Strategy
+		decision            Decision
+	}{
+		{"improvement", strategy.Lookahead{Depth: 4}, strategy.Random{}, Accepted},
+		{"regression", strategy.Random{}, strategy.Lookahead{Depth: 4}, Rejected},
+	}
+
+	for _, r := range table {
+		t.Run(r.name, func(t *testing.T) {
+			report, err := Run(context.Background(), Config{Candidate: r.candidate, Baseline: r.baseline, Seed: 1,
+				Geometry: small, MaxGames: 1000})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if report.Decision != r.decision {
+				t.Fatalf("expected decision %v, observed %v", r.decision, report)
+			}
+
+			n := len(report.Games)
+			if n == 0 || n >= 1000 || len(report.Trajectory) != n || report.Wins+report.Draws+report.Losses != n {
+				t.Fatalf("expected a decision before the maximum number of games, observed %v", report)
+			}
+			if last := report.Trajectory[n-1]; last != report.LLR || (last < report.Upper && last > report.Lower) {
+				t.Errorf("expected the final LLR %v to cross a bound, observed %v", report.LLR, report)
+			}
+			for i, llr := range report.Trajectory[:n-1] {
+				if llr >= report.Upper || llr <= report.Lower {
+					t.Errorf("expected the test to stop when LLR %v crossed a bound after game %v", llr, i+1)
+				}
+			}
+		})
+	}
+}
+
+// TestRun_colors asserts that the candidate alternates colors, starting with RED
+func TestRun_colors(t *testing.T) {
+	// Both players playing the leftmost column, RED wins every game by stacking column zero
+	report, err := Run(context.Background(), Config{Candidate: leftmost{}, Baseline: leftmost{}, Geometry: small,
+		MaxGames: 10, Workers: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Games) != 10 || report.Wins != 5 || report.Losses != 5 || report.Decision != Undecided {
+		t.Fatalf("expected the candidate to win exactly its five games as RED, observed %v", report)
+	}
+	for i, g := range report.Games {
+		want := board.RED
+		if g.Index != i || g.Winner != want {
+			t.Fatalf("expected game %v won by RED, observed game %v won by %v", i, g.Index, g.Winner)
+		}
+	}
+}
+
+// leftmost plays the leftmost playable column
+type leftmost struct{}
+
+func (leftmost) Choose(_ context.Context, p bitboard.Position, _ *rand.Rand) (board.Move, error) {
+	return p.LegalMoves()[0], nil
+}
+
+// TestRun_deterministic asserts that results don't depend on the number of workers
+func TestRun_deterministic(t *testing.T) {
+	c := Config{Candidate: strategy.Greedy{}, Baseline: strategy.Random{}, Seed: 5, Geometry: small, MaxGames: 500}
+	var reports []Report
+	for _, workers := range []int{1, 4, 7} {
+		c.Workers = workers
+		r, err := Run(context.Background(), c)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		reports = append(reports, r)
+	}
+	for _, r := range reports[1:] {
+		if r.String() != reports[0].String() || len(r.Trajectory) != len(reports[0].Trajectory) {
+			t.Errorf("expected identical reports, observed\n%v\nand\n%v", reports[0], r)
+		}
+	}
+}
+
+// TestRun_undecided asserts that too few games to tell apart close hypotheses leave the test undecided
+func TestRun_undecided(t *testing.T) {
+	r, err := Run(context.Background(), Config{Candidate: strategy.Random{}, Baseline: strategy.Random{},
+		Elo0: 0, Elo1: 5, Geometry: small, MaxGames: 50})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.Decision != Undecided || len(r.Games) != 50 {
+		t.Errorf("expected an undecided test after 50 games, observed %v", r)
+	}
+	if r.EloLow > r.Elo || r.Elo > r.EloHigh {
+		t.Errorf("expected the Elo interval to contain its estimate, observed %v", r)
+	}
+}
+
+func TestLLR(t *testing.T) {
+	if llr(0, 0, 0, 0, 20) != 0 {
+		t.Errorf("expected no evidence without games")
+	}
+	// Scoring between the hypotheses' expected scores is no evidence either way
+	if l := llr(1, 0, 1, -20, 20); math.Abs(l) > 1e-12 {
+		t.Errorf("expected no evidence from an even score, observed %v", l)
+	}
+	// Winning is evidence for the alternative, and losing for the null, more so the more games played
+	if a, b := llr(6, 2, 2, 0, 20), llr(60, 20, 20, 0, 20); a <= 0 || b <= a {
+		t.Errorf("expected growing evidence for the alternative, observed %v and %v", a, b)
+	}
+	if a := llr(2, 2, 6, 0, 20); a >= 0 {
+		t.Errorf("expected evidence for the null, observed %v", a)
+	}
+	// Winning every game is evidence nonetheless
+	if a := llr(10, 0, 0, 0, 20); a <= 0 {
+		t.Errorf("expected evidence for the alternative from a clean sweep, observed %v", a)
+	}
+}
+
+func TestReport_WriteTrajectory(t *testing.T) {
+	var buf bytes.Buffer
+	r := Report{Trajectory: []float64{0.5, -0.25}}
+	if err := r.WriteTrajectory(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "game,llr\n1,0.500000\n2,-0.250000\n"; buf.String() != want {
+		t.Errorf("expected %q, observed %q", want, buf.String())
+	}
+}
+
+func TestRun_errors(t *testing.T) {
+	table := []Config{
+		{Baseline: strategy.Random{}},
+		{Candidate: strategy.Random{}, Baseline: strategy.Random{}, Elo0: 10, Elo1: 5},
+		{Candidate: strategy.Random{}, Baseline: strategy.Random{}, Alpha: 1},
+		{Candidate: strategy.Random{}, Baseline: strategy.Random{}, MaxGames: -1},
+	}
+	for _, c := range table {
+		if _, err := Run(context.Background(), c); !errors.As(err, new(ConfigError)) {
+			t.Errorf("expected a configuration error, observed %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Run(ctx, Config{Candidate: strategy.Random{}, Baseline: strategy.Random{}})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected cancellation, observed %v", err)
+	}
+}
+
+func TestReport_String(t *testing.T) {
+	r, _ := Run(context.Background(), Config{Candidate: strategy.Greedy{}, Baseline: strategy.Random{},
+		Geometry: small, MaxGames: 4})
+	if s := r.String(); !strings.Contains(s, "greedy against random: ") || !strings.Contains(s, "elo0 0, elo1 20") {
+		t.Errorf("unexpected summary:\n%v", s)
+	}
+}
@@ -0,0 +1,48 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+)
+
+// Difference holds the result of comparing two proportions, such as the rates at which two strategies win
+type Difference struct {
+	A, B Interval // Each proportion, with its Wilson interval
+	// Estimate, Low and High hold the difference of the proportions, A less B, with its Newcombe interval, built from
+	// the Wilson intervals of each proportion
+	Estimate, Low, High float64
+	Z                   float64 // Test statistic of the pooled two-proportion z-test
+	P                   float64 // Two-sided p-value of the test, against the hypothesis that the proportions are equal
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%+.4f [%+.4f, %+.4f], z = %.3f, p = %.4g", d.Estimate, d.Low, d.High, d.Z, d.P)
+}
+
+// Significant reports whether the difference is significant at the given level, such as 0.05
+func (d Difference) Significant(level float64) bool {
+	return d.P < level
+}
+
+// Compare tests whether two proportions differ, given the successes and trials behind each
+func Compare(successesA, trialsA, successesB, trialsB int, confidence float64) Difference {
+	d := Difference{A: Wilson(successesA, trialsA, confidence), B: Wilson(successesB, trialsB, confidence), P: 1}
+	d.Estimate = d.A.Estimate - d.B.Estimate
+	d.Low = d.Estimate - math.Sqrt(sq(d.A.Estimate-d.A.Low)+sq(d.B.High-d.B.Estimate))
+	d.High = d.Estimate + math.Sqrt(sq(d.A.High-d.A.Estimate)+sq(d.B.Estimate-d.B.Low))
+	if trialsA == 0 || trialsB == 0 {
+		return d
+	}
+
+	pooled := float64(successesA+successesB) / float64(trialsA+trialsB)
+	se := math.Sqrt(pooled * (1 - pooled) * (1/float64(trialsA) + 1/float64(trialsB)))
+	if se > 0 {
+		d.Z = d.Estimate / se
+		d.P = math.Erfc(math.Abs(d.Z) / math.Sqrt2)
+	}
+	return d
+}
+
+func sq(x float64) float64 {
+	return x * x
+}
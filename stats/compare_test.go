@@ -0,0 +1,32 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	// 60/100 against 40/100 gives a pooled proportion of 0.5, a standard error of sqrt(0.005), and z = 2.828
+	d := Compare(60, 100, 40, 100, 0.95)
+	if math.Abs(d.Estimate-0.2) > 1e-12 || math.Abs(d.Z-2.8284) > 1e-4 || math.Abs(d.P-0.004678) > 1e-5 {
+		t.Errorf("unexpected comparison %v", d)
+	}
+	if !d.Significant(0.05) || d.Significant(0.001) {
+		t.Errorf("expected significance at 0.05 but not at 0.001, observed p = %v", d.P)
+	}
+
+	// Newcombe's own worked example, 56/70 against 48/80, has the interval [0.0524, 0.3339]
+	if d := Compare(56, 70, 48, 80, 0.95); math.Abs(d.Low-0.0524) > 1e-4 || math.Abs(d.High-0.3339) > 1e-4 {
+		t.Errorf("unexpected interval [%v, %v]", d.Low, d.High)
+	}
+
+	// Equal proportions are no different at all
+	if d := Compare(30, 100, 60, 200, 0.95); d.Z != 0 || d.P != 1 || d.Significant(0.5) {
+		t.Errorf("expected no difference, observed %v", d)
+	}
+
+	// Without trials, nothing can be concluded
+	if d := Compare(0, 0, 5, 10, 0.95); d.P != 1 || d.Low < -1 || d.High > 1 {
+		t.Errorf("expected an uninformative comparison, observed %v", d)
+	}
+}
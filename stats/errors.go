@@ -0,0 +1,10 @@
+package stats
+
+import "fmt"
+
+// UnfinishedError defines an error used when summarizing a game that hasn't ended, after the given number of moves
+type UnfinishedError int
+
+func (e UnfinishedError) Error() string {
+	return fmt.Sprintf("game is unfinished after %v moves", int(e))
+}
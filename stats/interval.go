@@ -0,0 +1,121 @@
+// Package stats summarizes collections of finished games: the rate at which each side wins, with confidence
+// intervals, tests of whether two rates differ, histograms of how long games last and when they're won, and how often
+// each column is played. Summaries are plain structs, and can be written as CSV or as human-readable tables.
+package stats
+
+import (
+	"fmt"
+	"math"
+)
+
+// Interval holds an estimated proportion along with a confidence interval around it
+type Interval struct {
+	Estimate, Low, High float64
+}
+
+func (i Interval) String() string {
+	return fmt.Sprintf("%.4f [%.4f, %.4f]", i.Estimate, i.Low, i.High)
+}
+
+// Z returns the two-sided critical value of the standard normal distribution at the given confidence level, such that
+// an estimate falls within Z standard errors of the truth with that probability
+func Z(confidence float64) float64 {
+	return math.Sqrt2 * math.Erfinv(confidence)
+}
+
+// Wilson returns the Wilson score interval of a proportion, which unlike the normal approximation behaves well for
+// proportions near zero or one, as in lopsided matchups
+func Wilson(successes, trials int, confidence float64) Interval {
+	if trials == 0 {
+		return Interval{Low: 0, High: 1}
+	}
+	z := Z(confidence)
+	n, p := float64(trials), float64(successes)/float64(trials)
+	center := (p + z*z/(2*n)) / (1 + z*z/n)
+	margin := z / (1 + z*z/n) * math.Sqrt(p*(1-p)/n+z*z/(4*n*n))
+	return Interval{Estimate: p, Low: math.Max(0, center-margin), High: math.Min(1, center+margin)}
+}
+
+// ClopperPearson returns the Clopper-Pearson interval of a proportion, which inverts the binomial distribution
+// exactly, guaranteeing at least the nominal coverage at the cost of being wider than the Wilson interval
+func ClopperPearson(successes, trials int, confidence float64) Interval {
+	if trials == 0 {
+		return Interval{Low: 0, High: 1}
+	}
+	alpha := 1 - confidence
+	k, n := float64(successes), float64(trials)
+	i := Interval{Estimate: k / n, Low: 0, High: 1}
+	if successes > 0 {
+		i.Low = inverseBeta(alpha/2, k, n-k+1)
+	}
+	if successes < trials {
+		i.High = inverseBeta(1-alpha/2, k+1, n-k)
+	}
+	return i
+}
+
+// inverseBeta returns the quantile of the beta distribution with the given shape parameters, by bisection
+func inverseBeta(q, a, b float64) float64 {
+	low, high := 0.0, 1.0
+	for i := 0; i < 100; i++ {
+		mid := (low + high) / 2
+		if incompleteBeta(mid, a, b) < q {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2
+}
+
+// incompleteBeta returns the regularized incomplete beta function, being the cumulative distribution function of the
+// beta distribution, evaluated by its continued fraction
+func incompleteBeta(x, a, b float64) float64 {
+	switch {
+	case x <= 0:
+		return 0
+	case x >= 1:
+		return 1
+	}
+
+	// The continued fraction converges quickly only below the mean, so the symmetry I(x; a, b) = 1 - I(1-x; b, a)
+	// covers the rest
+	if x > (a+1)/(a+b+2) {
+		return 1 - incompleteBeta(1-x, b, a)
+	}
+
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab-la-lb+a*math.Log(x)+b*math.Log(1-x)) / a
+
+	// Evaluate the continued fraction by the modified Lentz method
+	const tiny = 1e-300
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	f := d
+	for m := 1.0; m <= 1000; m++ {
+		for _, numerator := range []float64{
+			m * (b - m) * x / ((a + 2*m - 1) * (a + 2*m)),
+			-(a + m) * (a + b + m) * x / ((a + 2*m) * (a + 2*m + 1)),
+		} {
+			d = 1 + numerator*d
+			if math.Abs(d) < tiny {
+				d = tiny
+			}
+			c = 1 + numerator/c
+			if math.Abs(c) < tiny {
+				c = tiny
+			}
+			d = 1 / d
+			f *= c * d
+		}
+		if math.Abs(c*d-1) < 1e-15 {
+			break
+		}
+	}
+	return front * f
+}
@@ -0,0 +1,94 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWilson(t *testing.T) {
+	table := []struct {
+		successes, trials int
+		low, high         float64
+	}{
+		{0, 10, 0, 0.2775},
+		{5, 10, 0.2366, 0.7634},
+		{81, 263, 0.2553, 0.3662},
+		{10, 10, 0.7225, 1},
+	}
+
+	for _, r := range table {
+		got := Wilson(r.successes, r.trials, 0.95)
+		if math.Abs(got.Low-r.low) > 1e-4 || math.Abs(got.High-r.high) > 1e-4 {
+			t.Errorf("Wilson interval of %v/%v incorrect. Expected [%v, %v], observed %v",
+				r.successes, r.trials, r.low, r.high, got)
+		}
+	}
+}
+
+func TestClopperPearson(t *testing.T) {
+	table := []struct {
+		successes, trials int
+		low, high         float64
+	}{
+		{0, 10, 0, 0.3085},
+		{5, 10, 0.1871, 0.8129},
+		{10, 10, 0.6915, 1},
+		{0, 0, 0, 1},
+	}
+
+	for _, r := range table {
+		got := ClopperPearson(r.successes, r.trials, 0.95)
+		if math.Abs(got.Low-r.low) > 1e-4 || math.Abs(got.High-r.high) > 1e-4 {
+			t.Errorf("Clopper-Pearson interval of %v/%v incorrect. Expected [%v, %v], observed %v",
+				r.successes, r.trials, r.low, r.high, got)
+		}
+	}
+}
+
+// binomialTail returns the probability of at least k successes in n trials of probability p
+func binomialTail(k, n int, p float64) float64 {
+	tail := 0.0
+	for i := k; i <= n; i++ {
+		ln, _ := math.Lgamma(float64(n + 1))
+		li, _ := math.Lgamma(float64(i + 1))
+		lni, _ := math.Lgamma(float64(n - i + 1))
+		tail += math.Exp(ln - li - lni + float64(i)*math.Log(p) + float64(n-i)*math.Log(1-p))
+	}
+	return tail
+}
+
+// TestClopperPearson_definition asserts that the interval's bounds are those at which the observed number of
+// successes becomes exactly as extreme as the confidence level allows
+func TestClopperPearson_definition(t *testing.T) {
+	for _, r := range []struct{ successes, trials int }{{81, 263}, {3, 1000}, {997, 1000}, {1, 2}} {
+		for _, confidence := range []float64{0.9, 0.99} {
+			i := ClopperPearson(r.successes, r.trials, confidence)
+			alpha := 1 - confidence
+			if tail := binomialTail(r.successes, r.trials, i.Low); math.Abs(tail-alpha/2) > 1e-9 {
+				t.Errorf("%v/%v at %v: expected upper tail %v at the lower bound %v, observed %v", r.successes,
+					r.trials, confidence, alpha/2, i.Low, tail)
+			}
+			if tail := 1 - binomialTail(r.successes+1, r.trials, i.High); math.Abs(tail-alpha/2) > 1e-9 {
+				t.Errorf("%v/%v at %v: expected lower tail %v at the upper bound %v, observed %v", r.successes,
+					r.trials, confidence, alpha/2, i.High, tail)
+			}
+		}
+	}
+}
+
+func TestIncompleteBeta(t *testing.T) {
+	table := []struct{ x, a, b, want float64 }{
+		{0.5, 1, 1, 0.5},
+		{0.3, 2, 1, 0.09},
+		{0.5, 3, 3, 0.5},
+		{0.2, 1, 4, 1 - math.Pow(0.8, 4)},
+		{0, 2, 2, 0},
+		{1, 2, 2, 1},
+	}
+
+	for _, r := range table {
+		if got := incompleteBeta(r.x, r.a, r.b); math.Abs(got-r.want) > 1e-12 {
+			t.Errorf("I(%v; %v, %v) expected %v, observed %v", r.x, r.a, r.b, r.want, got)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package stats
+
+import (
+	"fmt"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+// Histogram counts occurrences of non-negative integers, such that h[v] holds the number of occurrences of v
+type Histogram []int
+
+// add counts an occurrence of a value, growing the histogram as necessary
+func (h *Histogram) add(v int) {
+	for len(*h) <= v {
+		*h = append(*h, 0)
+	}
+	(*h)[v]++
+}
+
+// Total returns the number of occurrences counted
+func (h Histogram) Total() int {
+	total := 0
+	for _, n := range h {
+		total += n
+	}
+	return total
+}
+
+// Mean returns the mean of the values counted, or zero if there are none
+func (h Histogram) Mean() float64 {
+	total, sum := 0, 0
+	for v, n := range h {
+		total += n
+		sum += v * n
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(sum) / float64(total)
+}
+
+// Summary accumulates statistics over finished games of a single geometry. The zero value isn't usable, so summaries
+// must be created with New or Summarize
+type Summary struct {
+	Geometry   bitboard.Geometry
+	Confidence float64 // Confidence level of intervals
+
+	Games, Red, Blue, Draws int // Number of games, and number won by each player or drawn
+
+	Lengths Histogram // Number of moves in each game
+	Wins    Histogram // Index of the winning move of each decisive game, counting from one, being its length
+
+	// RedColumns and BlueColumns hold the number of moves played in each column by each player
+	RedColumns, BlueColumns []int
+}
+
+// New returns an empty summary of games of the geometry, defaulting to bitboard.Standard if zero, with intervals at
+// the confidence level, defaulting to 0.95 if zero
+func New(g bitboard.Geometry, confidence float64) *Summary {
+	if g == (bitboard.Geometry{}) {
+		g = bitboard.Standard
+	}
+	if confidence == 0 {
+		confidence = 0.95
+	}
+	return &Summary{Geometry: g, Confidence: confidence, RedColumns: make([]int, g.Width),
+		BlueColumns: make([]int, g.Width)}
+}
+
+// Summarize returns a summary of the games, each given as its moves from the empty board
+func Summarize(g bitboard.Geometry, games []board.History, confidence float64) (*Summary, error) {
+	s := New(g, confidence)
+	for i, h := range games {
+		if err := s.Add(h); err != nil {
+			return nil, fmt.Errorf("cannot summarize game %v: %w", i, err)
+		}
+	}
+	return s, nil
+}
+
+// SummarizeBoards returns a summary of the games played on the boards
+func SummarizeBoards(boards []board.Board, confidence float64) (*Summary, error) {
+	s := New(bitboard.Standard, confidence)
+	for i, b := range boards {
+		if err := s.AddBoard(b); err != nil {
+			return nil, fmt.Errorf("cannot summarize board %v: %w", i, err)
+		}
+	}
+	return s, nil
+}
+
+// Add adds a finished game, given as its moves from the empty board, to the summary
+func (s *Summary) Add(h board.History) error {
+	p, err := bitboard.FromHistory(s.Geometry, h)
+	if err != nil {
+		return err
+	}
+	if !p.IsOver() {
+		return UnfinishedError(len(h))
+	}
+
+	s.Games++
+	s.Lengths.add(len(h))
+	switch p.Winner() {
+	case board.RED:
+		s.Red++
+		s.Wins.add(len(h))
+	case board.BLUE:
+		s.Blue++
+		s.Wins.add(len(h))
+	default:
+		s.Draws++
+	}
+	for i, m := range h {
+		if i%2 == 0 {
+			s.RedColumns[m]++
+		} else {
+			s.BlueColumns[m]++
+		}
+	}
+	return nil
+}
+
+// AddBoard adds the finished game played on a board to the summary, which must be of the standard geometry
+func (s *Summary) AddBoard(b board.Board) error {
+	return s.Add(b.History())
+}
+
+// RedRate returns the proportion of games won by RED, with its Wilson interval
+func (s *Summary) RedRate() Interval {
+	return Wilson(s.Red, s.Games, s.Confidence)
+}
+
+// BlueRate returns the proportion of games won by BLUE, with its Wilson interval
+func (s *Summary) BlueRate() Interval {
+	return Wilson(s.Blue, s.Games, s.Confidence)
+}
+
+// DrawRate returns the proportion of games drawn, with its Wilson interval
+func (s *Summary) DrawRate() Interval {
+	return Wilson(s.Draws, s.Games, s.Confidence)
+}
+
+// Columns returns the number of moves played in each column by either player
+func (s *Summary) Columns() []int {
+	columns := make([]int, len(s.RedColumns))
+	for i := range columns {
+		columns[i] = s.RedColumns[i] + s.BlueColumns[i]
+	}
+	return columns
+}
+
+// CompareRed tests whether RED wins at a different rate in the summary than in another, as when comparing two
+// strategies playing RED against the same opponent
+func (s *Summary) CompareRed(other *Summary) Difference {
+	return Compare(s.Red, s.Games, other.Red, other.Games, s.Confidence)
+}
+
+// CompareBlue tests whether BLUE wins at a different rate in the summary than in another
+func (s *Summary) CompareBlue(other *Summary) Difference {
+	return Compare(s.Blue, s.Games, other.Blue, other.Games, s.Confidence)
+}
@@ -0,0 +1,102 @@
+package stats
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+// games holds finished games on the standard board: a vertical RED win, a vertical BLUE win, and a horizontal RED win
+var games = []board.History{
+	{0, 1, 0, 1, 0, 1, 0},
+	{6, 0, 6, 0, 6, 0, 5, 0},
+	{0, 0, 1, 1, 2, 2, 3},
+}
+
+func TestSummarize(t *testing.T) {
+	s, err := Summarize(bitboard.Standard, games, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if s.Games != 3 || s.Red != 2 || s.Blue != 1 || s.Draws != 0 || s.Confidence != 0.95 {
+		t.Errorf("unexpected tally %+v", s)
+	}
+	if s.RedRate().Estimate != 2.0/3 || s.BlueRate().Estimate != 1.0/3 || s.DrawRate().Estimate != 0 {
+		t.Errorf("unexpected rates %v, %v, %v", s.RedRate(), s.BlueRate(), s.DrawRate())
+	}
+	if s.Lengths[7] != 2 || s.Lengths[8] != 1 || s.Lengths.Total() != 3 || s.Wins.Total() != 3 {
+		t.Errorf("unexpected lengths %v and winning moves %v", s.Lengths, s.Wins)
+	}
+	if m := s.Lengths.Mean(); m != 22.0/3 {
+		t.Errorf("expected mean length %v, observed %v", 22.0/3, m)
+	}
+
+	wantRed, wantBlue := []int{5, 1, 1, 1, 0, 1, 3}, []int{5, 4, 1, 0, 0, 0, 0}
+	for col := range wantRed {
+		if s.RedColumns[col] != wantRed[col] || s.BlueColumns[col] != wantBlue[col] {
+			t.Fatalf("expected columns %v and %v, observed %v and %v", wantRed, wantBlue, s.RedColumns,
+				s.BlueColumns)
+		}
+		if s.Columns()[col] != wantRed[col]+wantBlue[col] {
+			t.Fatalf("unexpected total columns %v", s.Columns())
+		}
+	}
+}
+
+func TestSummarize_draw(t *testing.T) {
+	// Fill a 2x2 board, too small for anyone to win
+	s, err := Summarize(bitboard.Geometry{Width: 2, Height: 2}, []board.History{{0, 0, 1, 1}}, 0.9)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Draws != 1 || s.Wins.Total() != 0 || s.Lengths[4] != 1 {
+		t.Errorf("expected a single drawn game of four moves, observed %+v", s)
+	}
+}
+
+func TestSummarize_errors(t *testing.T) {
+	if _, err := Summarize(bitboard.Standard, []board.History{{0, 1}}, 0); !errors.As(err, new(UnfinishedError)) {
+		t.Errorf("expected an unfinished game error, observed %v", err)
+	}
+	if _, err := Summarize(bitboard.Standard, []board.History{{9}}, 0); !errors.As(err,
+		new(bitboard.ColumnRangeError)) {
+		t.Errorf("expected a column range error, observed %v", err)
+	}
+}
+
+func TestSummarizeBoards(t *testing.T) {
+	var boards []board.Board
+	for _, h := range games {
+		b := board.New()
+		for _, m := range h {
+			if _, _, err := b.Move(int(m)); err != nil {
+				t.Fatalf("unexpected error making move: %v", err)
+			}
+		}
+		boards = append(boards, b)
+	}
+
+	s, err := SummarizeBoards(boards, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected, _ := Summarize(bitboard.Standard, games, 0)
+	if s.String() != expected.String() {
+		t.Errorf("expected boards to summarize as their histories. Expected:\n%v\nObserved:\n%v", expected, s)
+	}
+}
+
+func TestSummary_CompareRed(t *testing.T) {
+	a, b := New(bitboard.Standard, 0), New(bitboard.Standard, 0)
+	a.Games, a.Red = 100, 60
+	b.Games, b.Red = 100, 40
+	if d := a.CompareRed(b); d.Estimate <= 0 || !d.Significant(0.05) {
+		t.Errorf("expected a significant difference, observed %v", d)
+	}
+	if d := a.CompareBlue(b); d.Significant(0.05) {
+		t.Errorf("expected no difference, observed %v", d)
+	}
+}
@@ -0,0 +1,140 @@
+package stats
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// columns names the fields of a summary's CSV rows, in the order in which they're written
+var columns = []string{"section", "key", "count", "proportion", "low", "high"}
+
+// rows flattens a summary into CSV rows, one per outcome, game length, winning move index and column, leaving
+// interval fields blank where they aren't meaningful
+func rows(s *Summary) [][]string {
+	format := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', 6, 64)
+	}
+	proportion := func(n, total int) string {
+		if total == 0 {
+			return ""
+		}
+		return format(float64(n) / float64(total))
+	}
+
+	var r [][]string
+	for _, o := range []struct {
+		key      string
+		count    int
+		interval Interval
+	}{{"red", s.Red, s.RedRate()}, {"blue", s.Blue, s.BlueRate()}, {"draw", s.Draws, s.DrawRate()}} {
+		r = append(r, []string{"outcome", o.key, strconv.Itoa(o.count), proportion(o.count, s.Games),
+			format(o.interval.Low), format(o.interval.High)})
+	}
+
+	for _, h := range []struct {
+		section   string
+		histogram Histogram
+	}{{"length", s.Lengths}, {"winning_move", s.Wins}} {
+		for v, n := range h.histogram {
+			if n > 0 {
+				r = append(r, []string{h.section, strconv.Itoa(v), strconv.Itoa(n), proportion(n, h.histogram.Total()),
+					"", ""})
+			}
+		}
+	}
+
+	for _, c := range []struct {
+		section string
+		counts  []int
+	}{{"red_column", s.RedColumns}, {"blue_column", s.BlueColumns}, {"column", s.Columns()}} {
+		total := 0
+		for _, n := range c.counts {
+			total += n
+		}
+		for col, n := range c.counts {
+			r = append(r, []string{c.section, strconv.Itoa(col), strconv.Itoa(n), proportion(n, total), "", ""})
+		}
+	}
+	return r
+}
+
+// WriteCSV writes the summary as CSV in long form, with a header row, such that every statistic is a row keyed by
+// its section and key
+func WriteCSV(w io.Writer, s *Summary) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return fmt.Errorf("cannot write summary: %w", err)
+	}
+	for _, r := range rows(s) {
+		if err := cw.Write(r); err != nil {
+			return fmt.Errorf("cannot write summary: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("cannot write summary: %w", err)
+	}
+	return nil
+}
+
+// WriteTable writes the summary as human-readable tables of outcomes, game lengths and column frequencies
+func WriteTable(w io.Writer, s *Summary) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "%v games on %v (%.0f%% confidence)\n\n", s.Games, s.Geometry, s.Confidence*100)
+
+	fmt.Fprintf(tw, "outcome\tgames\trate\tWilson\tClopper-Pearson\n")
+	for _, o := range []struct {
+		name  string
+		count int
+	}{{"RED", s.Red}, {"BLUE", s.Blue}, {"draw", s.Draws}} {
+		wilson, cp := Wilson(o.count, s.Games, s.Confidence), ClopperPearson(o.count, s.Games, s.Confidence)
+		fmt.Fprintf(tw, "%v\t%v\t%.4f\t[%.4f, %.4f]\t[%.4f, %.4f]\n", o.name, o.count, wilson.Estimate, wilson.Low,
+			wilson.High, cp.Low, cp.High)
+	}
+
+	fmt.Fprintf(tw, "\nmoves\tgames\twon\tdistribution\n")
+	for v, n := range s.Lengths {
+		if n == 0 {
+			continue
+		}
+		won := 0
+		if v < len(s.Wins) {
+			won = s.Wins[v]
+		}
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\n", v, n, won, bar(n, s.Games))
+	}
+	fmt.Fprintf(tw, "mean\t%.2f\t%.2f\n", s.Lengths.Mean(), s.Wins.Mean())
+
+	fmt.Fprintf(tw, "\ncolumn\tRED\tBLUE\tdistribution\n")
+	columns, total := s.Columns(), 0
+	for _, n := range columns {
+		total += n
+	}
+	for col, n := range columns {
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\n", col, s.RedColumns[col], s.BlueColumns[col], bar(n, total))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("cannot write summary: %w", err)
+	}
+	return nil
+}
+
+// bar draws a proportion as a bar of up to 40 characters
+func bar(n, total int) string {
+	if total == 0 {
+		return ""
+	}
+	return strings.Repeat("#", (40*n+total/2)/total)
+}
+
+// String formats the summary as human-readable tables
+func (s *Summary) String() string {
+	var b strings.Builder
+	_ = WriteTable(&b, s)
+	return b.String()
+}
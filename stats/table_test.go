@@ -0,0 +1,56 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+)
+
+func TestWriteCSV(t *testing.T) {
+	s, _ := Summarize(bitboard.Standard, games, 0)
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error reading CSV: %v", err)
+	}
+	if strings.Join(records[0], ",") != strings.Join(columns, ",") {
+		t.Errorf("unexpected header %v", records[0])
+	}
+
+	// Three outcomes, two lengths, two winning move indices, and three sets of seven columns
+	if len(records) != 1+3+2+2+21 {
+		t.Errorf("expected %v records, observed %v", 1+3+2+2+21, len(records))
+	}
+	for _, want := range []string{"outcome,red,2,0.666667,", "length,8,1,0.333333,,", "column,0,10,0.454545,,"} {
+		if !containsRecord(records, want) {
+			t.Errorf("expected a record beginning %q", want)
+		}
+	}
+}
+
+// containsRecord reports whether any record, joined by commas, begins with the prefix
+func containsRecord(records [][]string, prefix string) bool {
+	for _, r := range records {
+		if strings.HasPrefix(strings.Join(r, ","), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestWriteTable(t *testing.T) {
+	s, _ := Summarize(bitboard.Standard, games, 0)
+	table := s.String()
+	for _, want := range []string{"3 games on 7x6 (95% confidence)", "RED", "Clopper-Pearson", "mean", "7.33"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("expected table to contain %q, observed\n%v", want, table)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+// Lookahead searches a fixed number of plies ahead, counting its own move, and chooses randomly among the moves
+// scoring best. Positions at the search horizon are scored as draws, so Lookahead plays for wins it can see and
+// against losses it can see, and is otherwise random. As every position searched, including those at the horizon, is
+// first checked for an immediate win by the player to move, a depth of one both takes immediate wins and avoids
+// handing the opponent one, a depth of two also plays for wins the opponent can't block in a single move, and deeper
+// searches play progressively stronger, at exponentially increasing cost. A depth of zero plays randomly
+type Lookahead struct {
+	Depth int
+}
+
+// Choose returns a random choice among the moves scoring best within the search depth
+func (s Lookahead) Choose(ctx context.Context, p bitboard.Position, rng *rand.Rand) (board.Move, error) {
+	moves := p.LegalMoves()
+	if len(moves) == 0 {
+		return 0, fmt.Errorf("cannot choose move: %w", bitboard.GameOverError{})
+	}
+	if s.Depth <= 0 {
+		return moves[rng.Intn(len(moves))], nil
+	}
+
+	var best []board.Move
+	bestScore := -p.Geometry().Cells()
+	for _, m := range moves {
+		if err := ctx.Err(); err != nil {
+			return 0, fmt.Errorf("cannot choose move: %w", err)
+		}
+
+		var score int
+		switch child := p.Play(int(m)); {
+		case p.IsWinningMove(int(m)):
+			score = (p.Geometry().Cells() + 1 - p.Moves()) / 2
+		case !child.IsFull():
+			score = -lookahead(child, s.Depth-1, -p.Geometry().Cells(), p.Geometry().Cells())
+		}
+
+		switch {
+		case score > bestScore:
+			best, bestScore = []board.Move{m}, score
+		case score == bestScore:
+			best = append(best, m)
+		}
+	}
+	return best[rng.Intn(len(best))], nil
+}
+
+func (s Lookahead) String() string {
+	return fmt.Sprintf("lookahead:%v", s.Depth)
+}
+
+// lookahead returns the negamax score of an unfinished position searched to the given depth, scoring wins as the
+// solver does and positions at the horizon as draws
+func lookahead(p bitboard.Position, depth, alpha, beta int) int {
+	cells := p.Geometry().Cells()
+	if p.CanWinNext() {
+		return (cells + 1 - p.Moves()) / 2
+	}
+	if depth == 0 {
+		return 0
+	}
+
+	for _, m := range p.LegalMoves() {
+		score := 0
+		if child := p.Play(int(m)); !child.IsFull() {
+			score = -lookahead(child, depth-1, -beta, -alpha)
+		}
+		if score >= beta {
+			return score
+		}
+		if score > alpha {
+			alpha = score
+		}
+	}
+	return alpha
+}
+
+// Mix plays a uniformly random move with probability Epsilon, and the strategy's move otherwise, such that skill can
+// be diluted by chance in precise measure
+type Mix struct {
+	Epsilon  float64
+	Strategy Strategy
+}
+
+// Choose returns a random move with probability Epsilon, and the strategy's move otherwise
+func (s Mix) Choose(ctx context.Context, p bitboard.Position, rng *rand.Rand) (board.Move, error) {
+	if rng.Float64() < s.Epsilon {
+		return Random{}.Choose(ctx, p, rng)
+	}
+	return s.Strategy.Choose(ctx, p, rng)
+}
+
+func (s Mix) String() string {
+	return fmt.Sprintf("mix:%g:%v", s.Epsilon, Name(s.Strategy))
+}
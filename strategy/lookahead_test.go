@@ -0,0 +1,118 @@
+package strategy
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/solver"
+)
+
+func TestLookahead_Choose(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	table := []struct {
+		name  string
+		depth int
+		moves board.History
+		want  map[board.Move]bool
+	}{
+		{"takes win", 1, board.History{0, 1, 0, 1, 0, 1}, map[board.Move]bool{0: true}},
+		{"blocks win", 1, board.History{0, 1, 0, 1, 0}, map[board.Move]bool{0: true}},
+		{"makes double threat", 2, board.History{2, 2, 3, 3}, map[board.Move]bool{1: true, 4: true}},
+	}
+
+	for _, r := range table {
+		p, _ := bitboard.FromHistory(bitboard.Standard, r.moves)
+		for i := 0; i < 20; i++ {
+			m, err := Lookahead{Depth: r.depth}.Choose(context.Background(), p, rng)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !r.want[m] {
+				t.Fatalf("%v: expected one of columns %v, observed %v", r.name, r.want, m)
+			}
+		}
+	}
+
+	// Without enough depth to see the double threat, any move may be chosen
+	p, _ := bitboard.FromHistory(bitboard.Standard, board.History{2, 2, 3, 3})
+	seen := map[board.Move]bool{}
+	for i := 0; i < 200; i++ {
+		m, _ := Lookahead{Depth: 1}.Choose(context.Background(), p, rng)
+		seen[m] = true
+	}
+	if len(seen) != bitboard.Standard.Width {
+		t.Errorf("lookahead of depth one should choose every column, observed %v", seen)
+	}
+}
+
+// TestLookahead_full asserts that a search reaching the end of the game plays exactly the optimal moves
+func TestLookahead_full(t *testing.T) {
+	g := bitboard.Geometry{Width: 4, Height: 4}
+	sv, err := solver.New(g, solver.Config{Workers: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rng := rand.New(rand.NewSource(3))
+	for _, h := range []board.History{{0, 1, 2, 3, 0, 1}, {1, 1, 2, 2, 0}, {3, 3, 3, 0, 1, 2, 0}} {
+		p, _ := bitboard.FromHistory(g, h)
+		optimal, _, err := Optimal(context.Background(), sv, p)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := map[board.Move]bool{}
+		for _, m := range optimal {
+			want[m] = true
+		}
+
+		seen := map[board.Move]bool{}
+		for i := 0; i < 50; i++ {
+			m, err := Lookahead{Depth: g.Cells()}.Choose(context.Background(), p, rng)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			seen[m] = true
+		}
+		if len(seen) != len(want) {
+			t.Errorf("moves %v: expected optimal moves %v, observed %v", h, want, seen)
+		}
+		for m := range seen {
+			if !want[m] {
+				t.Errorf("moves %v: chose suboptimal move %v, expected one of %v", h, m, want)
+			}
+		}
+	}
+}
+
+func TestMix_Choose(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	p, _ := bitboard.FromHistory(bitboard.Standard, board.History{0, 1, 0, 1, 0, 1})
+
+	// Never random, the mixture always takes the win
+	for i := 0; i < 50; i++ {
+		if m, _ := (Mix{Epsilon: 0, Strategy: Greedy{}}).Choose(context.Background(), p, rng); m != 0 {
+			t.Fatalf("expected column 0, observed %v", m)
+		}
+	}
+
+	// Half random, the mixture takes the win more often than not, but not always
+	wins := 0
+	for i := 0; i < 1000; i++ {
+		if m, _ := (Mix{Epsilon: 0.5, Strategy: Greedy{}}).Choose(context.Background(), p, rng); m == 0 {
+			wins++
+		}
+	}
+	// With probability 0.5 + 0.5/7 of taking the win, expect about 571 wins
+	if wins < 500 || wins > 650 {
+		t.Errorf("expected about 571 wins in 1000, observed %v", wins)
+	}
+}
+
+func TestLookahead_String(t *testing.T) {
+	if n := Name(Mix{Epsilon: 0.25, Strategy: Lookahead{Depth: 4}}); n != "mix:0.25:lookahead:4" {
+		t.Errorf("unexpected name %q", n)
+	}
+}
@@ -0,0 +1,65 @@
+package strategy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parse returns the strategy with the given name, as returned by Name for the built-in strategies, such that
+// strategies can be named in configuration files and on the command line. The recognized names are:
+//
+//	random                uniformly random play
+//	greedy                immediate wins and blocks, otherwise random
+//	perfect               optimal play, breaking ties randomly
+//	perfect:deterministic optimal play, breaking ties in favor of central columns
+//	lookahead:<depth>     a depth-limited search, as in "lookahead:4"
+//	mix:<epsilon>:<name>  random play with probability epsilon, and the named strategy otherwise, as in
+//	                      "mix:0.1:perfect"
+func Parse(name string) (Strategy, error) {
+	kind, arg := name, ""
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		kind, arg = name[:i], name[i+1:]
+	}
+
+	switch {
+	case kind == "random" && arg == "":
+		return Random{}, nil
+	case kind == "greedy" && arg == "":
+		return Greedy{}, nil
+	case kind == "perfect" && arg == "":
+		return &Perfect{}, nil
+	case kind == "perfect" && arg == "deterministic":
+		return &Perfect{Deterministic: true}, nil
+	case kind == "lookahead":
+		depth, err := strconv.Atoi(arg)
+		if err != nil || depth < 0 {
+			return nil, ParseError{Name: name, Reason: "depth must be a non-negative integer"}
+		}
+		return Lookahead{Depth: depth}, nil
+	case kind == "mix":
+		i := strings.IndexByte(arg, ':')
+		if i < 0 {
+			return nil, ParseError{Name: name, Reason: "expected mix:<epsilon>:<name>"}
+		}
+		epsilon, err := strconv.ParseFloat(arg[:i], 64)
+		if err != nil || epsilon < 0 || epsilon > 1 {
+			return nil, ParseError{Name: name, Reason: "epsilon must be between 0 and 1"}
+		}
+		s, err := Parse(arg[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		return Mix{Epsilon: epsilon, Strategy: s}, nil
+	}
+	return nil, ParseError{Name: name, Reason: "unknown strategy"}
+}
+
+// ParseError defines an error used when parsing a strategy name that doesn't name a strategy
+type ParseError struct {
+	Name, Reason string
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("cannot parse strategy %q: %v", e.Name, e.Reason)
+}
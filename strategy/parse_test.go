@@ -0,0 +1,29 @@
+package strategy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, name := range []string{"random", "greedy", "perfect", "perfect:deterministic", "lookahead:0",
+		"lookahead:12", "mix:0.1:perfect", "mix:0.5:mix:1:greedy", "mix:1:lookahead:3"} {
+		s, err := Parse(name)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", name, err)
+			continue
+		}
+		if got := Name(s); got != name {
+			t.Errorf("%q: expected the name to round trip, observed %q", name, got)
+		}
+	}
+}
+
+func TestParse_errors(t *testing.T) {
+	for _, name := range []string{"", "minimax", "random:1", "perfect:sometimes", "lookahead", "lookahead:-1",
+		"lookahead:x", "mix:0.1", "mix:2:random", "mix:0.1:unknown"} {
+		if _, err := Parse(name); !errors.As(err, new(ParseError)) {
+			t.Errorf("%q: expected a parse error, observed %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/solver"
+)
+
+// Perfect plays optimally, choosing among the moves achieving the exact score of the position. A won position is
+// won as quickly as possible, and a lost position is lost as slowly as possible. Solvers are constructed lazily, one
+// per geometry, and shared by every game the strategy plays
+type Perfect struct {
+	// Config configures the solvers, excepting the table, which must be nil as tables are bound to a geometry
+	Config solver.Config
+	// Memory sets the size of each solver's table, in bytes, defaulting to solver.DefaultBudget if zero
+	Memory int
+	// Deterministic breaks ties between optimal moves in favor of central columns, rather than randomly
+	Deterministic bool
+	// Solvers, if not nil, provides the solver of each geometry in place of constructing one from Config, such as to
+	// share solvers, and so the memory of their tables, with other users
+	Solvers func(g bitboard.Geometry) (*solver.Solver, error)
+
+	mutex   sync.Mutex
+	solvers map[bitboard.Geometry]*solver.Solver
+}
+
+// Solver returns the solver the strategy searches positions of a geometry with, constructing it if necessary
+func (s *Perfect) Solver(g bitboard.Geometry) (*solver.Solver, error) {
+	if s.Solvers != nil {
+		return s.Solvers(g)
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if sv, ok := s.solvers[g]; ok {
+		return sv, nil
+	}
+	c := s.Config
+	if s.Memory > 0 {
+		t, err := solver.NewTable(g, s.Memory, solver.TwoTier)
+		if err != nil {
+			return nil, err
+		}
+		c.Table = t
+	}
+	sv, err := solver.New(g, c)
+	if err != nil {
+		return nil, err
+	}
+	if s.solvers == nil {
+		s.solvers = map[bitboard.Geometry]*solver.Solver{}
+	}
+	s.solvers[g] = sv
+	return sv, nil
+}
+
+// Choose returns an optimal move
+func (s *Perfect) Choose(ctx context.Context, p bitboard.Position, rng *rand.Rand) (board.Move, error) {
+	sv, err := s.Solver(p.Geometry())
+	if err != nil {
+		return 0, fmt.Errorf("cannot choose move: %w", err)
+	}
+	moves, _, err := Optimal(ctx, sv, p)
+	if err != nil {
+		return 0, fmt.Errorf("cannot choose move: %w", err)
+	}
+	if s.Deterministic {
+		return moves[0], nil
+	}
+	return moves[rng.Intn(len(moves))], nil
+}
+
+func (s *Perfect) String() string {
+	if s.Deterministic {
+		return "perfect:deterministic"
+	}
+	return "perfect"
+}
+
+// Optimal returns every move achieving the exact score of an unfinished position, ordered from the center outwards,
+// along with that score, as found by Solver.Optimal
+func Optimal(ctx context.Context, sv *solver.Solver, p bitboard.Position) ([]board.Move, solver.Score, error) {
+	if p.IsOver() {
+		return nil, 0, bitboard.GameOverError{}
+	}
+	score, moves, err := sv.Optimal(ctx, p)
+	return moves, score, err
+}
@@ -0,0 +1,77 @@
+// Package strategy defines the Strategy interface, through which simulations, tournaments, and interactive play
+// obtain moves, along with a set of built-in strategies ranging from purely random to perfect play.
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+// Strategy chooses moves. Implementations must draw any randomness from the given source only, such that games are
+// reproducible from a seed, and must be safe for concurrent use, as a single Strategy may play many games at once
+type Strategy interface {
+	// Choose returns a playable column for the player to move in an unfinished position
+	Choose(ctx context.Context, p bitboard.Position, rng *rand.Rand) (board.Move, error)
+}
+
+// Random chooses uniformly among the playable columns
+type Random struct{}
+
+// Choose returns a uniformly random playable column
+func (Random) Choose(_ context.Context, p bitboard.Position, rng *rand.Rand) (board.Move, error) {
+	moves := p.LegalMoves()
+	if len(moves) == 0 {
+		return 0, fmt.Errorf("cannot choose move: %w", bitboard.GameOverError{})
+	}
+	return moves[rng.Intn(len(moves))], nil
+}
+
+func (Random) String() string {
+	return "random"
+}
+
+// Greedy takes an immediate win when one is available, blocks the opponent's immediate win when one is threatened,
+// avoids moves that hand the opponent an immediate win where possible, and otherwise chooses randomly
+type Greedy struct{}
+
+// Choose returns a winning move, a blocking move, or failing both, a random move that doesn't lose immediately
+func (Greedy) Choose(_ context.Context, p bitboard.Position, rng *rand.Rand) (board.Move, error) {
+	moves := p.LegalMoves()
+	if len(moves) == 0 {
+		return 0, fmt.Errorf("cannot choose move: %w", bitboard.GameOverError{})
+	}
+
+	for _, m := range moves {
+		if p.IsWinningMove(int(m)) {
+			return m, nil
+		}
+	}
+
+	var safe []board.Move
+	nonLosing := p.NonLosingMoves()
+	for _, m := range moves {
+		if nonLosing&p.ColumnMask(int(m)) != 0 {
+			safe = append(safe, m)
+		}
+	}
+	if len(safe) > 0 {
+		moves = safe
+	}
+	return moves[rng.Intn(len(moves))], nil
+}
+
+func (Greedy) String() string {
+	return "greedy"
+}
+
+// Name returns the name of a strategy for reports, being its String method if it has one, or its type otherwise
+func Name(s Strategy) string {
+	if n, ok := s.(fmt.Stringer); ok {
+		return n.String()
+	}
+	return fmt.Sprintf("%T", s)
+}
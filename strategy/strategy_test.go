@@ -0,0 +1,131 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/solver"
+)
+
+func TestRandom_Choose(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	p, _ := bitboard.FromHistory(bitboard.Standard, board.History{0, 0, 0, 0, 0, 0})
+
+	seen := map[board.Move]bool{}
+	for i := 0; i < 1000; i++ {
+		m, err := Random{}.Choose(context.Background(), p, rng)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !p.CanPlay(int(m)) {
+			t.Fatalf("random chose unplayable column %v", m)
+		}
+		seen[m] = true
+	}
+	if len(seen) != 6 {
+		t.Errorf("random should choose every playable column, observed %v", seen)
+	}
+}
+
+func TestGreedy_Choose(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	table := []struct {
+		name  string
+		moves board.History
+		want  board.Move
+	}{
+		{"takes win", board.History{0, 1, 0, 1, 0, 1}, 0},
+		{"blocks win", board.History{0, 1, 0, 1, 0}, 0},
+		{"prefers win to block", board.History{0, 1, 0, 1, 0, 1, 6, 1}, 0},
+	}
+
+	for _, r := range table {
+		p, _ := bitboard.FromHistory(bitboard.Standard, r.moves)
+		for i := 0; i < 20; i++ {
+			if m, _ := (Greedy{}).Choose(context.Background(), p, rng); m != r.want {
+				t.Fatalf("%v: expected column %v, observed %v", r.name, r.want, m)
+			}
+		}
+	}
+
+	// Avoids playing beneath the opponent's winning square: BLUE threatens row 1 at column 3
+	p, _ := bitboard.FromHistory(bitboard.Standard, board.History{0, 0, 1, 1, 6, 2, 6, 2})
+	for i := 0; i < 50; i++ {
+		if m, _ := (Greedy{}).Choose(context.Background(), p, rng); m == 3 {
+			t.Fatalf("greedy should not play beneath opponent's winning square\n%v", p)
+		}
+	}
+}
+
+func TestPerfect_Choose(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	g := bitboard.Geometry{Width: 5, Height: 4}
+	s := &Perfect{}
+
+	p, _ := bitboard.FromHistory(g, board.History{2, 2, 1})
+	m, err := s.Choose(context.Background(), p, rng)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sv, _ := s.Solver(g)
+	scores, ok, _ := sv.Analyze(context.Background(), p)
+	for col := range scores {
+		if ok[col] && scores[col] > scores[m] {
+			t.Errorf("perfect chose column %v scoring %v, but column %v scores %v", m, scores[m], col, scores[col])
+		}
+	}
+
+	d := &Perfect{Deterministic: true}
+	first, _ := d.Choose(context.Background(), p, rng)
+	for i := 0; i < 10; i++ {
+		if m, _ := d.Choose(context.Background(), p, rng); m != first {
+			t.Fatalf("deterministic perfect chose %v, then %v", first, m)
+		}
+	}
+}
+
+func TestPerfect_Solvers(t *testing.T) {
+	g := bitboard.Geometry{Width: 4, Height: 4}
+	shared, err := solver.New(g, solver.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Perfect{Solvers: func(bitboard.Geometry) (*solver.Solver, error) { return shared, nil }}
+	p, _ := bitboard.FromHistory(g, board.History{1})
+	if _, err := s.Choose(context.Background(), p, rand.New(rand.NewSource(1))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if shared.Nodes() == 0 || s.solvers != nil {
+		t.Errorf("expected the shared solver to search in place of its own, observed %v nodes", shared.Nodes())
+	}
+}
+
+func TestChoose_gameOver(t *testing.T) {
+	p, _ := bitboard.FromHistory(bitboard.Standard, board.History{0, 1, 0, 1, 0, 1, 0})
+	for _, s := range []Strategy{Random{}, Greedy{}, &Perfect{}} {
+		if _, err := s.Choose(context.Background(), p, rand.New(rand.NewSource(1))); !errors.Is(err,
+			bitboard.GameOverError{}) {
+			t.Errorf("%v: expected %v, observed %v", Name(s), bitboard.GameOverError{}, err)
+		}
+	}
+}
+
+func TestName(t *testing.T) {
+	if Name(Random{}) != "random" || Name(&Perfect{}) != "perfect" {
+		t.Errorf("strategies should be named by their String method")
+	}
+	if Name(anonymousStrategy{}) != "strategy.anonymousStrategy" {
+		t.Errorf("strategies without a String method should be named by type, observed %q", Name(anonymousStrategy{}))
+	}
+}
+
+type anonymousStrategy struct{}
+
+func (anonymousStrategy) Choose(context.Context, bitboard.Position, *rand.Rand) (board.Move, error) {
+	return 0, nil
+}
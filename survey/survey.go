@@ -0,0 +1,181 @@
+// Package survey solves the empty position of every board geometry in a range, recording who wins, how quickly, with
+// which first move, and at what cost, such that the dependence of the "player order" edge on the shape of the board
+// can be read off a single table.
+package survey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/solver"
+)
+
+// Status is an enumerated type describing how the survey of a single geometry ended
+type Status uint8
+
+// Solved geometries were solved within budget. TimedOut geometries exhausted their budget mid-solve, while Skipped
+// geometries were never attempted, as a smaller geometry had already exhausted its budget
+const (
+	Solved Status = iota
+	TimedOut
+	Skipped
+)
+
+func (s Status) String() string {
+	switch s {
+	case Solved:
+		return "solved"
+	case TimedOut:
+		return "timed out"
+	default:
+		return "skipped"
+	}
+}
+
+// Config holds the parameters of a survey
+type Config struct {
+	Min, Max bitboard.Geometry // Range of geometries to survey, inclusive in both dimensions
+	Budget   time.Duration     // Time allowed per geometry, or unlimited if zero
+	Workers  int               // Number of solver workers per geometry
+	Memory   int               // Transposition table budget per geometry in bytes, or solver.DefaultBudget if zero
+
+	// Progress, if not nil, is called with each result as soon as it's recorded
+	Progress func(Result)
+}
+
+// Result holds the outcome of surveying a single geometry. Fields other than Geometry and Status are only meaningful
+// for solved geometries
+type Result struct {
+	Geometry bitboard.Geometry
+	Status   Status
+	Score    solver.Score  // Score of the empty position, from the perspective of RED, who moves first
+	Winner   board.Type    // Winner under optimal play, or NONE for a draw
+	Plies    int           // Length of the game under optimal play
+	Best     board.Move    // Most central optimal first move
+	Nodes    uint64        // Positions searched to find the score and the best move
+	Elapsed  time.Duration // Time taken to find the score and the best move
+}
+
+// Run surveys every geometry in the configured range, in order of increasing area, such that small geometries are
+// solved before large ones. A geometry is skipped without an attempt if it's at least as large in both dimensions as
+// a geometry that timed out, or if it can't be represented as a bitboard.Position. Run only returns an error if the
+// context is done, along with the results recorded until then
+func Run(ctx context.Context, c Config) ([]Result, error) {
+	var geometries []bitboard.Geometry
+	for w := c.Min.Width; w <= c.Max.Width; w++ {
+		for h := c.Min.Height; h <= c.Max.Height; h++ {
+			geometries = append(geometries, bitboard.Geometry{Width: w, Height: h})
+		}
+	}
+	sortByArea(geometries)
+
+	var results, timedOut []Result
+	for _, g := range geometries {
+		r := Result{Geometry: g, Status: Skipped}
+		if g.Validate() == nil && !dominates(timedOut, g) {
+			var err error
+			if r, err = survey(ctx, c, g); err != nil {
+				return results, fmt.Errorf("cannot survey %v: %w", g, err)
+			}
+		}
+		if r.Status == TimedOut {
+			timedOut = append(timedOut, r)
+		}
+
+		results = append(results, r)
+		if c.Progress != nil {
+			c.Progress(r)
+		}
+	}
+	return results, nil
+}
+
+// survey solves a single geometry within the configured budget, reporting TimedOut if the budget is exhausted
+func survey(ctx context.Context, c Config, g bitboard.Geometry) (Result, error) {
+	r := Result{Geometry: g}
+	memory := c.Memory
+	if memory == 0 {
+		memory = solver.DefaultBudget
+	}
+	t, err := solver.NewTable(g, memory, solver.TwoTier)
+	if err != nil {
+		return r, err
+	}
+	s, err := solver.New(g, solver.Config{Workers: c.Workers, Table: t})
+	if err != nil {
+		return r, err
+	}
+
+	budget := ctx
+	if c.Budget > 0 {
+		var cancel context.CancelFunc
+		budget, cancel = context.WithTimeout(ctx, c.Budget)
+		defer cancel()
+	}
+
+	start := time.Now()
+	p := bitboard.New(g)
+	r.Score, err = s.Solve(budget, p)
+	if err == nil {
+		r.Best, err = best(budget, s, p, r.Score)
+	}
+	r.Nodes, r.Elapsed = s.Nodes(), time.Since(start)
+
+	switch {
+	case ctx.Err() != nil:
+		return r, ctx.Err()
+	case errors.Is(err, context.DeadlineExceeded):
+		r.Status = TimedOut
+		return r, nil
+	case err != nil:
+		return r, err
+	}
+
+	r.Status, r.Winner, r.Plies = Solved, r.Score.Winner(p), r.Score.Plies(p)
+	return r, nil
+}
+
+// best finds the most central move achieving the score of a position
+func best(ctx context.Context, s *solver.Solver, p bitboard.Position, score solver.Score) (board.Move, error) {
+	for _, col := range p.Geometry().Order() {
+		if !p.CanPlay(col) {
+			continue
+		}
+		child, err := s.Solve(ctx, p.Play(col))
+		if err != nil {
+			return 0, err
+		}
+		if -child == score {
+			return board.Move(col), nil
+		}
+	}
+
+	// Unreachable, as some move must achieve the score of the position
+	return 0, fmt.Errorf("no move achieves score %v", score)
+}
+
+// dominates returns whether any of the results is of a geometry no larger than g in either dimension
+func dominates(results []Result, g bitboard.Geometry) bool {
+	for _, r := range results {
+		if r.Geometry.Width <= g.Width && r.Geometry.Height <= g.Height {
+			return true
+		}
+	}
+	return false
+}
+
+// sortByArea sorts geometries by increasing area, breaking ties by width, with an insertion sort, as lists are short
+func sortByArea(gs []bitboard.Geometry) {
+	less := func(a, b bitboard.Geometry) bool {
+		return a.Cells() < b.Cells() || (a.Cells() == b.Cells() && a.Width < b.Width)
+	}
+	for i := 1; i < len(gs); i++ {
+		for j := i; j > 0 && less(gs[j], gs[j-1]); j-- {
+			gs[j], gs[j-1] = gs[j-1], gs[j]
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package survey
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+func TestRun(t *testing.T) {
+	var progress []Result
+	results, err := Run(context.Background(), Config{
+		Min:      bitboard.Geometry{Width: 4, Height: 4},
+		Max:      bitboard.Geometry{Width: 6, Height: 4},
+		Memory:   1 << 20,
+		Progress: func(r Result) { progress = append(progress, r) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 3 || len(progress) != 3 {
+		t.Fatalf("expected 3 results and 3 progress reports, observed %v and %v", len(results), len(progress))
+	}
+
+	// The first player can do no better than a draw on 4x4 and 5x4, and loses on 6x4
+	want := []struct {
+		g      bitboard.Geometry
+		winner board.Type
+	}{
+		{bitboard.Geometry{Width: 4, Height: 4}, board.NONE},
+		{bitboard.Geometry{Width: 5, Height: 4}, board.NONE},
+		{bitboard.Geometry{Width: 6, Height: 4}, board.BLUE},
+	}
+	for i, r := range results {
+		if r.Geometry != want[i].g || r.Status != Solved || r.Winner != want[i].winner {
+			t.Errorf("unexpected result %v. Expected solved %v with winner %v, observed %+v",
+				i, want[i].g, want[i].winner, r)
+		}
+		if r.Nodes == 0 {
+			t.Errorf("%v should report the nodes searched", r.Geometry)
+		}
+
+		// No game can outlast its board
+		if r.Status == Solved && r.Plies > r.Geometry.Cells() {
+			t.Errorf("%v game cannot last %v plies", r.Geometry, r.Plies)
+		}
+	}
+}
+
+// TestRun_budget asserts that a geometry exhausting its budget times out, and that larger geometries are skipped
+func TestRun_budget(t *testing.T) {
+	results, err := Run(context.Background(), Config{
+		Min:    bitboard.Geometry{Width: 7, Height: 5},
+		Max:    bitboard.Geometry{Width: 8, Height: 5},
+		Budget: time.Millisecond,
+		Memory: 1 << 20,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(results) != 2 || results[0].Status != TimedOut || results[1].Status != Skipped {
+		t.Errorf("expected 7x5 to time out and 8x5 to be skipped, observed %+v", results)
+	}
+}
+
+func TestRun_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Run(ctx, Config{
+		Min: bitboard.Geometry{Width: 7, Height: 6},
+		Max: bitboard.Geometry{Width: 7, Height: 6},
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, observed %v", context.Canceled, err)
+	}
+}
+
+func TestRun_invalidGeometry(t *testing.T) {
+	results, err := Run(context.Background(), Config{
+		Min:    bitboard.Geometry{Width: 9, Height: 7},
+		Max:    bitboard.Geometry{Width: 9, Height: 7},
+		Memory: 1 << 20,
+	})
+	if err != nil || len(results) != 1 || results[0].Status != Skipped {
+		t.Errorf("expected 9x7 to be skipped, observed %+v (error %v)", results, err)
+	}
+}
@@ -0,0 +1,72 @@
+package survey
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// columns names the fields of a result, in the order in which they're written
+var columns = []string{"cols", "rows", "status", "winner", "score", "plies", "best_col", "nodes", "seconds"}
+
+// fields formats a result for tabular output, leaving fields blank where they aren't meaningful. Columns are numbered
+// from one, as everywhere they're shown
+func fields(r Result) []string {
+	f := []string{strconv.Itoa(r.Geometry.Width), strconv.Itoa(r.Geometry.Height), r.Status.String(), "", "", "", "",
+		"", ""}
+	if r.Status != Skipped {
+		f[7], f[8] = strconv.FormatUint(r.Nodes, 10), strconv.FormatFloat(r.Elapsed.Seconds(), 'f', 3, 64)
+	}
+	if r.Status == Solved {
+		f[3], f[4], f[5], f[6] = winner(r), strconv.Itoa(int(r.Score)), strconv.Itoa(r.Plies),
+			strconv.Itoa(int(r.Best)+1)
+	}
+	return f
+}
+
+// winner describes the winner of a solved geometry in terms of player order, rather than color
+func winner(r Result) string {
+	switch {
+	case r.Score > 0:
+		return "first"
+	case r.Score < 0:
+		return "second"
+	default:
+		return "draw"
+	}
+}
+
+// WriteCSV writes the results as CSV, with a header row
+func WriteCSV(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return fmt.Errorf("cannot write survey: %w", err)
+	}
+	for _, r := range results {
+		if err := cw.Write(fields(r)); err != nil {
+			return fmt.Errorf("cannot write survey: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("cannot write survey: %w", err)
+	}
+	return nil
+}
+
+// WriteMarkdown writes the results as a Markdown table
+func WriteMarkdown(w io.Writer, results []Result) error {
+	rows := []string{
+		"| " + strings.Join(columns, " | ") + " |",
+		"|" + strings.Repeat(" --- |", len(columns)),
+	}
+	for _, r := range results {
+		rows = append(rows, "| "+strings.Join(fields(r), " | ")+" |")
+	}
+	if _, err := io.WriteString(w, strings.Join(rows, "\n")+"\n"); err != nil {
+		return fmt.Errorf("cannot write survey: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package survey
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+var sample = []Result{
+	{Geometry: bitboard.Geometry{Width: 6, Height: 4}, Status: Solved, Score: -1, Winner: board.BLUE, Plies: 24,
+		Best: 2, Nodes: 1234, Elapsed: 1500 * time.Millisecond},
+	{Geometry: bitboard.Geometry{Width: 7, Height: 6}, Status: TimedOut, Nodes: 99, Elapsed: time.Minute},
+	{Geometry: bitboard.Geometry{Width: 8, Height: 7}, Status: Skipped},
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, sample); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"cols,rows,status,winner,score,plies,best_col,nodes,seconds",
+		"6,4,solved,second,-1,24,3,1234,1.500",
+		"7,6,timed out,,,,,99,60.000",
+		"8,7,skipped,,,,,,",
+	}, "\n") + "\n"
+	if buf.String() != want {
+		t.Errorf("unexpected CSV. Expected:\n%v\nObserved:\n%v", want, buf.String())
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, sample); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rows := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(rows) != 2+len(sample) {
+		t.Fatalf("expected header, separator, and %v rows, observed:\n%v", len(sample), buf.String())
+	}
+	if rows[2] != "| 6 | 4 | solved | second | -1 | 24 | 3 | 1234 | 1.500 |" {
+		t.Errorf("unexpected row for solved result: %q", rows[2])
+	}
+}
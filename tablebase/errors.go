@@ -0,0 +1,43 @@
+package tablebase
+
+import "fmt"
+
+// EmptySquaresError defines an error used when a tablebase is requested for a number of empty squares that isn't
+// possible in its geometry
+type EmptySquaresError int
+
+func (e EmptySquaresError) Error() string {
+	return fmt.Sprintf("number of empty squares %v is out of range", int(e))
+}
+
+// RegionError defines an error used when generating a tablebase whose region holds more positions than fit in its
+// memory budget, giving the number of stones played in the layer at which it was rejected
+type RegionError int
+
+func (e RegionError) Error() string {
+	return fmt.Sprintf("tablebase region holds too many positions to enumerate beyond %v stones", int(e))
+}
+
+// FormatError defines an error used when reading a tablebase from data that isn't a tablebase
+type FormatError [4]byte
+
+func (e FormatError) Error() string {
+	return fmt.Sprintf("unrecognized tablebase format %q", e[:])
+}
+
+// SizeError defines an error used when reading a tablebase whose header claims more entries than its data holds
+type SizeError uint64
+
+func (e SizeError) Error() string {
+	return fmt.Sprintf("tablebase header claims %v entries, which the data does not hold", uint64(e))
+}
+
+// MismatchError defines an error used when a tablebase entry disagrees with a forward search of its position
+type MismatchError struct {
+	Key              uint64
+	Stored, Searched Value
+}
+
+func (e MismatchError) Error() string {
+	return fmt.Sprintf("position %#x is stored as a %v but searches as a %v", e.Key, e.Stored, e.Searched)
+}
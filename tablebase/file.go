@@ -0,0 +1,116 @@
+package tablebase
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/internal/chunked"
+)
+
+// tablebaseMagic identifies a file as a tablebase, and the version of its format
+var tablebaseMagic = [4]byte{'F', 'T', 'B', 1}
+
+// tablebaseHeader precedes the entries of a tablebase file, which follow as sorted keys, then packed values
+type tablebaseHeader struct {
+	Magic    [4]byte
+	Width    uint8
+	Height   uint8
+	MaxEmpty uint8
+	_        uint8
+	Entries  uint64
+}
+
+// WriteTo writes the tablebase to the writer in its compact indexed format, such that it can be restored with Read
+func (t *Tablebase) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	h := tablebaseHeader{
+		Magic:    tablebaseMagic,
+		Width:    uint8(t.geometry.Width),
+		Height:   uint8(t.geometry.Height),
+		MaxEmpty: uint8(t.maxEmpty),
+		Entries:  uint64(len(t.keys)),
+	}
+	for _, data := range []interface{}{h, t.keys, t.values} {
+		if err := binary.Write(bw, binary.LittleEndian, data); err != nil {
+			return 0, fmt.Errorf("cannot write tablebase: %w", err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return 0, fmt.Errorf("cannot write tablebase: %w", err)
+	}
+	return int64(binary.Size(h) + 8*len(t.keys) + len(t.values)), nil
+}
+
+// Read restores a tablebase written with WriteTo
+func Read(r io.Reader) (*Tablebase, error) {
+	br := bufio.NewReader(r)
+	var h tablebaseHeader
+	if err := binary.Read(br, binary.LittleEndian, &h); err != nil {
+		return nil, fmt.Errorf("cannot read tablebase header: %w", err)
+	}
+	if h.Magic != tablebaseMagic {
+		return nil, fmt.Errorf("cannot read tablebase: %w", FormatError(h.Magic))
+	}
+
+	g := bitboard.Geometry{Width: int(h.Width), Height: int(h.Height)}
+	if err := g.Validate(); err != nil {
+		return nil, fmt.Errorf("cannot read tablebase: %w", err)
+	}
+	if int(h.MaxEmpty) > g.Cells() {
+		return nil, fmt.Errorf("cannot read tablebase: %w", EmptySquaresError(h.MaxEmpty))
+	}
+
+	t := &Tablebase{geometry: g, maxEmpty: int(h.MaxEmpty)}
+	err := chunked.Read(h.Entries, func(count int) error {
+		keys := make([]uint64, count)
+		if err := binary.Read(br, binary.LittleEndian, keys); err != nil {
+			return err
+		}
+		t.keys = append(t.keys, keys...)
+		return nil
+	})
+	if err == nil {
+		// Once the keys are read, the packed values are known to be far smaller than the data read
+		t.values = make([]byte, (h.Entries+3)/4)
+		err = binary.Read(br, binary.LittleEndian, t.values)
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("cannot read tablebase entries: %w", SizeError(h.Entries))
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot read tablebase entries: %w", err)
+	}
+	return t, nil
+}
+
+// Save writes the tablebase to a file at the given path, replacing any existing file
+func (t *Tablebase) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("cannot save tablebase: %w", err)
+	}
+	if _, err := t.WriteTo(f); err != nil {
+		f.Close()
+		return fmt.Errorf("cannot save tablebase to %v: %w", path, err)
+	}
+	return f.Close()
+}
+
+// Load restores a tablebase saved to a file with Save
+func Load(path string) (*Tablebase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load tablebase: %w", err)
+	}
+	defer f.Close()
+
+	t, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load tablebase from %v: %w", path, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,56 @@
+package tablebase
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+)
+
+func TestTablebase_Save(t *testing.T) {
+	g := bitboard.Geometry{Width: 4, Height: 4}
+	tb, _ := Generate(context.Background(), bitboard.New(g), Config{MaxEmpty: 10})
+
+	path := filepath.Join(t.TempDir(), "tablebase")
+	if err := tb.Save(path); err != nil {
+		t.Fatalf("unexpected error saving tablebase: %v", err)
+	}
+	restored, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error loading tablebase: %v", err)
+	}
+
+	if restored.Geometry() != g || restored.MaxEmpty() != 10 || restored.Len() != tb.Len() {
+		t.Fatalf("restored tablebase has unexpected configuration")
+	}
+	for i := 0; i < tb.Len(); i++ {
+		p, v := tb.Position(i)
+		if got, ok := restored.Probe(p); !ok || got != v {
+			t.Fatalf("restored tablebase has %v (found %v) for position stored as %v\n%v", got, ok, v, p)
+		}
+	}
+}
+
+func TestRead(t *testing.T) {
+	if _, err := Read(bytes.NewReader([]byte("this is certainly not a tablebase"))); !errors.As(err, new(FormatError)) {
+		t.Errorf("expected %T, observed %v", FormatError{}, err)
+	}
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, tablebaseHeader{Magic: tablebaseMagic, Width: 7, Height: 6, MaxEmpty: 8,
+		Entries: 1 << 60})
+	buf.Write(make([]byte, 100))
+	if _, err := Read(&buf); !errors.As(err, new(SizeError)) {
+		t.Errorf("expected %T, observed %v", SizeError(0), err)
+	}
+
+	buf.Reset()
+	binary.Write(&buf, binary.LittleEndian, tablebaseHeader{Magic: tablebaseMagic, Width: 4, Height: 4, MaxEmpty: 17})
+	if _, err := Read(&buf); !errors.As(err, new(EmptySquaresError)) {
+		t.Errorf("expected %T, observed %v", EmptySquaresError(0), err)
+	}
+}
@@ -0,0 +1,158 @@
+package tablebase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+// layer holds the positions of a single number of stones played, as sorted canonical keys, with their values
+type layer struct {
+	keys   []uint64
+	values []Value
+}
+
+func (l layer) find(key uint64) Value {
+	i := sort.Search(len(l.keys), func(i int) bool { return l.keys[i] >= key })
+	if i == len(l.keys) || l.keys[i] != key {
+		return Unknown
+	}
+	return l.values[i]
+}
+
+// DefaultMemory sets the memory a tablebase is generated within, if not configured
+const DefaultMemory = 2 << 30
+
+// positionSize sets the memory each position held during generation is counted as occupying: its key, its value,
+// and its share of the tablebase the layers are merged into
+const positionSize = 32
+
+// Config configures the generation of a tablebase
+type Config struct {
+	MaxEmpty int // Greatest number of empty squares of the positions in the tablebase
+	Memory   int // Bytes the positions held at once may occupy, or DefaultMemory if zero
+}
+
+// Generate builds a tablebase of every position with at most the configured number of empty squares reachable from
+// the root. As positions are enumerated forward from the root, every layer between it and the tablebase is held in
+// turn, and regions holding more positions at once than fit in the configured memory are rejected with a RegionError
+func Generate(ctx context.Context, root bitboard.Position, c Config) (*Tablebase, error) {
+	g := root.Geometry()
+	if c.MaxEmpty < 0 || c.MaxEmpty > g.Cells() {
+		return nil, fmt.Errorf("cannot generate tablebase: %w", EmptySquaresError(c.MaxEmpty))
+	}
+	if c.Memory == 0 {
+		c.Memory = DefaultMemory
+	}
+
+	// Enumerate forward, keeping only the layers within the tablebase
+	var layers []layer
+	held := 0
+	keys := []uint64{canonical(root)}
+	for moves := root.Moves(); len(keys) > 0; moves++ {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("cannot generate tablebase: %w", err)
+		}
+		if g.Cells()-moves <= c.MaxEmpty {
+			layers = append(layers, layer{keys: keys})
+			held += len(keys)
+		}
+		children, ok := expand(g, keys, c.Memory/positionSize-held-len(keys))
+		if !ok {
+			return nil, fmt.Errorf("cannot generate tablebase: %w", RegionError(moves+1))
+		}
+		keys = children
+	}
+
+	// Value backward, each position taking the best value of its children in the following layer
+	for i := len(layers) - 1; i >= 0; i-- {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("cannot generate tablebase: %w", err)
+		}
+
+		l := &layers[i]
+		l.values = make([]Value, len(l.keys))
+		for j, key := range l.keys {
+			p, _ := bitboard.FromKey(g, key)
+			switch {
+			case p.Winner() != board.NONE:
+				l.values[j] = Loss
+			case p.IsFull():
+				l.values[j] = Draw
+			default:
+				v := Loss
+				for _, m := range p.LegalMoves() {
+					if c := layers[i+1].find(canonical(p.Play(int(m)))).invert(); c > v {
+						v = c
+					}
+					if v == Win {
+						break
+					}
+				}
+				l.values[j] = v
+			}
+		}
+	}
+
+	return merge(g, c.MaxEmpty, layers), nil
+}
+
+// expand returns the sorted, deduplicated canonical keys of the children of the unfinished positions in keys, or false
+// if there are more than limit children before deduplication
+func expand(g bitboard.Geometry, keys []uint64, limit int) ([]uint64, bool) {
+	var children []uint64
+	for _, key := range keys {
+		p, _ := bitboard.FromKey(g, key)
+		for _, m := range p.LegalMoves() {
+			if len(children) >= limit {
+				return nil, false
+			}
+			children = append(children, canonical(p.Play(int(m))))
+		}
+	}
+	return dedupe(children), true
+}
+
+// dedupe sorts keys, removing duplicates in place
+func dedupe(keys []uint64) []uint64 {
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	n := 0
+	for i, key := range keys {
+		if i == 0 || key != keys[n-1] {
+			keys[n] = key
+			n++
+		}
+	}
+	return keys[:n]
+}
+
+// merge combines the layers into a single tablebase, sorted by key for probing. Keys are unique across layers, as
+// they encode the stones played
+func merge(g bitboard.Geometry, maxEmpty int, layers []layer) *Tablebase {
+	type pair struct {
+		key   uint64
+		value Value
+	}
+	var pairs []pair
+	for _, l := range layers {
+		for i := range l.keys {
+			pairs = append(pairs, pair{l.keys[i], l.values[i]})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].key < pairs[j].key })
+
+	t := &Tablebase{
+		geometry: g,
+		maxEmpty: maxEmpty,
+		keys:     make([]uint64, len(pairs)),
+		values:   make([]byte, (len(pairs)+3)/4),
+	}
+	for i, p := range pairs {
+		t.keys[i] = p.key
+		t.values[i/4] |= byte(p.value) << uint(i%4*2)
+	}
+	return t
+}
@@ -0,0 +1,98 @@
+package tablebase
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/solver"
+)
+
+// TestGenerate_complete builds a complete tablebase of a small board and checks every entry against forward search
+func TestGenerate_complete(t *testing.T) {
+	g := bitboard.Geometry{Width: 4, Height: 4}
+	tb, err := Generate(context.Background(), bitboard.New(g), Config{MaxEmpty: g.Cells()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s, _ := solver.New(g, solver.Config{})
+	for i := 0; i < tb.Len(); i++ {
+		p, v := tb.Position(i)
+		score, err := s.Solve(context.Background(), p)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ValueOf(score) != v {
+			t.Fatalf("position stored as %v, but searches as %v\n%v", v, ValueOf(score), p)
+		}
+	}
+
+	if v, _ := tb.Probe(bitboard.New(g)); v != Draw {
+		t.Errorf("empty %v board should be a %v, observed %v", g, Draw, v)
+	}
+}
+
+// TestGenerate_nearFull builds a tablebase of the last few plies below a classic position, and verifies a sample of
+// its entries against forward search
+func TestGenerate_nearFull(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	root := bitboard.New(bitboard.Standard)
+	for root.Moves() < 32 {
+		col := rng.Intn(bitboard.Standard.Width)
+		if root.CanPlay(col) && !root.Play(col).IsOver() {
+			root = root.Play(col)
+		}
+	}
+
+	const maxEmpty = 7
+	tb, err := Generate(context.Background(), root, Config{MaxEmpty: maxEmpty})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tb.Len() == 0 {
+		t.Fatalf("tablebase below\n%v\nshould not be empty", root)
+	}
+
+	for i := 0; i < tb.Len(); i++ {
+		if p, _ := tb.Position(i); p.Geometry().Cells()-p.Moves() > maxEmpty {
+			t.Fatalf("tablebase should only hold positions with at most %v empty squares, observed\n%v", maxEmpty, p)
+		}
+	}
+
+	s, _ := solver.New(bitboard.Standard, solver.Config{})
+	if err := tb.Verify(context.Background(), s, rng, 500); err != nil {
+		t.Errorf("unexpected error verifying tablebase: %v", err)
+	}
+}
+
+func TestGenerate_errors(t *testing.T) {
+	g := bitboard.Geometry{Width: 4, Height: 4}
+	if _, err := Generate(context.Background(), bitboard.New(g), Config{MaxEmpty: 17}); !errors.Is(err,
+		EmptySquaresError(17)) {
+		t.Errorf("expected %v, observed %v", EmptySquaresError(17), err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Generate(ctx, bitboard.New(g), Config{MaxEmpty: 16}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, observed %v", context.Canceled, err)
+	}
+
+	// Complete tablebases are rejected if they don't fit in memory, as are 7x6 tablebases rooted at the empty board,
+	// however few empty squares they hold
+	table := []struct {
+		root bitboard.Position
+		c    Config
+	}{
+		{bitboard.New(g), Config{MaxEmpty: 16, Memory: 1 << 20}},
+		{bitboard.New(bitboard.Standard), Config{MaxEmpty: 4, Memory: 1 << 20}},
+	}
+	for _, r := range table {
+		if _, err := Generate(context.Background(), r.root, r.c); !errors.As(err, new(RegionError)) {
+			t.Errorf("%v, %+v: expected %T, observed %v", r.root.Geometry(), r.c, RegionError(0), err)
+		}
+	}
+}
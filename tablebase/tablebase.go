@@ -0,0 +1,129 @@
+// Package tablebase implements endgame tablebases: the win, draw, or loss value of every position in a region of the
+// game tree, computed by retrograde analysis and stored in a compact indexed file.
+//
+// As every move adds a stone, positions fall into layers by the number of stones played, and each layer depends only
+// on the one after it. Retrograde analysis therefore needs no unmove generation: positions are enumerated forward,
+// layer by layer, and then valued backward from the final layer, each position taking the best of its children's
+// values. A tablebase covers every position with at most a given number of empty squares that is reachable from a
+// root position, such that rooting at the empty board yields a complete tablebase of a geometry.
+//
+// As every layer between the root and the tablebase is enumerated, limiting the number of empty squares alone doesn't
+// keep the region small, and the positions held at once are bounded by a memory budget. In the default budget,
+// complete tablebases fit for boards up to around 5x5, while a complete 6x5 tablebase, of around a billion positions,
+// takes tens of gigabytes. Tablebases of the classic 7x6 board are only generated below a root position, deep enough
+// for the region to fit: there are over a billion full 7x6 boards alone, and every layer beyond them is larger, so
+// tablebases of every 7x6 position with a few empty squares are out of reach of generation in memory, whether forward
+// from the empty board, as here, or backward from the full boards.
+package tablebase
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+)
+
+// Value is an enumerated type consisting of loss, draw, or win, from the perspective of the player to move
+type Value uint8
+
+// Loss, Draw, and Win give the value of a position under optimal play by both players, with Unknown acting as a
+// valid nil value. Values are ordered such that a greater value is better for the player to move
+const (
+	Unknown Value = iota
+	Loss
+	Draw
+	Win
+)
+
+func (v Value) String() string {
+	switch v {
+	case Loss:
+		return "loss"
+	case Draw:
+		return "draw"
+	case Win:
+		return "win"
+	default:
+		return "unknown"
+	}
+}
+
+// invert returns the value from the perspective of the opponent
+func (v Value) invert() Value {
+	switch v {
+	case Loss:
+		return Win
+	case Win:
+		return Loss
+	default:
+		return v
+	}
+}
+
+// Tablebase maps positions of a single geometry to their values, stored once per pair of mirror-image positions
+type Tablebase struct {
+	geometry bitboard.Geometry
+	maxEmpty int
+	keys     []uint64 // Canonical keys, sorted ascending
+	values   []byte   // Values, packed four to a byte, in the same order as keys
+}
+
+// Geometry returns the geometry of the positions in the tablebase
+func (t *Tablebase) Geometry() bitboard.Geometry {
+	return t.geometry
+}
+
+// MaxEmpty returns the greatest number of empty squares of any position in the tablebase
+func (t *Tablebase) MaxEmpty() int {
+	return t.maxEmpty
+}
+
+// Len returns the number of entries in the tablebase, where a position and its mirror image share a single entry
+func (t *Tablebase) Len() int {
+	return len(t.keys)
+}
+
+// canonical returns the key identifying a position and its mirror image
+func canonical(p bitboard.Position) uint64 {
+	if k, m := p.Key(), p.MirrorKey(); m < k {
+		return m
+	}
+	return p.Key()
+}
+
+func (t *Tablebase) value(i int) Value {
+	return Value(t.values[i/4] >> uint(i%4*2) & 3)
+}
+
+// Probe returns the value of a position, if the position is in the tablebase
+func (t *Tablebase) Probe(p bitboard.Position) (Value, bool) {
+	if p.Geometry() != t.geometry {
+		return Unknown, false
+	}
+
+	key := canonical(p)
+	i := sort.Search(len(t.keys), func(i int) bool { return t.keys[i] >= key })
+	if i == len(t.keys) || t.keys[i] != key {
+		return Unknown, false
+	}
+	return t.value(i), true
+}
+
+// ProbeState returns the value of the position shown by a state, if the position is in the tablebase, returning an
+// error if the state isn't a valid position
+func (t *Tablebase) ProbeState(s board.State) (Value, bool, error) {
+	p, err := bitboard.FromState(s)
+	if err != nil {
+		return Unknown, false, fmt.Errorf("cannot probe state: %w", err)
+	}
+	v, ok := t.Probe(p)
+	return v, ok, nil
+}
+
+// Position returns the position of the i-th entry of the tablebase, in the orientation of the entry's key
+func (t *Tablebase) Position(i int) (bitboard.Position, Value) {
+	// Keys in the tablebase are known to be valid, so no error can occur
+	p, _ := bitboard.FromKey(t.geometry, t.keys[i])
+	return p, t.value(i)
+}
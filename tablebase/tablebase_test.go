@@ -0,0 +1,86 @@
+package tablebase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/solver"
+)
+
+func TestTablebase_ProbeState(t *testing.T) {
+	rng := rand.New(rand.NewSource(6))
+	b, root := board.New(), bitboard.New(bitboard.Standard)
+	for root.Moves() < 34 {
+		col := rng.Intn(bitboard.Standard.Width)
+		if !root.CanPlay(col) || root.Play(col).IsOver() {
+			continue
+		}
+		if _, _, err := b.Move(col); err != nil {
+			t.Fatalf("unexpected error making move: %v", err)
+		}
+		root = root.Play(col)
+	}
+
+	tb, err := Generate(context.Background(), root, Config{MaxEmpty: 8})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s := b.State()
+	v, ok, err := tb.ProbeState(s)
+	if err != nil || !ok {
+		t.Fatalf("expected state in tablebase, observed %v, %v", ok, err)
+	}
+	sv, _ := solver.New(bitboard.Standard, solver.Config{})
+	if score, _ := sv.SolveBoard(context.Background(), b); v != ValueOf(score) {
+		t.Errorf("expected %v for player to move, observed %v\n%v", ValueOf(score), v, b)
+	}
+
+	// Lift a stone into the air, breaking Drop Validity
+	for col := range s {
+		if s[col][0] != board.NONE && s[col][board.ROWS-1] == board.NONE {
+			s[col][board.ROWS-1], s[col][0] = s[col][0], board.NONE
+			break
+		}
+	}
+	if _, _, err := tb.ProbeState(s); !errors.As(err, new(bitboard.DropValidityError)) {
+		t.Errorf("expected %T probing invalid state, observed %v", bitboard.DropValidityError{}, err)
+	}
+}
+
+func TestTablebase_Probe(t *testing.T) {
+	g := bitboard.Geometry{Width: 4, Height: 4}
+	tb, _ := Generate(context.Background(), bitboard.New(g).Play(0), Config{MaxEmpty: 14})
+
+	table := []struct {
+		name  string
+		moves board.History
+		ok    bool
+	}{
+		{"too many empty squares", board.History{0}, false},
+		{"in region", board.History{0, 1}, true},
+		{"mirror image in region", board.History{3, 2}, true},
+		{"outside region", board.History{1, 1}, false},
+	}
+
+	for _, r := range table {
+		p, _ := bitboard.FromHistory(g, r.moves)
+		if _, ok := tb.Probe(p); ok != r.ok {
+			t.Errorf("%v: expected found %v, observed %v", r.name, r.ok, ok)
+		}
+	}
+
+	if _, ok := tb.Probe(bitboard.New(bitboard.Standard)); ok {
+		t.Errorf("position of another geometry should not be found")
+	}
+}
+
+func ExampleValue_String() {
+	fmt.Println(Loss, Draw, Win)
+	// Output: loss draw win
+}
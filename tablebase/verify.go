@@ -0,0 +1,37 @@
+package tablebase
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/talglobus/fearsome/solver"
+)
+
+// Verify checks the values of randomly sampled entries against a forward search by the solver, which must be of the
+// tablebase's geometry, returning a MismatchError for the first entry found to disagree
+func (t *Tablebase) Verify(ctx context.Context, s *solver.Solver, rng *rand.Rand, samples int) error {
+	for n := 0; n < samples && len(t.keys) > 0; n++ {
+		p, v := t.Position(rng.Intn(len(t.keys)))
+		score, err := s.Solve(ctx, p)
+		if err != nil {
+			return fmt.Errorf("cannot verify tablebase: %w", err)
+		}
+		if want := ValueOf(score); v != want {
+			return fmt.Errorf("cannot verify tablebase: %w", MismatchError{Key: p.Key(), Stored: v, Searched: want})
+		}
+	}
+	return nil
+}
+
+// ValueOf returns the value corresponding to an exact score
+func ValueOf(s solver.Score) Value {
+	switch {
+	case s > 0:
+		return Win
+	case s < 0:
+		return Loss
+	default:
+		return Draw
+	}
+}
@@ -0,0 +1,24 @@
+package tournament
+
+import "fmt"
+
+// ConfigError defines an error used when a tournament is configured incorrectly
+type ConfigError string
+
+func (e ConfigError) Error() string {
+	return string(e)
+}
+
+// DuplicateError defines an error used when two entrants share a name
+type DuplicateError string
+
+func (e DuplicateError) Error() string {
+	return fmt.Sprintf("entrant name %q is used more than once", string(e))
+}
+
+// UnfinishedError defines an error used when tabulating the record of a game, with the given index, that hasn't ended
+type UnfinishedError int
+
+func (e UnfinishedError) Error() string {
+	return fmt.Sprintf("game %v is unfinished", int(e))
+}
@@ -0,0 +1,87 @@
+package tournament
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ranking returns the indices of the entrants ordered by Elo rating, highest first, breaking ties by points
+func (r Report) ranking() []int {
+	order := make([]int, len(r.Standings))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		sa, sb := r.Standings[order[a]], r.Standings[order[b]]
+		if sa.Elo.Rating != sb.Elo.Rating {
+			return sa.Elo.Rating > sb.Elo.Rating
+		}
+		return sa.Points > sb.Points
+	})
+	return order
+}
+
+// String summarizes the report as its standings
+func (r Report) String() string {
+	var b strings.Builder
+	_ = WriteStandings(&b, r)
+	return b.String()
+}
+
+// WriteStandings writes the standings as a Markdown table, highest rated first, with Elo ratings shown with their
+// standard errors, and Glicko-2 ratings with their deviations if enabled
+func WriteStandings(w io.Writer, r Report) error {
+	columns := []string{"rank", "entrant", "games", "wins", "draws", "losses", "points", "elo"}
+	if r.Config.Glicko {
+		columns = append(columns, "glicko", "volatility")
+	}
+	rows := []string{
+		"| " + strings.Join(columns, " | ") + " |",
+		"|" + strings.Repeat(" --- |", len(columns)),
+	}
+	for rank, i := range r.ranking() {
+		s := r.Standings[i]
+		fields := []string{strconv.Itoa(rank + 1), s.Name, strconv.Itoa(s.Games), strconv.Itoa(s.Wins),
+			strconv.Itoa(s.Draws), strconv.Itoa(s.Losses), strconv.FormatFloat(s.Points, 'f', 1, 64),
+			fmt.Sprintf("%+.0f ± %.0f", s.Elo.Rating, s.Elo.Error)}
+		if r.Config.Glicko {
+			fields = append(fields, fmt.Sprintf("%.0f ± %.0f", s.Glicko.Rating, s.Glicko.Deviation),
+				strconv.FormatFloat(s.Glicko.Volatility, 'f', 4, 64))
+		}
+		rows = append(rows, "| "+strings.Join(fields, " | ")+" |")
+	}
+	if _, err := io.WriteString(w, strings.Join(rows, "\n")+"\n"); err != nil {
+		return fmt.Errorf("cannot write standings: %w", err)
+	}
+	return nil
+}
+
+// WriteCrossTable writes the cross table as a Markdown table, highest rated first, in which each cell holds the points
+// scored by the entrant of its row against the entrant of its column, out of the games they played
+func WriteCrossTable(w io.Writer, r Report) error {
+	order := r.ranking()
+	header, rule := "| |", "| --- |"
+	for _, j := range order {
+		header, rule = header+" "+r.Standings[j].Name+" |", rule+" --- |"
+	}
+	rows := []string{header, rule}
+	for _, i := range order {
+		row := "| " + r.Standings[i].Name + " |"
+		for _, j := range order {
+			cell := ""
+			if r.Games[i][j] > 0 {
+				cell = strconv.FormatFloat(r.Cross[i][j], 'f', -1, 64) + "/" +
+					strconv.FormatFloat(r.Games[i][j], 'f', -1, 64)
+			}
+			row += " " + cell + " |"
+		}
+		rows = append(rows, row)
+	}
+	if _, err := io.WriteString(w, strings.Join(rows, "\n")+"\n"); err != nil {
+		return fmt.Errorf("cannot write cross table: %w", err)
+	}
+	return nil
+}
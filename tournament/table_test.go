@@ -0,0 +1,37 @@
+package tournament
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/talglobus/fearsome/record"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+func TestWriteTables(t *testing.T) {
+	c := Config{Entrants: []Entrant{{Name: "a", Strategy: strategy.Random{}}, {Name: "b", Strategy: strategy.Random{}}},
+		Glicko: true}
+	r, _ := Tabulate(c, []record.Record{
+		{Red: "a", Blue: "b", Result: record.BlueWin},
+		{Red: "b", Blue: "a", Result: record.RedWin},
+	})
+
+	var buf bytes.Buffer
+	if err := WriteStandings(&buf, r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[2], "| 1 | b | 2 | 2 | 0 | 0 | 2.0 | +") ||
+		!strings.Contains(lines[0], "glicko") {
+		t.Errorf("unexpected standings:\n%v", buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteCrossTable(&buf, r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "| | b | a |\n| --- | --- | --- |\n| b |  | 2/2 |\n| a | 0/2 |  |\n"; buf.String() != want {
+		t.Errorf("unexpected cross table. Expected:\n%v\nObserved:\n%v", want, buf.String())
+	}
+}
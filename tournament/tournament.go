@@ -0,0 +1,262 @@
+// Package tournament runs round-robin tournaments among any number of named strategies, in which every pair of
+// entrants plays a number of games with colors alternating, every game is kept as a record.Record, and the entrants
+// are rated by Elo and optionally Glicko-2, and tabulated in standings and a cross table.
+package tournament
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/board"
+	"github.com/talglobus/fearsome/internal/pool"
+	"github.com/talglobus/fearsome/rating"
+	"github.com/talglobus/fearsome/record"
+	"github.com/talglobus/fearsome/simulate"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+// Entrant holds a strategy entered in a tournament, under a name unique within the tournament
+type Entrant struct {
+	Name     string // Name of the entrant, defaulting to the strategy's name if empty
+	Strategy strategy.Strategy
+}
+
+// Config holds the parameters of a tournament
+type Config struct {
+	Event    string    // Name of the tournament, as recorded in its records
+	Entrants []Entrant // Entrants, of which there must be at least two
+	// Games sets the number of games played by each pair of entrants, with the entrant listed first playing RED in
+	// even-numbered games, and BLUE in odd-numbered games
+	Games int
+	Seed  int64 // Master seed, from which the seed of every game is derived
+
+	// Geometry sets the board size, defaulting to bitboard.Standard if zero
+	Geometry bitboard.Geometry
+	// Start holds the moves leading to the starting position of every game, or nil to start from the empty board
+	Start board.History
+	// Workers sets the number of games played concurrently, defaulting to the number of CPUs if zero. Results are
+	// identical regardless of the number of workers
+	Workers int
+
+	// Glicko enables Glicko-2 ratings alongside Elo ratings, with each round of games, in which every pair of
+	// entrants plays once, as a rating period
+	Glicko bool
+	// Tau constrains the change in Glicko-2 volatility between rating periods, defaulting to rating.DefaultTau if zero
+	Tau float64
+
+	// Progress, if not nil, is called with the record of each game as soon as it's played, from any goroutine
+	Progress func(record.Record)
+	// Moved, if not nil, is called as each game starts and after each of its moves, with the record of the game so
+	// far, which is Unfinished until the game ends. It's called from the goroutine playing the game, which waits for
+	// it to return, so it must return quickly
+	Moved func(record.Record)
+}
+
+// Standing holds the results and ratings of a single entrant
+type Standing struct {
+	Name                       string
+	Games, Wins, Draws, Losses int
+	Points                     float64         // Wins plus half of draws
+	Elo                        rating.Estimate // Elo rating, centered on zero across the entrants
+	Glicko                     rating.Glicko   // Glicko-2 rating, if enabled
+}
+
+// add counts the results of games in the standing
+func (s *Standing) add(wins, draws, losses int) {
+	s.Games += wins + draws + losses
+	s.Wins += wins
+	s.Draws += draws
+	s.Losses += losses
+	s.Points += float64(wins) + float64(draws)/2
+}
+
+// Report holds the results of a tournament
+type Report struct {
+	Config    Config
+	Records   []record.Record // Record of every game played, in order of index
+	Standings []Standing      // Standing of each entrant, in the order entered
+	// Cross holds the points scored by each entrant against each other entrant, such that Cross[i][j] is the points
+	// scored by entrant i against entrant j, and Games the number of games they played
+	Cross, Games [][]float64
+}
+
+// Run plays every game of the tournament across the configured number of workers, and tabulates the results. If the
+// context is done, or a strategy fails, before every game is played, Run returns a report of the games completed
+// until then, along with the error
+func Run(ctx context.Context, c Config) (Report, error) {
+	c, err := c.normalize()
+	if err != nil {
+		return Report{}, err
+	}
+
+	type pair struct{ first, second int }
+	var pairs []pair
+	for i := range c.Entrants {
+		for j := i + 1; j < len(c.Entrants); j++ {
+			pairs = append(pairs, pair{i, j})
+		}
+	}
+
+	// Games are ordered by round, such that every pair plays its first game before any pair plays its second
+	n := len(pairs) * c.Games
+	records, played := make([]record.Record, n), make([]bool, n)
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = i
+	}
+	failure := pool.Run(ctx, c.Workers, indices, func(ctx context.Context, index int) error {
+		p, round := pairs[index%len(pairs)], index/len(pairs)
+		red, blue := c.Entrants[p.first], c.Entrants[p.second]
+		if round%2 == 1 {
+			red, blue = blue, red
+		}
+
+		sc := simulate.Config{Red: red.Strategy, Blue: blue.Strategy, Geometry: c.Geometry, Start: c.Start}
+		if c.Moved != nil {
+			sc.Moved = func(g simulate.Game, p bitboard.Position) {
+				c.Moved(record.Record{Event: c.Event, Index: index, Red: red.Name, Blue: blue.Name,
+					Geometry: c.Geometry, Start: c.Start, Moves: g.Moves, Result: record.ResultOf(p), Seed: g.Seed})
+			}
+		}
+		g, err := simulate.Replay(ctx, sc, simulate.Game{Index: index, Seed: simulate.GameSeed(c.Seed, index)})
+		if err != nil {
+			return fmt.Errorf("cannot play %v against %v: %w", red.Name, blue.Name, err)
+		}
+
+		records[index] = record.Record{Event: c.Event, Index: index, Red: red.Name, Blue: blue.Name,
+			Geometry: c.Geometry, Start: c.Start, Moves: g.Moves, Result: record.Finished(g.Winner), Seed: g.Seed}
+		played[index] = true
+		if c.Progress != nil {
+			c.Progress(records[index])
+		}
+		return nil
+	})
+
+	completed := records[:0]
+	for i, r := range records {
+		if played[i] {
+			completed = append(completed, r)
+		}
+	}
+	r, err := Tabulate(c, completed)
+	if err != nil {
+		return r, err
+	}
+	return r, failure
+}
+
+// normalize fills in defaults and validates the configuration
+func (c Config) normalize() (Config, error) {
+	if c.Geometry == (bitboard.Geometry{}) {
+		c.Geometry = bitboard.Standard
+	}
+	if c.Workers == 0 {
+		c.Workers = runtime.NumCPU()
+	}
+	if c.Tau == 0 {
+		c.Tau = rating.DefaultTau
+	}
+
+	entrants := make([]Entrant, len(c.Entrants))
+	names := map[string]bool{}
+	for i, e := range c.Entrants {
+		if e.Strategy == nil {
+			return c, fmt.Errorf("cannot run tournament: %w", ConfigError(fmt.Sprintf("entrant %v has no strategy", i)))
+		}
+		if e.Name == "" {
+			e.Name = strategy.Name(e.Strategy)
+		}
+		if names[e.Name] {
+			return c, fmt.Errorf("cannot run tournament: %w", DuplicateError(e.Name))
+		}
+		names[e.Name] = true
+		entrants[i] = e
+	}
+	c.Entrants = entrants
+
+	switch {
+	case len(c.Entrants) < 2:
+		return c, fmt.Errorf("cannot run tournament: %w", ConfigError("at least two entrants are required"))
+	case c.Games <= 0:
+		return c, fmt.Errorf("cannot run tournament: %w", ConfigError("games must be positive"))
+	case c.Workers < 0:
+		return c, fmt.Errorf("cannot run tournament: %w", ConfigError("workers must not be negative"))
+	case c.Tau < 0:
+		return c, fmt.Errorf("cannot run tournament: %w", ConfigError("tau must not be negative"))
+	}
+	if _, err := bitboard.FromHistory(c.Geometry, c.Start); err != nil {
+		return c, fmt.Errorf("cannot run tournament from starting moves: %w", err)
+	}
+	return c, nil
+}
+
+// Tabulate tabulates records of games among the configured entrants, however they were played, identifying players
+// by name. Records of games involving players other than the entrants are ignored, while unfinished games are an
+// error. For Glicko-2 ratings, the nth game between each pair of entrants is taken to be in the nth rating period
+func Tabulate(c Config, records []record.Record) (Report, error) {
+	if c.Tau == 0 {
+		c.Tau = rating.DefaultTau
+	}
+	index := map[string]int{}
+	r := Report{Config: c, Standings: make([]Standing, len(c.Entrants)), Cross: make([][]float64, len(c.Entrants)),
+		Games: make([][]float64, len(c.Entrants))}
+	for i, e := range c.Entrants {
+		name := e.Name
+		if name == "" {
+			name = strategy.Name(e.Strategy)
+		}
+		index[name] = i
+		r.Standings[i].Name = name
+		r.Cross[i], r.Games[i] = make([]float64, len(c.Entrants)), make([]float64, len(c.Entrants))
+	}
+
+	var outcomes []rating.Outcome
+	var periods [][]rating.Outcome
+	for _, rec := range records {
+		red, okRed := index[rec.Red]
+		blue, okBlue := index[rec.Blue]
+		if !okRed || !okBlue || red == blue {
+			continue
+		}
+		if rec.Result == record.Unfinished {
+			return r, fmt.Errorf("cannot tabulate: %w", UnfinishedError(rec.Index))
+		}
+		r.Records = append(r.Records, rec)
+
+		o := rating.Outcome{A: red, B: blue}
+		switch rec.Result {
+		case record.RedWin:
+			o.Wins = 1
+		case record.BlueWin:
+			o.Losses = 1
+		default:
+			o.Draws = 1
+		}
+		outcomes = append(outcomes, o)
+
+		period := int(r.Games[red][blue])
+		for len(periods) <= period {
+			periods = append(periods, nil)
+		}
+		periods[period] = append(periods[period], o)
+
+		r.Games[red][blue]++
+		r.Games[blue][red]++
+		r.Cross[red][blue] += float64(o.Wins) + float64(o.Draws)/2
+		r.Cross[blue][red] += float64(o.Losses) + float64(o.Draws)/2
+		r.Standings[red].add(o.Wins, o.Draws, o.Losses)
+		r.Standings[blue].add(o.Losses, o.Draws, o.Wins)
+	}
+
+	for i, e := range rating.Elo(len(c.Entrants), outcomes) {
+		r.Standings[i].Elo = e
+	}
+	if c.Glicko {
+		for i, g := range rating.Glicko2(len(c.Entrants), periods, c.Tau) {
+			r.Standings[i].Glicko = g
+		}
+	}
+	return r, nil
+}
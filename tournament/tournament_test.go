@@ -0,0 +1,192 @@
+package tournament
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/talglobus/fearsome/bitboard"
+	"github.com/talglobus/fearsome/record"
+	"github.com/talglobus/fearsome/strategy"
+)
+
+var small = bitboard.Geometry{Width: 5, Height: 4}
+
+// ladder returns entrants of clearly different strengths
+func ladder() []Entrant {
+	return []Entrant{
+		{Strategy: strategy.Random{}},
+		{Strategy: strategy.Greedy{}},
+		{Name: "deep", Strategy: strategy.Lookahead{Depth: 4}},
+	}
+}
+
+func TestRun(t *testing.T) {
+	var progress int32
+	c := Config{Event: "test", Entrants: ladder(), Games: 40, Seed: 2, Geometry: small, Glicko: true,
+		Progress: func(record.Record) { atomic.AddInt32(&progress, 1) }}
+	r, err := Run(context.Background(), c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(r.Records) != 120 || progress != 120 {
+		t.Fatalf("expected 120 games, observed %v records and %v progress reports", len(r.Records), progress)
+	}
+	for i, rec := range r.Records {
+		if rec.Index != i || rec.Event != "test" || rec.Geometry != small {
+			t.Fatalf("unexpected record %+v at %v", rec, i)
+		}
+		if err := rec.Validate(); err != nil {
+			t.Fatalf("invalid record %+v: %v", rec, err)
+		}
+	}
+
+	// Every entrant plays every other entrant equally often with each color
+	colors := map[[2]string]int{}
+	for _, rec := range r.Records {
+		colors[[2]string{rec.Red, rec.Blue}]++
+	}
+	if len(colors) != 6 {
+		t.Errorf("expected six ordered pairings, observed %v", colors)
+	}
+	for pairing, n := range colors {
+		if n != 20 {
+			t.Errorf("expected 20 games with %v as RED against %v, observed %v", pairing[0], pairing[1], n)
+		}
+	}
+
+	names := []string{"random", "greedy", "deep"}
+	for i, s := range r.Standings {
+		if s.Name != names[i] || s.Games != 80 || s.Wins+s.Draws+s.Losses != 80 {
+			t.Errorf("unexpected standing %+v", s)
+		}
+		if s.Elo.Error <= 0 || s.Glicko.Deviation <= 0 || s.Glicko.Deviation >= 350 {
+			t.Errorf("expected rating uncertainty, observed %+v", s)
+		}
+	}
+	if r.Standings[2].Elo.Rating <= r.Standings[0].Elo.Rating ||
+		r.Standings[2].Glicko.Rating <= r.Standings[0].Glicko.Rating {
+		t.Errorf("expected the deep searcher to outrate random play, observed %+v", r.Standings)
+	}
+
+	total := 0.0
+	for i := range r.Cross {
+		for j := range r.Cross {
+			total += r.Cross[i][j]
+			if i != j && r.Cross[i][j]+r.Cross[j][i] != 40 {
+				t.Errorf("expected 40 points between %v and %v, observed %v", i, j, r.Cross[i][j]+r.Cross[j][i])
+			}
+		}
+	}
+	if total != 120 {
+		t.Errorf("expected 120 points in total, observed %v", total)
+	}
+}
+
+func TestRun_moved(t *testing.T) {
+	var mutex sync.Mutex
+	final := map[int]record.Record{}
+	c := Config{Event: "watched", Entrants: ladder(), Games: 4, Seed: 6, Geometry: small,
+		Moved: func(rec record.Record) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if prev, ok := final[rec.Index]; ok && (prev.Result != record.Unfinished ||
+				len(rec.Moves) != len(prev.Moves)+1) {
+				t.Errorf("game %v: expected a move to follow %+v, observed %+v", rec.Index, prev, rec)
+			}
+			final[rec.Index] = rec
+		}}
+	r, err := Run(context.Background(), c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, rec := range r.Records {
+		got := final[rec.Index]
+		if got.Event != "watched" || got.Red != rec.Red || got.Blue != rec.Blue || got.Result != rec.Result ||
+			!got.Moves.Equals(rec.Moves) {
+			t.Errorf("expected the last report of game %v to match its record %+v, observed %+v", rec.Index, rec, got)
+		}
+	}
+}
+
+// TestRun_deterministic asserts that results don't depend on the number of workers, and agree with tabulating the
+// records afresh
+func TestRun_deterministic(t *testing.T) {
+	c := Config{Entrants: ladder(), Games: 10, Seed: 4, Geometry: small}
+	c.Workers = 1
+	r1, _ := Run(context.Background(), c)
+	c.Workers = 5
+	r2, _ := Run(context.Background(), c)
+
+	for i := range r1.Records {
+		if !r1.Records[i].Moves.Equals(r2.Records[i].Moves) {
+			t.Fatalf("game %v differs between runs", i)
+		}
+	}
+	if r1.String() != r2.String() {
+		t.Errorf("expected identical standings, observed\n%v\nand\n%v", r1, r2)
+	}
+
+	r3, err := Tabulate(c, r1.Records)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := range r1.Standings {
+		if r1.Standings[i] != r3.Standings[i] {
+			t.Errorf("expected tabulated standing %+v, observed %+v", r1.Standings[i], r3.Standings[i])
+		}
+	}
+}
+
+func TestTabulate(t *testing.T) {
+	c := Config{Entrants: []Entrant{{Name: "a", Strategy: strategy.Random{}}, {Name: "b", Strategy: strategy.Random{}}}}
+	records := []record.Record{
+		{Index: 0, Red: "a", Blue: "b", Result: record.RedWin},
+		{Index: 1, Red: "b", Blue: "a", Result: record.Draw},
+		{Index: 2, Red: "a", Blue: "c", Result: record.BlueWin},
+	}
+	r, err := Tabulate(c, records)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(r.Records) != 2 || r.Standings[0].Points != 1.5 || r.Standings[1].Points != 0.5 {
+		t.Errorf("unexpected standings %+v", r.Standings)
+	}
+	if r.Cross[0][1] != 1.5 || r.Games[0][1] != 2 || math.Abs(r.Standings[0].Elo.Rating+r.Standings[1].Elo.Rating) > 1e-9 {
+		t.Errorf("unexpected cross table %v", r.Cross)
+	}
+
+	records = append(records, record.Record{Index: 3, Red: "a", Blue: "b"})
+	if _, err := Tabulate(c, records); !errors.As(err, new(UnfinishedError)) {
+		t.Errorf("expected an unfinished game error, observed %v", err)
+	}
+}
+
+func TestRun_errors(t *testing.T) {
+	table := []struct {
+		config Config
+		target interface{}
+	}{
+		{Config{Entrants: ladder()[:1], Games: 2}, new(ConfigError)},
+		{Config{Entrants: ladder(), Games: 0}, new(ConfigError)},
+		{Config{Entrants: []Entrant{{Name: "x"}, {Strategy: strategy.Random{}}}, Games: 2}, new(ConfigError)},
+		{Config{Entrants: []Entrant{{Strategy: strategy.Random{}}, {Strategy: strategy.Random{}}}, Games: 2},
+			new(DuplicateError)},
+	}
+	for _, r := range table {
+		if _, err := Run(context.Background(), r.config); !errors.As(err, r.target) {
+			t.Errorf("expected error of type %T, observed %v", r.target, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Run(ctx, Config{Entrants: ladder(), Games: 100}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected cancellation, observed %v", err)
+	}
+}